@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/contract"
+)
+
+// Checks the router against the OpenAPI spec. Exits with 1 when a route is
+// missing from either side or a response doesn't match its schema.
+func main() {
+	specPath := flag.String("spec", "swagger.yaml", "Path of the OpenAPI spec")
+	flag.Parse()
+
+	// Handlers log every error case, only show them when asked to
+	if os.Getenv("LOG_LEVEL") == "" {
+		log.SetOutput(io.Discard)
+	}
+
+	spec, err := contract.LoadSpec(*specPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	h := contract.NewHandler()
+
+	failures := contract.CheckRoutes(h.Router, spec)
+
+	cases := contract.Cases()
+	failures = append(failures, contract.Run(spec, h.Router, cases)...)
+
+	for _, failure := range failures {
+		fmt.Fprintln(os.Stderr, failure)
+	}
+
+	if len(failures) > 0 {
+		fmt.Fprintf(os.Stderr, "%d contract failures\n", len(failures))
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d cases match %s\n", len(cases), *specPath)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zzenonn/scoping-ai/internal/db"
+)
+
+// The unique fields the repositories reserve, by the collection names the
+// server is started with
+var uniqueFields = []struct {
+	collection string
+	field      string
+}{
+	{"question_sets", "technology_name"},
+	{"course_outlines", "course_code"},
+	{"users", "email_address"},
+	{"organizations", "name"},
+}
+
+// The collections whose documents are soft deleted
+var softDeleted = []string{"question_sets", "course_outlines", "users", "organizations", "messages"}
+
+// Brings documents written by earlier versions up to date. Every step can be
+// run again, it only changes what is still missing.
+func main() {
+	projectId := flag.String("project-id", "", "The id of the project (required)")
+	flag.Parse()
+
+	if *projectId == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	firestoreDb, err := db.NewDatabase(*projectId)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer firestoreDb.Client.Close()
+
+	ctx := context.Background()
+	failed := false
+
+	for _, collection := range softDeleted {
+		updated, err := db.BackfillDeletedAt(ctx, firestoreDb.Client, collection)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s.deleted_at: %v\n", collection, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s.deleted_at: set on %d documents\n", collection, updated)
+	}
+
+	for _, unique := range uniqueFields {
+		claimed, conflicts, err := db.BackfillReservations(ctx, firestoreDb.Client, unique.collection, unique.field)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s.%s: %v\n", unique.collection, unique.field, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s.%s: reserved %d values, %d conflicts\n", unique.collection, unique.field, claimed, conflicts)
+		failed = failed || conflicts > 0
+	}
+
+	// Conflicting documents need a new value before they can be reserved
+	if failed {
+		os.Exit(2)
+	}
+}
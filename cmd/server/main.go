@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	firebase "firebase.google.com/go"
 	log "github.com/sirupsen/logrus"
@@ -14,10 +17,21 @@ import (
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
 	secretmanagerpb "cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
 
+	apikeys "github.com/zzenonn/scoping-ai/internal/apikey"
+	"github.com/zzenonn/scoping-ai/internal/audit"
+	"github.com/zzenonn/scoping-ai/internal/auth"
 	"github.com/zzenonn/scoping-ai/internal/db"
+	"github.com/zzenonn/scoping-ai/internal/idempotency"
 	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	organization "github.com/zzenonn/scoping-ai/internal/organization"
 	outline "github.com/zzenonn/scoping-ai/internal/outline"
+	"github.com/zzenonn/scoping-ai/internal/privacy"
 	questionSet "github.com/zzenonn/scoping-ai/internal/question-set"
+	"github.com/zzenonn/scoping-ai/internal/ratelimit"
+	"github.com/zzenonn/scoping-ai/internal/retention"
+	"github.com/zzenonn/scoping-ai/internal/ticket"
+	transportGraphql "github.com/zzenonn/scoping-ai/internal/transport/graphql"
+	transportGrpc "github.com/zzenonn/scoping-ai/internal/transport/grpc"
 	transportHttp "github.com/zzenonn/scoping-ai/internal/transport/http"
 	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
 )
@@ -40,6 +54,28 @@ func init() {
 
 }
 
+// Runtime configuration taken from the command line
+type config struct {
+	projectId     string
+	retention     time.Duration
+	purgeInterval time.Duration
+	authProvider  string
+	oidcIssuer    string
+	oidcClientId  string
+	tenant        string
+	tenants       string
+	rateLimit     int
+	addressLimit  int
+	proxies       string
+	llmRateLimit  int
+	grpcAddr      string
+	grpcCert      string
+	grpcKey       string
+	grpcReflect   bool
+	messageEvents string
+	idempotency   time.Duration
+}
+
 func getSecret(secretName string) (string, error) {
 	ctx := context.Background()
 
@@ -61,10 +97,59 @@ func getSecret(secretName string) (string, error) {
 	return string(result.Payload.Data), nil
 }
 
+// Tokens belong to the tenant the identity provider is configured for, or to
+// one of the listed tenants they name
+func tenancyFromConfig(cfg config) auth.Tenancy {
+	tenancy := auth.Tenancy{Fixed: cfg.tenant}
+
+	for _, tenantId := range strings.Split(cfg.tenants, ",") {
+		if tenantId = strings.TrimSpace(tenantId); tenantId != "" {
+			tenancy.Allowed = append(tenancy.Allowed, tenantId)
+		}
+	}
+
+	return tenancy
+}
+
+// Picks the verifier for bearer tokens. The HMAC verifier reads its secret
+// from JWT_HMAC_SECRET and is only meant for local development.
+func newTokenVerifier(cfg config) (transportHttp.TokenVerifier, error) {
+	tenancy := tenancyFromConfig(cfg)
+
+	switch cfg.authProvider {
+	case "firebase":
+		firebaseConfig := &firebase.Config{ProjectID: cfg.projectId}
+
+		firebaseApp, err := firebase.NewApp(context.Background(), firebaseConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return auth.NewFirebaseVerifier(firebaseApp, tenancy), nil
+	case "oidc":
+		if cfg.oidcIssuer == "" || cfg.oidcClientId == "" {
+			return nil, errors.New("the 'oidc-issuer' and 'oidc-client-id' flags are required for oidc")
+		}
+
+		return auth.NewOIDCVerifier(context.Background(), cfg.oidcIssuer, cfg.oidcClientId, tenancy)
+	case "hmac":
+		secret := os.Getenv("JWT_HMAC_SECRET")
+		if secret == "" {
+			return nil, errors.New("JWT_HMAC_SECRET must be set for hmac")
+		}
+
+		return auth.NewHMACVerifier([]byte(secret), tenancy), nil
+	default:
+		return nil, fmt.Errorf("unknown auth provider: %s", cfg.authProvider)
+	}
+}
+
 // Instantiate and startup go app
-func Run(projectName string) error {
+func Run(cfg config) error {
 	log.Println("starting up the application")
 
+	projectName := cfg.projectId
+
 	firestoreDb, err := db.NewDatabase(projectName)
 
 	if err != nil {
@@ -72,13 +157,10 @@ func Run(projectName string) error {
 		return err
 	}
 
-	// Create Firebase app for JWT verification
-
-	cfg := &firebase.Config{ProjectID: projectName}
-
-	firebaseApp, err := firebase.NewApp(context.Background(), cfg)
+	verifier, err := newTokenVerifier(cfg)
 	if err != nil {
-		log.Fatalf("Error initializing Firebase App: %v", err)
+		log.Error("Failed to create the token verifier")
+		return err
 	}
 
 	openAIKeySecretName := fmt.Sprintf("projects/%s/secrets/OpenAIAPIKey/versions/latest", projectName)
@@ -101,20 +183,133 @@ func Run(projectName string) error {
 	userService := scopingUser.NewUserService(&userRepository)
 	userHandler := transportHttp.NewUserHandler(userService)
 
+	orgRepository := db.NewOrganizationRepository(firestoreDb.Client, "organizations", "members", "users")
+	orgService := organization.NewOrganizationService(&orgRepository)
+	orgHandler := transportHttp.NewOrganizationHandler(orgService)
+
+	privacyRepository := db.NewPrivacyRepository(firestoreDb.Client, "users", "erasure_records", "organizations", "members")
+	privacyService := privacy.NewPrivacyService(&privacyRepository)
+	privacyHandler := transportHttp.NewPrivacyHandler(privacyService)
+
+	rateLimitStore := ratelimit.NewMemoryStore()
+	answersLimiter := transportHttp.NewRateLimiter("llm", rateLimitStore, ratelimit.PerMinute(cfg.llmRateLimit))
+
 	openAiRepository := db.NewOpenAiRepository(openAPIKey, "https://api.openai.com/v1/chat/completions", "gpt-4", 1)
 	messageRepository := db.NewMessageRepository(firestoreDb.Client, "messages", "users")
-	messageService := scopingMessage.NewMessageService(&messageRepository, &openAiRepository)
-	messageHandler := transportHttp.NewMessageHandler(messageService)
 
-	httpHandler := transportHttp.NewMainHandler(firebaseApp)
+	// Clients hear about changes to messages from the instance they are
+	// connected to. With more than one instance, every instance watches the
+	// database instead of publishing its own changes.
+	messageHub := scopingMessage.NewHub()
+	var messageEvents scopingMessage.EventBus = messageHub
+
+	if cfg.messageEvents == "firestore" {
+		messageEvents = messageHub.Watched()
+
+		watchCtx, stopWatching := context.WithCancel(context.Background())
+		defer stopWatching()
+
+		go messageRepository.WatchMessages(watchCtx, messageHub)
+	}
+
+	messageService := scopingMessage.NewMessageService(&messageRepository, &openAiRepository, messageEvents, orgService)
+	ticketRepository := db.NewTicketRepository(firestoreDb.Client, "tickets")
+	ticketService := ticket.NewTicketService(&ticketRepository)
+
+	// Soft deleted records are kept for the retention period so they can be restored
+	purgeScheduler := retention.NewScheduler(cfg.retention, cfg.purgeInterval)
+	purgeScheduler.AddPurger("question sets", qSetService)
+	purgeScheduler.AddPurger("course outlines", cOutlineService)
+	purgeScheduler.AddPurger("users", userService)
+	purgeScheduler.AddPurger("messages", messageService)
+	purgeScheduler.AddPurger("organizations", orgService)
+
+	purgeCtx, stopPurging := context.WithCancel(context.Background())
+	defer stopPurging()
+
+	go purgeScheduler.Run(purgeCtx)
+
+	apiKeyRepository := db.NewAPIKeyRepository(firestoreDb.Client, "api_keys")
+	apiKeyService := apikeys.NewAPIKeyService(&apiKeyRepository)
+	apiKeyHandler := transportHttp.NewAPIKeyHandler(apiKeyService)
+
+	auditRepository := db.NewAuditRepository(firestoreDb.Client, "audit_records")
+	auditService := audit.NewAuditService(&auditRepository)
+	auditHandler := transportHttp.NewAuditHandler(auditService)
+
+	idempotencyRepository := db.NewIdempotencyRepository(firestoreDb.Client, "idempotency_keys")
+	idempotencyService := idempotency.NewIdempotencyService(&idempotencyRepository, cfg.idempotency)
+
+	accessPolicy := transportHttp.NewAccessPolicy(userService)
+
+	graphqlHandler := transportHttp.NewGraphQLHandler(transportGraphql.NewHandler(qSetService, cOutlineService, userService, messageService, accessPolicy))
+
+	corsConfig, err := transportHttp.CorsConfigFromEnv()
+	if err != nil {
+		log.Error("Failed to load the CORS configuration")
+		return err
+	}
+
+	messageHandler := transportHttp.NewMessageHandler(messageService, answersLimiter, messageHub, ticketService, corsConfig)
+
+	requestLimiter := transportHttp.NewRateLimiter("requests", rateLimitStore, ratelimit.PerMinute(cfg.rateLimit))
+	trustedProxies, err := transportHttp.ParseTrustedProxies(cfg.proxies)
+	if err != nil {
+		log.Error("Failed to parse the trusted proxies")
+		return err
+	}
+
+	addressLimiter := transportHttp.NewAddressRateLimiter("addresses", rateLimitStore, ratelimit.PerMinute(cfg.addressLimit), trustedProxies)
+
+	httpHandler := transportHttp.NewMainHandler(verifier, apiKeyService, accessPolicy, corsConfig, requestLimiter, addressLimiter, auditService, idempotencyService, ticketService)
 
 	httpHandler.AddHandler(qSetHandler)
 	httpHandler.AddHandler(cOutlineHandler)
 	httpHandler.AddHandler(userHandler)
 	httpHandler.AddHandler(messageHandler)
+	httpHandler.AddHandler(privacyHandler)
+	httpHandler.AddHandler(orgHandler)
+	httpHandler.AddHandler(apiKeyHandler)
+	httpHandler.AddHandler(auditHandler)
+	httpHandler.AddHandler(graphqlHandler)
 
 	httpHandler.MapRoutes()
 
+	// The gRPC API serves the same services on its own port
+	if cfg.grpcAddr != "" {
+		grpcOptions := transportGrpc.Options{Reflection: cfg.grpcReflect}
+
+		if cfg.grpcCert != "" {
+			certificate, err := tls.LoadX509KeyPair(cfg.grpcCert, cfg.grpcKey)
+			if err != nil {
+				log.Error("Failed to load the gRPC certificate")
+				return err
+			}
+
+			grpcOptions.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}
+		}
+
+		grpcServer := transportGrpc.NewServer(cfg.grpcAddr, verifier, apiKeyService, accessPolicy, transportGrpc.Limits{
+			Store:     rateLimitStore,
+			Addresses: ratelimit.PerMinute(cfg.addressLimit),
+			Requests:  ratelimit.PerMinute(cfg.rateLimit),
+			Answers:   ratelimit.PerMinute(cfg.llmRateLimit),
+		}, grpcOptions)
+
+		grpcServer.AddService(transportGrpc.NewQuestionSetServer(qSetService))
+		grpcServer.AddService(transportGrpc.NewCourseOutlineServer(cOutlineService))
+		grpcServer.AddService(transportGrpc.NewUserServer(userService))
+		grpcServer.AddService(transportGrpc.NewMessageServer(messageService))
+
+		go func() {
+			if err := grpcServer.Serve(); err != nil {
+				log.Error(err)
+			}
+		}()
+
+		defer grpcServer.Stop()
+	}
+
 	if err := httpHandler.Serve(); err != nil {
 		return err
 	}
@@ -123,18 +318,67 @@ func Run(projectName string) error {
 }
 
 func main() {
-	projectId := flag.String("project-id", "", "The id of the project (required)")
+	var cfg config
+
+	flag.StringVar(&cfg.projectId, "project-id", "", "The id of the project (required)")
+	flag.DurationVar(&cfg.retention, "retention", 30*24*time.Hour, "How long soft deleted records are kept before they are purged")
+	flag.DurationVar(&cfg.purgeInterval, "purge-interval", 24*time.Hour, "How often soft deleted records are checked for purging")
+	flag.StringVar(&cfg.authProvider, "auth-provider", "firebase", "How bearer tokens are verified: firebase, oidc or hmac")
+	flag.StringVar(&cfg.oidcIssuer, "oidc-issuer", "", "The issuer URL of the OpenID Connect provider")
+	flag.StringVar(&cfg.oidcClientId, "oidc-client-id", "", "The client id the OpenID Connect tokens are issued for")
+	flag.StringVar(&cfg.tenant, "tenant", "", "The tenant every token belongs to, when the identity provider serves a single tenant")
+	flag.StringVar(&cfg.tenants, "tenants", "", "Comma separated tenants tokens may name in their tenant claim, empty to disable tenancy")
+	flag.IntVar(&cfg.rateLimit, "rate-limit", 300, "Requests per minute allowed for each user or API key")
+	flag.IntVar(&cfg.addressLimit, "address-rate-limit", 1200, "Requests per minute allowed from each address, counted before authentication")
+	flag.StringVar(&cfg.proxies, "trusted-proxies", "", "Comma separated addresses and CIDR ranges of the proxies in front of the server, whose X-Forwarded-For is trusted")
+	flag.IntVar(&cfg.llmRateLimit, "llm-rate-limit", 5, "Requests per minute allowed for each caller on routes that call the LLM")
+	flag.StringVar(&cfg.grpcAddr, "grpc-addr", "127.0.0.1:9090", "The address the gRPC API listens on, empty to disable it. Only listen on other interfaces with TLS or behind a proxy that terminates it")
+	flag.StringVar(&cfg.grpcCert, "grpc-tls-cert", "", "The PEM certificate the gRPC API serves TLS with, set with 'grpc-tls-key'")
+	flag.StringVar(&cfg.grpcKey, "grpc-tls-key", "", "The PEM private key of the gRPC certificate")
+	flag.BoolVar(&cfg.grpcReflect, "grpc-reflection", false, "Lets tools such as grpcurl list the gRPC services")
+	flag.StringVar(&cfg.messageEvents, "message-events", "local", "Where message events come from: local for a single instance, firestore for many")
+	flag.DurationVar(&cfg.idempotency, "idempotency-window", 24*time.Hour, "How long responses to requests with an Idempotency-Key are replayed")
 	flag.Parse()
 
-	if *projectId == "" {
+	if cfg.messageEvents != "local" && cfg.messageEvents != "firestore" {
+		log.Debug("The 'message-events' flag must be local or firestore")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if cfg.tenant != "" && cfg.tenants != "" {
+		log.Debug("Only one of the 'tenant' and 'tenants' flags can be set")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if cfg.rateLimit < 1 || cfg.addressLimit < 1 || cfg.llmRateLimit < 1 {
+		log.Debug("The rate limits must be at least 1")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if (cfg.grpcCert == "") != (cfg.grpcKey == "") {
+		log.Debug("The 'grpc-tls-cert' and 'grpc-tls-key' flags must be set together")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if cfg.retention <= 0 || cfg.purgeInterval <= 0 {
+		log.Debug("The 'retention' and 'purge-interval' flags must be positive")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if cfg.projectId == "" {
 		log.Debug("The 'project-id' flag is required")
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	log.Infof("the server is up with project: %s", *projectId)
+	log.Infof("the server is up with project: %s", cfg.projectId)
 
-	if err := Run(*projectId); err != nil {
+	if err := Run(cfg); err != nil {
 		log.Error(err)
 	}
 }
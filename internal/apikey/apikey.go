@@ -0,0 +1,279 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/internal/tenant"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+var (
+	ErrNotFound     = common.NewError(common.KindNotFound, "api key not found")
+	ErrInvalidKey   = common.NewError(common.KindUnauthorized, "invalid api key")
+	ErrMissingName  = common.NewError(common.KindValidation, "api key name is required")
+	ErrInvalidScope = common.NewError(common.KindValidation, "invalid api key scope")
+	ErrRevoked      = common.NewError(common.KindConflict, "api key was revoked")
+)
+
+// Scopes grant read or write access to a group of routes
+const (
+	ScopeQuestionSetsRead    = "question-sets:read"
+	ScopeQuestionSetsWrite   = "question-sets:write"
+	ScopeCourseOutlinesRead  = "course-outlines:read"
+	ScopeCourseOutlinesWrite = "course-outlines:write"
+	ScopeUsersRead           = "users:read"
+	ScopeUsersWrite          = "users:write"
+	ScopeMessagesRead        = "messages:read"
+	ScopeMessagesWrite       = "messages:write"
+	ScopeOrganizationsRead   = "organizations:read"
+	ScopeOrganizationsWrite  = "organizations:write"
+)
+
+var validScopes = map[string]bool{
+	ScopeQuestionSetsRead:    true,
+	ScopeQuestionSetsWrite:   true,
+	ScopeCourseOutlinesRead:  true,
+	ScopeCourseOutlinesWrite: true,
+	ScopeUsersRead:           true,
+	ScopeUsersWrite:          true,
+	ScopeMessagesRead:        true,
+	ScopeMessagesWrite:       true,
+	ScopeOrganizationsRead:   true,
+	ScopeOrganizationsWrite:  true,
+}
+
+// Keys are handed out as "sk_<id>.<secret>". Only a hash of the secret is
+// stored, the key itself is shown once when it is created or rotated.
+const keyPrefix = "sk_"
+
+type APIKey struct {
+	Id         string     `json:"id" firestore:"id"`
+	Name       *string    `json:"name,omitempty" firestore:"name,omitempty" validate:"required,max=100"`
+	TenantId   string     `json:"-" firestore:"tenant_id"`
+	Scopes     []string   `json:"scopes" firestore:"scopes"`
+	SecretHash string     `json:"-" firestore:"secret_hash"`
+	CreatedBy  *string    `json:"created_by,omitempty" firestore:"created_by,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty" firestore:"created_at,omitempty"`
+	RotatedAt  *time.Time `json:"rotated_at,omitempty" firestore:"rotated_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" firestore:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" firestore:"revoked_at,omitempty"`
+}
+
+// An API key along with its plain text key, returned only when it is issued
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type APIKeyRepository interface {
+	PostAPIKey(ctx context.Context, key APIKey) (APIKey, error)
+	GetAPIKey(ctx context.Context, id string) (APIKey, error)
+	GetAPIKeysByTenant(ctx context.Context, tenantId string, page int, pageSize int) ([]APIKey, error)
+	RotateAPIKey(ctx context.Context, id string, secretHash string) (APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	TouchAPIKey(ctx context.Context, id string) error
+}
+
+type APIKeyService struct {
+	apiKeyRepository APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepository APIKeyRepository) *APIKeyService {
+	return &APIKeyService{apiKeyRepository: apiKeyRepository}
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func newSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func tenantOf(ctx context.Context) string {
+	tenantId, _ := tenant.FromContext(ctx)
+	return tenantId
+}
+
+func (service *APIKeyService) CreateAPIKey(ctx context.Context, key APIKey) (IssuedAPIKey, error) {
+	log.Debug("Creating api key . . .")
+
+	if key.Name == nil || strings.TrimSpace(*key.Name) == "" {
+		return IssuedAPIKey{}, ErrMissingName
+	}
+
+	for _, scope := range key.Scopes {
+		if !validScopes[scope] {
+			return IssuedAPIKey{}, ErrInvalidScope
+		}
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return IssuedAPIKey{}, err
+	}
+
+	key.Id = uuid.New().String()
+	key.TenantId = tenantOf(ctx)
+	key.SecretHash = hashSecret(secret)
+	key.CreatedBy = nil
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+
+	if ident, ok := identity.FromContext(ctx); ok {
+		key.CreatedBy = &ident.UID
+	}
+
+	createdKey, err := service.apiKeyRepository.PostAPIKey(ctx, key)
+
+	if err != nil {
+		log.Error("Failed to create api key")
+		return IssuedAPIKey{}, err
+	}
+
+	return IssuedAPIKey{APIKey: createdKey, Key: keyPrefix + createdKey.Id + "." + secret}, nil
+}
+
+// Keys of other tenants are reported as missing
+func (service *APIKeyService) GetAPIKey(ctx context.Context, id string) (APIKey, error) {
+	log.Debug("Retrieving api key . . .")
+
+	key, err := service.apiKeyRepository.GetAPIKey(ctx, id)
+
+	if err != nil {
+		log.Error("Failed to retrieve api key")
+		return APIKey{}, err
+	}
+
+	if key.TenantId != tenantOf(ctx) {
+		return APIKey{}, ErrNotFound
+	}
+
+	return key, nil
+}
+
+func (service *APIKeyService) GetAllAPIKeys(ctx context.Context, page int, pageSize int) ([]APIKey, error) {
+	log.Debug("Retrieving all api keys . . .")
+
+	keys, err := service.apiKeyRepository.GetAPIKeysByTenant(ctx, tenantOf(ctx), page, pageSize)
+
+	if err != nil {
+		log.Error("Failed to retrieve all api keys")
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Replaces the secret of the key. The old secret stops working immediately.
+func (service *APIKeyService) RotateAPIKey(ctx context.Context, id string) (IssuedAPIKey, error) {
+	log.Debug("Rotating api key . . .")
+
+	key, err := service.GetAPIKey(ctx, id)
+	if err != nil {
+		return IssuedAPIKey{}, err
+	}
+
+	if key.RevokedAt != nil {
+		return IssuedAPIKey{}, ErrRevoked
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return IssuedAPIKey{}, err
+	}
+
+	rotatedKey, err := service.apiKeyRepository.RotateAPIKey(ctx, id, hashSecret(secret))
+
+	if err != nil {
+		log.Error("Failed to rotate api key")
+		return IssuedAPIKey{}, err
+	}
+
+	return IssuedAPIKey{APIKey: rotatedKey, Key: keyPrefix + rotatedKey.Id + "." + secret}, nil
+}
+
+func (service *APIKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	log.Debug("Revoking api key . . .")
+
+	if _, err := service.GetAPIKey(ctx, id); err != nil {
+		return err
+	}
+
+	err := service.apiKeyRepository.RevokeAPIKey(ctx, id)
+
+	if err != nil {
+		log.Error("Failed to revoke api key")
+		return err
+	}
+
+	return nil
+}
+
+// Returns the key matching the plain text key of a request. Last use is
+// recorded at most once a minute to keep authentication cheap.
+func (service *APIKeyService) Authenticate(ctx context.Context, rawKey string) (APIKey, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(rawKey, keyPrefix), ".")
+	if !ok || !strings.HasPrefix(rawKey, keyPrefix) {
+		return APIKey{}, ErrInvalidKey
+	}
+
+	key, err := service.apiKeyRepository.GetAPIKey(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return APIKey{}, ErrInvalidKey
+	}
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.SecretHash)) != 1 {
+		return APIKey{}, ErrInvalidKey
+	}
+
+	if key.RevokedAt != nil {
+		return APIKey{}, ErrRevoked
+	}
+
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > time.Minute {
+		if err := service.apiKeyRepository.TouchAPIKey(ctx, key.Id); err != nil {
+			log.Errorf("Failed to record use of api key %s: %v", key.Id, err)
+		}
+	}
+
+	return key, nil
+}
@@ -0,0 +1,215 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zzenonn/scoping-ai/internal/tenant"
+)
+
+// Keeps the keys of every tenant in memory
+type memoryRepository struct {
+	keys    map[string]APIKey
+	touched int
+}
+
+func (repo *memoryRepository) PostAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	repo.keys[key.Id] = key
+	return key, nil
+}
+
+func (repo *memoryRepository) GetAPIKey(ctx context.Context, id string) (APIKey, error) {
+	key, ok := repo.keys[id]
+	if !ok {
+		return APIKey{}, ErrNotFound
+	}
+	return key, nil
+}
+
+func (repo *memoryRepository) GetAPIKeysByTenant(ctx context.Context, tenantId string, page int, pageSize int) ([]APIKey, error) {
+	var keys []APIKey
+	for _, key := range repo.keys {
+		if key.TenantId == tenantId {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (repo *memoryRepository) RotateAPIKey(ctx context.Context, id string, secretHash string) (APIKey, error) {
+	key := repo.keys[id]
+	key.SecretHash = secretHash
+	repo.keys[id] = key
+	return key, nil
+}
+
+func (repo *memoryRepository) RevokeAPIKey(ctx context.Context, id string) error {
+	key := repo.keys[id]
+	now := time.Now()
+	key.RevokedAt = &now
+	repo.keys[id] = key
+	return nil
+}
+
+func (repo *memoryRepository) TouchAPIKey(ctx context.Context, id string) error {
+	key := repo.keys[id]
+	now := time.Now()
+	key.LastUsedAt = &now
+	repo.keys[id] = key
+	repo.touched++
+	return nil
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	name := "CRM"
+
+	tests := []struct {
+		name    string
+		key     APIKey
+		wantErr error
+	}{
+		{"scoped key", APIKey{Name: &name, Scopes: []string{ScopeUsersRead, ScopeMessagesWrite}}, nil},
+		{"no name", APIKey{Scopes: []string{ScopeUsersRead}}, ErrMissingName},
+		{"unknown scope", APIKey{Name: &name, Scopes: []string{"admin"}}, ErrInvalidScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryRepository{keys: map[string]APIKey{}}
+
+			issued, err := NewAPIKeyService(repo).CreateAPIKey(context.Background(), tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if !strings.HasPrefix(issued.Key, keyPrefix+issued.Id+".") {
+				t.Errorf("got key %s, want it to start with %s%s.", issued.Key, keyPrefix, issued.Id)
+			}
+
+			// Only the hash of the secret is stored
+			_, secret, _ := strings.Cut(issued.Key, ".")
+			stored := repo.keys[issued.Id]
+			if stored.SecretHash == secret || stored.SecretHash != hashSecret(secret) {
+				t.Error("the stored key doesn't hold the hash of its secret")
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	name := "CRM"
+	repo := &memoryRepository{keys: map[string]APIKey{}}
+	service := NewAPIKeyService(repo)
+
+	issued, err := service.CreateAPIKey(context.Background(), APIKey{Name: &name, Scopes: []string{ScopeUsersRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		rawKey  string
+		wantErr error
+	}{
+		{"valid key", issued.Key, nil},
+		{"wrong secret", keyPrefix + issued.Id + ".wrong", ErrInvalidKey},
+		{"unknown key", keyPrefix + "missing.secret", ErrInvalidKey},
+		{"no prefix", strings.TrimPrefix(issued.Key, keyPrefix), ErrInvalidKey},
+		{"no secret", keyPrefix + issued.Id, ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := service.Authenticate(context.Background(), tt.rawKey)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && key.Id != issued.Id {
+				t.Errorf("got key %s, want %s", key.Id, issued.Id)
+			}
+		})
+	}
+
+	// Last use is recorded at most once a minute
+	if _, err := service.Authenticate(context.Background(), issued.Key); err != nil {
+		t.Fatal(err)
+	}
+	if repo.touched != 1 {
+		t.Errorf("recorded %d uses, want 1", repo.touched)
+	}
+}
+
+func TestRotateAndRevokeAPIKey(t *testing.T) {
+	name := "LMS"
+	repo := &memoryRepository{keys: map[string]APIKey{}}
+	service := NewAPIKeyService(repo)
+	ctx := context.Background()
+
+	issued, err := service.CreateAPIKey(ctx, APIKey{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := service.RotateAPIKey(ctx, issued.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The old secret stops working as soon as the key is rotated
+	if _, err := service.Authenticate(ctx, issued.Key); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("old key: got error %v, want %v", err, ErrInvalidKey)
+	}
+	if _, err := service.Authenticate(ctx, rotated.Key); err != nil {
+		t.Errorf("rotated key: %v", err)
+	}
+
+	if err := service.RevokeAPIKey(ctx, issued.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Authenticate(ctx, rotated.Key); !errors.Is(err, ErrRevoked) {
+		t.Errorf("revoked key: got error %v, want %v", err, ErrRevoked)
+	}
+	if _, err := service.RotateAPIKey(ctx, issued.Id); !errors.Is(err, ErrRevoked) {
+		t.Errorf("rotating a revoked key: got error %v, want %v", err, ErrRevoked)
+	}
+}
+
+func TestAPIKeyTenants(t *testing.T) {
+	name := "CRM"
+	repo := &memoryRepository{keys: map[string]APIKey{}}
+	service := NewAPIKeyService(repo)
+
+	acme := tenant.NewContext(context.Background(), "acme")
+	globex := tenant.NewContext(context.Background(), "globex")
+
+	issued, err := service.CreateAPIKey(acme, APIKey{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys of other tenants are reported as missing and can't be managed
+	if _, err := service.GetAPIKey(globex, issued.Id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrNotFound)
+	}
+	if err := service.RevokeAPIKey(globex, issued.Id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrNotFound)
+	}
+
+	keys, err := service.GetAllAPIKeys(globex, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("got %d keys of another tenant, want none", len(keys))
+	}
+
+	if _, err := service.GetAPIKey(acme, issued.Id); err != nil {
+		t.Errorf("got error %v for a key of the tenant", err)
+	}
+}
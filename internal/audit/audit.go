@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+const (
+	ActionImpersonate       = "impersonate"
+	ActionImpersonateDenied = "impersonate_denied"
+)
+
+// A request made on behalf of someone else, or refused from being made as
+// them. ActorId is who actually made the request and SubjectId who it was
+// made as. Only refused attempts have a status, impersonated requests are
+// recorded before they are served.
+type Record struct {
+	Id        string    `json:"id" firestore:"id"`
+	Action    string    `json:"action" firestore:"action"`
+	ActorId   string    `json:"actor_id" firestore:"actor_id"`
+	SubjectId string    `json:"subject_id" firestore:"subject_id"`
+	Method    string    `json:"method" firestore:"method"`
+	Path      string    `json:"path" firestore:"path"`
+	Status    int       `json:"status,omitempty" firestore:"status,omitempty"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+}
+
+// Implements the audit repository interface design pattern
+type AuditRepository interface {
+	PostRecord(ctx context.Context, record Record) (Record, error)
+	GetRecords(ctx context.Context, page int, pageSize int) ([]Record, error)
+}
+
+type AuditService struct {
+	auditRepository AuditRepository
+}
+
+func NewAuditService(auditRepository AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepository: auditRepository,
+	}
+}
+
+func (service *AuditService) RecordAction(ctx context.Context, record Record) (Record, error) {
+	log.Debugf("Recording %s by %s as %s . . .", record.Action, record.ActorId, record.SubjectId)
+
+	record.Id = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+
+	record, err := service.auditRepository.PostRecord(ctx, record)
+	if err != nil {
+		log.Error("Failed to record the action")
+		return Record{}, err
+	}
+
+	return record, nil
+}
+
+func (service *AuditService) GetRecords(ctx context.Context, page int, pageSize int) ([]Record, error) {
+	log.Debug("Retrieving audit records . . .")
+
+	records, err := service.auditRepository.GetRecords(ctx, page, pageSize)
+	if err != nil {
+		log.Error("Failed to retrieve audit records")
+		return nil, err
+	}
+
+	return records, nil
+}
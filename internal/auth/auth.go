@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+var (
+	ErrInvalidToken  = common.NewError(common.KindUnauthorized, "invalid token")
+	ErrUnknownTenant = common.NewError(common.KindUnauthorized, "the token does not belong to a tenant of this deployment")
+)
+
+// Binds the tokens of a verifier to the tenants of the deployment. The tenant
+// a token names is never trusted on its own: it must be one of the configured
+// tenants, or the verifier serves a single tenant and every token belongs to
+// it. Without any tenants configured, tenancy is off and every token belongs
+// to the default tenant whatever it claims.
+type Tenancy struct {
+	// The tenant of every token, for identity providers that serve one tenant
+	Fixed string
+
+	// The tenants tokens may name in their tenant claim
+	Allowed []string
+}
+
+func (t Tenancy) Enabled() bool {
+	return t.Fixed != "" || len(t.Allowed) > 0
+}
+
+// Returns the tenant of a token from the tenant it claims
+func (t Tenancy) resolve(claimed string) (string, error) {
+	if t.Fixed != "" {
+		if claimed != "" && claimed != t.Fixed {
+			return "", fmt.Errorf("%w: %s", ErrUnknownTenant, claimed)
+		}
+		return t.Fixed, nil
+	}
+
+	if !t.Enabled() {
+		return "", nil
+	}
+
+	for _, allowed := range t.Allowed {
+		if claimed == allowed {
+			return claimed, nil
+		}
+	}
+
+	if claimed == "" {
+		return "", fmt.Errorf("%w: missing tenant", ErrUnknownTenant)
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownTenant, claimed)
+}
+
+// Firebase nests the tenant of Identity Platform tokens in its own claim
+func tenantClaim(claims map[string]interface{}) string {
+	if tenantId, ok := claims["tenant_id"].(string); ok {
+		return tenantId
+	}
+
+	if firebaseClaim, ok := claims["firebase"].(map[string]interface{}); ok {
+		tenantId, _ := firebaseClaim["tenant"].(string)
+		return tenantId
+	}
+
+	return ""
+}
+
+// Builds the identity of a caller from the subject and claims of a verified
+// token. Tokens that don't resolve to a tenant of the deployment are rejected.
+func identityFromClaims(subject string, claims map[string]interface{}, tenancy Tenancy) (identity.Identity, error) {
+	tenantId, err := tenancy.resolve(tenantClaim(claims))
+	if err != nil {
+		return identity.Identity{}, err
+	}
+
+	ident := identity.Identity{
+		UID:      subject,
+		TenantId: tenantId,
+		Claims:   claims,
+	}
+
+	if email, ok := claims["email"].(string); ok {
+		ident.Email = email
+	}
+
+	return ident, nil
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestTenancyResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		tenancy Tenancy
+		claimed string
+		want    string
+		wantErr error
+	}{
+		{"tenancy off", Tenancy{}, "", "", nil},
+		{"tenancy off ignores claims", Tenancy{}, "acme", "", nil},
+		{"fixed tenant", Tenancy{Fixed: "acme"}, "", "acme", nil},
+		{"fixed tenant claimed", Tenancy{Fixed: "acme"}, "acme", "acme", nil},
+		{"other than the fixed tenant", Tenancy{Fixed: "acme"}, "globex", "", ErrUnknownTenant},
+		{"allowed tenant", Tenancy{Allowed: []string{"acme", "globex"}}, "globex", "globex", nil},
+		{"unknown tenant", Tenancy{Allowed: []string{"acme"}}, "initech", "", ErrUnknownTenant},
+		{"missing tenant", Tenancy{Allowed: []string{"acme"}}, "", "", ErrUnknownTenant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tenancy.resolve(tt.claimed)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got tenant %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantClaim(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		want   string
+	}{
+		{"no tenant", map[string]interface{}{}, ""},
+		{"tenant claim", map[string]interface{}{"tenant_id": "acme"}, "acme"},
+		{"firebase tenant", map[string]interface{}{"firebase": map[string]interface{}{"tenant": "acme"}}, "acme"},
+		{"tenant claim first", map[string]interface{}{"tenant_id": "acme", "firebase": map[string]interface{}{"tenant": "globex"}}, "acme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tenantClaim(tt.claims); got != tt.want {
+				t.Errorf("tenantClaim() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHMACVerifierTenant(t *testing.T) {
+	secret := []byte("test-secret")
+	verifier := NewHMACVerifier(secret, Tenancy{Allowed: []string{"acme"}})
+
+	sign := func(claims jwt.MapClaims) string {
+		claims["sub"] = "user-1"
+		claims["email"] = "lee@example.com"
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	ident, err := verifier.VerifyToken(context.Background(), sign(jwt.MapClaims{"tenant_id": "acme"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ident.UID != "user-1" || ident.TenantId != "acme" || ident.Email != "lee@example.com" {
+		t.Errorf("got identity %+v, want user-1 of acme", ident)
+	}
+
+	// A valid signature doesn't let a token into a tenant of another deployment
+	if _, err := verifier.VerifyToken(context.Background(), sign(jwt.MapClaims{"tenant_id": "globex"})); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("got error %v, want %v", err, ErrUnknownTenant)
+	}
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	firebase "firebase.google.com/go"
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+)
+
+// Verifies Firebase Authentication ID tokens
+type FirebaseVerifier struct {
+	app     *firebase.App
+	tenancy Tenancy
+}
+
+func NewFirebaseVerifier(app *firebase.App, tenancy Tenancy) *FirebaseVerifier {
+	return &FirebaseVerifier{
+		app:     app,
+		tenancy: tenancy,
+	}
+}
+
+func (v *FirebaseVerifier) VerifyToken(ctx context.Context, rawToken string) (identity.Identity, error) {
+	client, err := v.app.Auth(ctx)
+	if err != nil {
+		log.Errorf("error getting Auth client: %v\n", err)
+		return identity.Identity{}, err
+	}
+
+	token, err := client.VerifyIDToken(ctx, rawToken)
+	if err != nil {
+		return identity.Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return identityFromClaims(token.UID, token.Claims, v.tenancy)
+}
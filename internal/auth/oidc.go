@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+)
+
+// Verifies ID tokens of any OpenID Connect provider against the keys it
+// publishes through its discovery document
+type OIDCVerifier struct {
+	verifier *oidc.IDTokenVerifier
+	tenancy  Tenancy
+}
+
+func NewOIDCVerifier(ctx context.Context, issuerURL string, clientID string, tenancy Tenancy) (*OIDCVerifier, error) {
+	provider, err := oidc.NewProvider(ctx, issuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCVerifier{
+		verifier: provider.Verifier(&oidc.Config{ClientID: clientID}),
+		tenancy:  tenancy,
+	}, nil
+}
+
+func (v *OIDCVerifier) VerifyToken(ctx context.Context, rawToken string) (identity.Identity, error) {
+	token, err := v.verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return identity.Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims map[string]interface{}
+	if err := token.Claims(&claims); err != nil {
+		return identity.Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return identityFromClaims(token.Subject, claims, v.tenancy)
+}
@@ -0,0 +1,201 @@
+package contract
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	mergePatch = "application/merge-patch+json"
+	ndjson     = "application/x-ndjson"
+
+	questionSetBody = `{
+		"technology_name": "AWS",
+		"questions": [{
+			"category": "Experience",
+			"text": "Which services have you used?",
+			"options": {"multi_answer": true, "possible_options": ["EC2", "S3", "Lambda"]}
+		}]
+	}`
+
+	courseOutlineBody = `{
+		"technology_name": "AWS",
+		"course_code": "AWS-101",
+		"course_name": "Cloud Practitioner Essentials",
+		"outline": "Module 1: Introduction to the cloud"
+	}`
+
+	userBody = `{"name": "Lee Learner", "email_address": "lee@contract.test"}`
+
+	organizationBody = `{"name": "Acme", "settings": {"allowed_technologies": ["AWS"]}}`
+
+	graphqlBody = `{
+		"query": "query Overview($userId: ID!) { user(id: $userId) { name messages(pageSize: 5) { messageText pending } } questionSets { technologyName questions { text } courseOutlines { courseCode } } courseOutlines(filter: {field: TECHNOLOGY_NAME, value: \"AWS\"}) { courseName } }",
+		"variables": {"userId": "user-1"}
+	}`
+
+	// The first rows update what the single requests created
+	questionSetsNDJSON = `{"technology_name": "aws", "questions": [{"category": "Experience", "text": "Which services have you used?"}]}
+{"technology_name": "GCP"}
+{"technology_name": "GCP"}
+{"owner": "me"}
+`
+
+	questionSetsYAML = `- technology_name: Azure
+  questions:
+    - category: Experience
+      text: Which services have you used?
+      options:
+        multi_answer: true
+        possible_options: [VMs, Blob Storage]
+`
+
+	courseOutlinesCSV = "technology_name,course_code,course_name,outline\n" +
+		"AWS,AWS-101,Cloud Practitioner Essentials,\"Module 1: Introduction to the cloud\nModule 2: Compute\"\n" +
+		"AWS,AWS-201,Architecting on AWS,\n" +
+		"AWS,,Missing Code,\n"
+
+	courseOutlinesNDJSON = `{"technology_name": "GCP", "course_code": "GCP-101", "course_name": "Google Cloud Fundamentals"}
+`
+
+	answersBody = `[{
+		"answer": {
+			"question": {"category": "Experience", "text": "Which services have you used?"},
+			"technology_name": "AWS",
+			"answer": "EC2"
+		}
+	}]`
+)
+
+// Ids handed out by the in-memory services, which count from one per kind
+const (
+	questionSetPath   = "/api/v1/question-sets/question-set-1"
+	courseOutlinePath = "/api/v1/course-outlines/course-outline-1"
+	userPath          = "/api/v1/users/user-1"
+	messagesPath      = "/api/v1/users/user-1/messages"
+	messagePath       = "/api/v1/users/user-1/messages/message-1"
+	organizationPath  = "/api/v1/organizations/organization-1"
+	apiKeyPath        = "/api/v1/api-keys/key-1"
+	apiKey            = "sk_key-1.secret"
+)
+
+var staleVersion = map[string]string{"If-Match": `"0"`}
+
+var weakVersion = map[string]string{"If-Match": `W/"0"`}
+
+var idempotencyKey = map[string]string{"Idempotency-Key": "answers-1"}
+
+// Exercises every documented operation with its success response and the
+// error responses clients rely on. Cases run in order against one handler,
+// later cases use what earlier ones created.
+func Cases() []Case {
+	return []Case{
+		{Name: "health check", Method: http.MethodGet, Path: "/", Status: http.StatusOK},
+		{Name: "unknown route", Method: http.MethodGet, Path: "/api/v1/unknown", Status: http.StatusNotFound},
+		{Name: "bad token", Method: http.MethodGet, Path: "/api/v1/me", Header: map[string]string{"Authorization": "Bearer wrong"}, Status: http.StatusUnauthorized},
+		{Name: "method not allowed", Method: http.MethodDelete, Path: "/api/v1/me", Status: http.StatusMethodNotAllowed},
+		{Name: "get me", Method: http.MethodGet, Path: "/api/v1/me", Status: http.StatusOK},
+
+		{Name: "post question set", Method: http.MethodPost, Path: "/api/v1/question-sets", Body: questionSetBody, Status: http.StatusOK},
+		{Name: "post duplicate question set", Method: http.MethodPost, Path: "/api/v1/question-sets", Body: questionSetBody, Status: http.StatusConflict},
+		{Name: "post question set without name", Method: http.MethodPost, Path: "/api/v1/question-sets", Body: `{"questions": []}`, Status: http.StatusBadRequest},
+		{Name: "post question set with unknown field", Method: http.MethodPost, Path: "/api/v1/question-sets", Body: `{"technology_name": "GCP", "owner": "me"}`, Status: http.StatusBadRequest},
+		{Name: "list question sets", Method: http.MethodGet, Path: "/api/v1/question-sets?page=1&pageSize=5", Status: http.StatusOK},
+		{Name: "get question set by name", Method: http.MethodGet, Path: "/api/v1/question-sets?name=AWS", Status: http.StatusOK},
+		{Name: "get missing question set by name", Method: http.MethodGet, Path: "/api/v1/question-sets?name=Azure", Status: http.StatusNotFound},
+		{Name: "get question set", Method: http.MethodGet, Path: questionSetPath, Status: http.StatusOK},
+		{Name: "get missing question set", Method: http.MethodGet, Path: "/api/v1/question-sets/missing", Status: http.StatusNotFound},
+		{Name: "put question set", Method: http.MethodPut, Path: questionSetPath, Body: questionSetBody, Status: http.StatusOK},
+		{Name: "put stale question set", Method: http.MethodPut, Path: questionSetPath, Body: questionSetBody, Header: staleVersion, Status: http.StatusPreconditionFailed},
+		{Name: "put question set with weak etag", Method: http.MethodPut, Path: questionSetPath, Body: questionSetBody, Header: weakVersion, Status: http.StatusPreconditionFailed},
+		{Name: "patch question set", Method: http.MethodPatch, Path: questionSetPath, Body: `{"questions": null}`, ContentType: mergePatch, Status: http.StatusOK},
+		{Name: "patch question set as text", Method: http.MethodPatch, Path: questionSetPath, Body: `{}`, ContentType: "text/plain", Status: http.StatusBadRequest},
+		{Name: "delete question set", Method: http.MethodDelete, Path: questionSetPath, Status: http.StatusOK},
+		{Name: "list deleted question sets", Method: http.MethodGet, Path: "/api/v1/question-sets?includeDeleted=true", Status: http.StatusOK},
+		{Name: "restore question set", Method: http.MethodPost, Path: questionSetPath + "/restore", Status: http.StatusOK},
+		{Name: "import question sets", Method: http.MethodPost, Path: "/api/v1/question-sets/import", Body: questionSetsNDJSON, ContentType: ndjson, Status: http.StatusOK},
+		{Name: "dry run question set import", Method: http.MethodPost, Path: "/api/v1/question-sets/import?dryRun=true", Body: questionSetsYAML, ContentType: "application/yaml", Status: http.StatusOK},
+		{Name: "import question sets as csv", Method: http.MethodPost, Path: "/api/v1/question-sets/import", Body: "technology_name\nAWS\n", ContentType: "text/csv", Status: http.StatusBadRequest},
+		{Name: "import no question sets", Method: http.MethodPost, Path: "/api/v1/question-sets/import", Body: "\n", ContentType: ndjson, Status: http.StatusBadRequest},
+		{Name: "export question sets", Method: http.MethodGet, Path: "/api/v1/question-sets/export", Status: http.StatusOK},
+		{Name: "export question sets as yaml", Method: http.MethodGet, Path: "/api/v1/question-sets/export?format=yaml", Status: http.StatusOK},
+		{Name: "export question sets as csv", Method: http.MethodGet, Path: "/api/v1/question-sets/export?format=csv", Status: http.StatusBadRequest},
+
+		{Name: "post course outline", Method: http.MethodPost, Path: "/api/v1/course-outlines", Body: courseOutlineBody, Status: http.StatusOK},
+		{Name: "post duplicate course outline", Method: http.MethodPost, Path: "/api/v1/course-outlines", Body: courseOutlineBody, Status: http.StatusConflict},
+		{Name: "list course outlines", Method: http.MethodGet, Path: "/api/v1/course-outlines", Status: http.StatusOK},
+		{Name: "filter course outlines", Method: http.MethodGet, Path: "/api/v1/course-outlines?filterName=technology_name&filterValue=AWS", Status: http.StatusOK},
+		{Name: "filter course outlines without match", Method: http.MethodGet, Path: "/api/v1/course-outlines?filterName=technology_name&filterValue=Azure", Status: http.StatusOK},
+		{Name: "get course outline", Method: http.MethodGet, Path: courseOutlinePath, Status: http.StatusOK},
+		{Name: "put course outline", Method: http.MethodPut, Path: courseOutlinePath, Body: courseOutlineBody, Status: http.StatusOK},
+		{Name: "patch course outline", Method: http.MethodPatch, Path: courseOutlinePath, Body: `{"outline": null}`, ContentType: mergePatch, Status: http.StatusOK},
+		{Name: "patch stale course outline", Method: http.MethodPatch, Path: courseOutlinePath, Body: `{}`, ContentType: mergePatch, Header: staleVersion, Status: http.StatusPreconditionFailed},
+		{Name: "delete course outline", Method: http.MethodDelete, Path: courseOutlinePath, Status: http.StatusOK},
+		{Name: "restore course outline", Method: http.MethodPost, Path: courseOutlinePath + "/restore", Status: http.StatusOK},
+		{Name: "import course outlines", Method: http.MethodPost, Path: "/api/v1/course-outlines/import", Body: courseOutlinesCSV, ContentType: "text/csv", Status: http.StatusOK},
+		{Name: "dry run course outline import", Method: http.MethodPost, Path: "/api/v1/course-outlines/import?dryRun=true", Body: courseOutlinesNDJSON, ContentType: ndjson, Status: http.StatusOK},
+		{Name: "import course outlines with unknown column", Method: http.MethodPost, Path: "/api/v1/course-outlines/import", Body: "course_code,owner\nAWS-101,me\n", ContentType: "text/csv", Status: http.StatusBadRequest},
+		{Name: "export course outlines", Method: http.MethodGet, Path: "/api/v1/course-outlines/export", Status: http.StatusOK},
+		{Name: "export course outlines as csv", Method: http.MethodGet, Path: "/api/v1/course-outlines/export?format=csv", Status: http.StatusOK},
+
+		{Name: "post user", Method: http.MethodPost, Path: "/api/v1/users", Body: userBody, Status: http.StatusOK},
+		{Name: "post user with invalid email", Method: http.MethodPost, Path: "/api/v1/users", Body: `{"name": "Kim", "email_address": "kim"}`, Status: http.StatusBadRequest},
+		{Name: "post user over the size limit", Method: http.MethodPost, Path: "/api/v1/users", Body: `{"name": "` + strings.Repeat("a", 1<<20) + `"}`, Status: http.StatusRequestEntityTooLarge},
+		{Name: "list users", Method: http.MethodGet, Path: "/api/v1/users", Status: http.StatusOK},
+		{Name: "get user", Method: http.MethodGet, Path: userPath, Status: http.StatusOK},
+		{Name: "put user", Method: http.MethodPut, Path: userPath, Body: `{"name": "Lee Learner", "email_address": "lee@contract.test", "roles": ["learner"]}`, Status: http.StatusOK},
+		{Name: "patch user", Method: http.MethodPatch, Path: userPath, Body: `{"name": "Lee"}`, ContentType: mergePatch, Status: http.StatusOK},
+		{Name: "impersonate user", Method: http.MethodGet, Path: "/api/v1/me", Header: map[string]string{"X-Impersonate-User": "user-1"}, Status: http.StatusOK},
+		{Name: "list audit records", Method: http.MethodGet, Path: "/api/v1/audit-records", Status: http.StatusOK},
+
+		{Name: "post organization", Method: http.MethodPost, Path: "/api/v1/organizations", Body: organizationBody, Status: http.StatusOK},
+		{Name: "list organizations", Method: http.MethodGet, Path: "/api/v1/organizations", Status: http.StatusOK},
+		{Name: "get organization", Method: http.MethodGet, Path: organizationPath, Status: http.StatusOK},
+		{Name: "put organization", Method: http.MethodPut, Path: organizationPath, Body: organizationBody, Status: http.StatusOK},
+		{Name: "put member", Method: http.MethodPut, Path: organizationPath + "/members/user-1", Body: `{"role": "manager"}`, Status: http.StatusOK},
+		{Name: "put member with invalid role", Method: http.MethodPut, Path: organizationPath + "/members/user-1", Body: `{"role": "owner"}`, Status: http.StatusBadRequest},
+		{Name: "list members", Method: http.MethodGet, Path: organizationPath + "/members", Status: http.StatusOK},
+		{Name: "remove member", Method: http.MethodDelete, Path: organizationPath + "/members/user-1", Status: http.StatusOK},
+		{Name: "delete organization", Method: http.MethodDelete, Path: organizationPath, Status: http.StatusOK},
+		{Name: "restore organization", Method: http.MethodPost, Path: organizationPath + "/restore", Status: http.StatusOK},
+
+		{Name: "post message", Method: http.MethodPost, Path: messagesPath, Body: `{"message_text": "Hello"}`, Status: http.StatusOK},
+		{Name: "post empty message", Method: http.MethodPost, Path: messagesPath, Body: `{}`, Status: http.StatusBadRequest},
+		{Name: "post answers", Method: http.MethodPost, Path: messagesPath + "/answers", Body: answersBody, Status: http.StatusOK},
+		{Name: "post answer without question text", Method: http.MethodPost, Path: messagesPath + "/answers", Body: `[{"answer": {"question": {"category": "Usage"}, "answer": "Often"}}]`, Status: http.StatusBadRequest},
+		{Name: "post answers with idempotency key", Method: http.MethodPost, Path: messagesPath + "/answers", Body: answersBody, Header: idempotencyKey, Status: http.StatusOK},
+		{Name: "replay answers", Method: http.MethodPost, Path: messagesPath + "/answers", Body: answersBody, Header: idempotencyKey, Status: http.StatusOK},
+		{Name: "reuse idempotency key", Method: http.MethodPost, Path: messagesPath + "/answers", Body: `[{"message_text": "Other"}]`, Header: idempotencyKey, Status: http.StatusUnprocessableEntity},
+		{Name: "list messages", Method: http.MethodGet, Path: messagesPath, Status: http.StatusOK},
+		{Name: "get message events without upgrading", Method: http.MethodGet, Path: messagesPath + "/events", Status: http.StatusBadRequest},
+		{Name: "post message events ticket", Method: http.MethodPost, Path: messagesPath + "/events/tickets", Status: http.StatusCreated},
+		{Name: "get message", Method: http.MethodGet, Path: messagePath, Status: http.StatusOK},
+		{Name: "wait for message", Method: http.MethodGet, Path: messagePath + "?wait=30s", Status: http.StatusOK},
+		{Name: "wait for message too long", Method: http.MethodGet, Path: messagePath + "?wait=10m", Status: http.StatusBadRequest},
+		{Name: "get message of another user", Method: http.MethodGet, Path: "/api/v1/users/" + AdminId + "/messages/message-1", Status: http.StatusNotFound},
+		{Name: "put message", Method: http.MethodPut, Path: messagePath, Body: `{"message_text": "Hello again"}`, Status: http.StatusOK},
+		{Name: "patch message", Method: http.MethodPatch, Path: messagePath, Body: `{"message_text": "Hi"}`, ContentType: mergePatch, Status: http.StatusOK},
+		{Name: "delete message", Method: http.MethodDelete, Path: messagePath, Status: http.StatusOK},
+		{Name: "restore message", Method: http.MethodPost, Path: messagePath + "/restore", Status: http.StatusOK},
+
+		{Name: "post graphql query", Method: http.MethodPost, Path: "/api/v1/graphql", Body: graphqlBody, Status: http.StatusOK},
+		{Name: "post graphql without query", Method: http.MethodPost, Path: "/api/v1/graphql", Body: `{"variables": {}}`, Status: http.StatusBadRequest},
+
+		{Name: "post api key", Method: http.MethodPost, Path: "/api/v1/api-keys", Body: `{"name": "CI", "scopes": ["question-sets:read"]}`, Status: http.StatusCreated},
+		{Name: "list api keys", Method: http.MethodGet, Path: "/api/v1/api-keys", Status: http.StatusOK},
+		{Name: "get api key", Method: http.MethodGet, Path: apiKeyPath, Status: http.StatusOK},
+		{Name: "rotate api key", Method: http.MethodPost, Path: apiKeyPath + "/rotate", Status: http.StatusOK},
+		{Name: "read with api key", Method: http.MethodGet, Path: "/api/v1/question-sets", Header: map[string]string{"X-API-Key": apiKey}, Status: http.StatusOK},
+		{Name: "write with read-only api key", Method: http.MethodPost, Path: "/api/v1/question-sets", Body: questionSetBody, Header: map[string]string{"X-API-Key": apiKey}, Status: http.StatusForbidden},
+		{Name: "revoke api key", Method: http.MethodDelete, Path: apiKeyPath, Status: http.StatusOK},
+		{Name: "read with revoked api key", Method: http.MethodGet, Path: "/api/v1/question-sets", Header: map[string]string{"X-API-Key": apiKey}, Status: http.StatusUnauthorized},
+
+		{Name: "export user", Method: http.MethodGet, Path: "/api/v1/users/user-1/export", Status: http.StatusOK},
+		{Name: "erase user", Method: http.MethodPost, Path: "/api/v1/users/user-1/erasure", Status: http.StatusOK},
+		{Name: "erase missing user", Method: http.MethodPost, Path: "/api/v1/users/user-1/erasure", Status: http.StatusNotFound},
+
+		{Name: "post second user", Method: http.MethodPost, Path: "/api/v1/users", Body: `{"name": "Sam", "email_address": "sam@contract.test"}`, Status: http.StatusOK},
+		{Name: "delete user", Method: http.MethodDelete, Path: "/api/v1/users/user-2", Status: http.StatusOK},
+		{Name: "restore user", Method: http.MethodPost, Path: "/api/v1/users/user-2/restore", Status: http.StatusOK},
+	}
+}
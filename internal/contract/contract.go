@@ -0,0 +1,304 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/go-chi/chi/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/auth"
+	"github.com/zzenonn/scoping-ai/internal/idempotency"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	"github.com/zzenonn/scoping-ai/internal/ticket"
+	"github.com/zzenonn/scoping-ai/internal/transport/graphql"
+	transportHttp "github.com/zzenonn/scoping-ai/internal/transport/http"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+	// Exports are only checked for their content type, not unpacked
+	openapi3filter.RegisterBodyDecoder("application/zip", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("application/x-ndjson", openapi3filter.PlainBodyDecoder)
+
+	// Failures name the offending field, dumping the whole schema buries it
+	openapi3.SchemaErrorDetailsDisabled = true
+}
+
+// Bearer token the contract suite authenticates with. It belongs to an admin
+// so every route is reachable.
+const (
+	AdminToken = "contract-admin-token"
+	AdminId    = "contract-admin"
+)
+
+type staticVerifier struct{}
+
+func (staticVerifier) VerifyToken(ctx context.Context, rawToken string) (identity.Identity, error) {
+	if rawToken != AdminToken {
+		return identity.Identity{}, auth.ErrInvalidToken
+	}
+
+	return identity.Identity{
+		UID:    AdminId,
+		Email:  "admin@contract.test",
+		Claims: map[string]interface{}{"roles": []interface{}{"admin"}},
+	}, nil
+}
+
+// Boots the router the server runs with, backed by in-memory services
+func NewHandler() *transportHttp.MainHandler {
+	users := NewUserService()
+	messages := NewMessageService()
+	questionSets := NewQuestionSetService()
+	courseOutlines := NewCourseOutlineService()
+	apiKeys := NewAPIKeyService()
+	auditService := NewAuditService()
+	tickets := ticket.NewTicketService(NewTicketRepository())
+
+	policy := transportHttp.NewAccessPolicy(users)
+
+	h := transportHttp.NewMainHandler(staticVerifier{}, apiKeys, policy, transportHttp.DefaultCorsConfig(), nil, nil, auditService, idempotency.NewIdempotencyService(NewIdempotencyRepository(), time.Hour), tickets)
+
+	h.AddHandler(transportHttp.NewQuestionSetHandler(questionSets))
+	h.AddHandler(transportHttp.NewCourseOutlineHandler(courseOutlines))
+	h.AddHandler(transportHttp.NewUserHandler(users))
+	h.AddHandler(transportHttp.NewOrganizationHandler(NewOrganizationService(users)))
+	h.AddHandler(transportHttp.NewPrivacyHandler(NewPrivacyService(users, messages)))
+	h.AddHandler(transportHttp.NewMessageHandler(messages, nil, scopingMessage.NewHub(), tickets, transportHttp.DefaultCorsConfig()))
+	h.AddHandler(transportHttp.NewAPIKeyHandler(apiKeys))
+	h.AddHandler(transportHttp.NewAuditHandler(auditService))
+	h.AddHandler(transportHttp.NewGraphQLHandler(graphql.NewHandler(questionSets, courseOutlines, users, messages, policy)))
+
+	h.MapRoutes()
+
+	return h
+}
+
+// Loads the spec and checks that it is valid OpenAPI 3
+func LoadSpec(path string) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+
+	spec, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := spec.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+
+	return spec, nil
+}
+
+// A request sent to the router and the status it must answer with
+type Case struct {
+	Name        string
+	Method      string
+	Path        string
+	Body        string
+	ContentType string
+	Header      map[string]string
+	Status      int
+}
+
+// A way in which the router and the spec disagree
+type Failure struct {
+	Case    string
+	Message string
+}
+
+func (f Failure) String() string {
+	if f.Case == "" {
+		return f.Message
+	}
+	return fmt.Sprintf("%s: %s", f.Case, f.Message)
+}
+
+func operationKey(method string, path string) string {
+	return method + " " + path
+}
+
+// Compares the routes of the router with the operations of the spec, in
+// both directions
+func CheckRoutes(router chi.Routes, spec *openapi3.T) []Failure {
+	served := map[string]bool{}
+
+	chi.Walk(router, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+		served[operationKey(method, route)] = true
+		return nil
+	})
+
+	documented := map[string]bool{}
+	for path, item := range spec.Paths.Map() {
+		for method := range item.Operations() {
+			documented[operationKey(method, path)] = true
+		}
+	}
+
+	var failures []Failure
+
+	for _, key := range sortedKeys(served) {
+		if !documented[key] {
+			failures = append(failures, Failure{Message: fmt.Sprintf("%s is served but not documented", key)})
+		}
+	}
+
+	for _, key := range sortedKeys(documented) {
+		if !served[key] {
+			failures = append(failures, Failure{Message: fmt.Sprintf("%s is documented but not served", key)})
+		}
+	}
+
+	return failures
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c Case) newRequest() *http.Request {
+	r := httptest.NewRequest(c.Method, c.Path, strings.NewReader(c.Body))
+
+	if c.Body != "" {
+		contentType := c.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		r.Header.Set("Content-Type", contentType)
+	}
+
+	if _, ok := c.Header["X-API-Key"]; !ok {
+		r.Header.Set("Authorization", "Bearer "+AdminToken)
+	}
+
+	for name, value := range c.Header {
+		r.Header.Set(name, value)
+	}
+
+	return r
+}
+
+// Sends each case to the handler in order and validates the responses
+// against the spec. Requests are validated as well unless the case expects
+// them to be rejected. Operations no case reached are reported too.
+func Run(spec *openapi3.T, handler http.Handler, cases []Case) []Failure {
+	router, err := gorillamux.NewRouter(spec)
+	if err != nil {
+		return []Failure{{Message: err.Error()}}
+	}
+
+	options := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		IncludeResponseStatus: true,
+		MultiError:            true,
+	}
+
+	covered := map[*openapi3.Operation]bool{}
+
+	var failures []Failure
+
+	for _, c := range cases {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, c.newRequest())
+
+		if recorder.Code != c.Status {
+			failures = append(failures, Failure{Case: c.Name, Message: fmt.Sprintf("expected status %d, got %d: %s", c.Status, recorder.Code, strings.TrimSpace(recorder.Body.String()))})
+		}
+
+		// The handler consumed the body of its request
+		request := c.newRequest()
+
+		route, pathParams, err := router.FindRoute(request)
+		if err != nil {
+			if c.Status != http.StatusNotFound && c.Status != http.StatusMethodNotAllowed {
+				failures = append(failures, Failure{Case: c.Name, Message: fmt.Sprintf("no documented operation: %v", err)})
+			}
+			continue
+		}
+
+		covered[route.Operation] = true
+
+		failures = append(failures, validate(c, route, pathParams, request, recorder, options)...)
+	}
+
+	uncovered := map[string]bool{}
+	for path, item := range spec.Paths.Map() {
+		for method, operation := range item.Operations() {
+			if !covered[operation] {
+				uncovered[operationKey(method, path)] = true
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(uncovered) {
+		failures = append(failures, Failure{Message: fmt.Sprintf("no case covers %s", key)})
+	}
+
+	return failures
+}
+
+func validate(c Case, route *routers.Route, pathParams map[string]string, request *http.Request, recorder *httptest.ResponseRecorder, options *openapi3filter.Options) []Failure {
+	ctx := context.Background()
+
+	var failures []Failure
+
+	requestInput := &openapi3filter.RequestValidationInput{
+		Request:    request,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    options,
+	}
+
+	if c.Status < http.StatusBadRequest {
+		if err := openapi3filter.ValidateRequest(ctx, requestInput); err != nil {
+			failures = append(failures, Failure{Case: c.Name, Message: fmt.Sprintf("request doesn't match the spec: %v", err)})
+		}
+	}
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestInput,
+		Status:                 recorder.Code,
+		Header:                 recorder.Header(),
+		Options:                options,
+	}
+	responseInput.SetBodyBytes(recorder.Body.Bytes())
+
+	if err := openapi3filter.ValidateResponse(ctx, responseInput); err != nil {
+		failures = append(failures, Failure{Case: c.Name, Message: fmt.Sprintf("response doesn't match the spec: %v", err)})
+	}
+
+	return failures
+}
@@ -0,0 +1,1003 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apikeys "github.com/zzenonn/scoping-ai/internal/apikey"
+	"github.com/zzenonn/scoping-ai/internal/audit"
+	"github.com/zzenonn/scoping-ai/internal/idempotency"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	organization "github.com/zzenonn/scoping-ai/internal/organization"
+	outline "github.com/zzenonn/scoping-ai/internal/outline"
+	"github.com/zzenonn/scoping-ai/internal/privacy"
+	questionSet "github.com/zzenonn/scoping-ai/internal/question-set"
+	"github.com/zzenonn/scoping-ai/internal/ticket"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+// In-memory stand-ins for the services behind the HTTP handlers. They keep
+// just enough behavior for every route to answer with each of its documented
+// responses: lookups, pagination, soft deletes and versions.
+
+// Records of one kind in insertion order
+type table[T any] struct {
+	mu      sync.Mutex
+	items   map[string]T
+	order   []string
+	counter int
+}
+
+func newTable[T any]() *table[T] {
+	return &table[T]{items: map[string]T{}}
+}
+
+func (t *table[T]) nextId(prefix string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.counter++
+	return fmt.Sprintf("%s-%d", prefix, t.counter)
+}
+
+func (t *table[T]) get(id string) (T, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item, ok := t.items[id]
+	return item, ok
+}
+
+func (t *table[T]) put(id string, item T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.items[id]; !ok {
+		t.order = append(t.order, id)
+	}
+	t.items[id] = item
+}
+
+func (t *table[T]) ids() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]string(nil), t.order...)
+}
+
+func (t *table[T]) remove(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.items, id)
+	for i, held := range t.order {
+		if held == id {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Returns a page of the records the filter keeps
+func (t *table[T]) list(page int, pageSize int, keep func(T) bool) []T {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var items []T
+	for _, id := range t.order {
+		if item := t.items[id]; keep(item) {
+			items = append(items, item)
+		}
+	}
+
+	start := (page - 1) * pageSize
+	if start >= len(items) {
+		return nil
+	}
+
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[start:end]
+}
+
+// Versions stand in for the update times of stored documents
+var versionCounter struct {
+	sync.Mutex
+	n int
+}
+
+func nextVersion() string {
+	versionCounter.Lock()
+	defer versionCounter.Unlock()
+
+	versionCounter.n++
+	return strconv.Itoa(versionCounter.n)
+}
+
+func callerId(ctx context.Context) *string {
+	ident, _ := identity.FromContext(ctx)
+	return &ident.UID
+}
+
+func now() *time.Time {
+	t := time.Now().UTC()
+	return &t
+}
+
+func all[T any](T) bool {
+	return true
+}
+
+// Upserts records by a key compared case insensitively, like the unique
+// fields of the database. Matching records are replaced, and restored if
+// they were deleted. Stored records get their id and a new version from store.
+func importRecords[T any](
+	t *table[T], items []T, dryRun bool, prefix string,
+	key func(T) string, deleted func(T) bool, store func(item T, id string) T,
+) []common.ImportResult {
+	normalize := func(value string) string {
+		return strings.ToLower(strings.TrimSpace(value))
+	}
+
+	held := map[string]string{}
+	for _, id := range t.ids() {
+		if item, ok := t.get(id); ok {
+			held[normalize(key(item))] = id
+		}
+	}
+
+	results := make([]common.ImportResult, len(items))
+	seen := map[string]bool{}
+
+	for i, item := range items {
+		value := normalize(key(item))
+		if seen[value] {
+			results[i] = common.ImportFailure(key(item), common.ErrRepeatedImportKey)
+			continue
+		}
+		seen[value] = true
+
+		result := common.ImportResult{Key: key(item), Action: common.ImportCreated}
+
+		if id, ok := held[value]; ok {
+			current, _ := t.get(id)
+			result.Id, result.Action = id, common.ImportUpdated
+			if deleted(current) {
+				result.Action = common.ImportRestored
+			}
+		} else if !dryRun {
+			result.Id = t.nextId(prefix)
+		}
+
+		if !dryRun {
+			t.put(result.Id, store(item, result.Id))
+		}
+		results[i] = result
+	}
+
+	return results
+}
+
+type QuestionSetService struct {
+	sets *table[questionSet.QuestionSet]
+}
+
+func NewQuestionSetService() *QuestionSetService {
+	return &QuestionSetService{sets: newTable[questionSet.QuestionSet]()}
+}
+
+func (s *QuestionSetService) GetQuestionSet(ctx context.Context, id string) (questionSet.QuestionSet, error) {
+	qSet, ok := s.sets.get(id)
+	if !ok || qSet.DeletedAt != nil {
+		return questionSet.QuestionSet{}, questionSet.ErrNotFound
+	}
+	return qSet, nil
+}
+
+func (s *QuestionSetService) GetQuestionSetByTechName(ctx context.Context, technologyName string) (questionSet.QuestionSet, error) {
+	matches := s.sets.list(1, 1, func(qSet questionSet.QuestionSet) bool {
+		return qSet.DeletedAt == nil && qSet.TechnologyName != nil && *qSet.TechnologyName == technologyName
+	})
+	if len(matches) == 0 {
+		return questionSet.QuestionSet{}, questionSet.ErrNotFound
+	}
+	return matches[0], nil
+}
+
+func (s *QuestionSetService) GetAllQuestionSets(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]questionSet.QuestionSet, error) {
+	return s.sets.list(page, pageSize, func(qSet questionSet.QuestionSet) bool {
+		return includeDeleted || qSet.DeletedAt == nil
+	}), nil
+}
+
+func (s *QuestionSetService) PostQuestionSet(ctx context.Context, qSet questionSet.QuestionSet) (questionSet.QuestionSet, error) {
+	if _, err := s.GetQuestionSetByTechName(ctx, *qSet.TechnologyName); err == nil {
+		return questionSet.QuestionSet{}, questionSet.ErrDuplicateTechnologyName
+	}
+
+	qSet.Id = s.sets.nextId("question-set")
+	qSet.Version = nextVersion()
+	s.sets.put(qSet.Id, qSet)
+	return qSet, nil
+}
+
+func (s *QuestionSetService) UpdateQuestionSet(ctx context.Context, qSet questionSet.QuestionSet) (questionSet.QuestionSet, error) {
+	current, err := s.GetQuestionSet(ctx, qSet.Id)
+	if err != nil {
+		return questionSet.QuestionSet{}, err
+	}
+	if qSet.Version != "" && qSet.Version != current.Version {
+		return questionSet.QuestionSet{}, questionSet.ErrVersionMismatch
+	}
+
+	qSet.Version = nextVersion()
+	s.sets.put(qSet.Id, qSet)
+	return qSet, nil
+}
+
+func (s *QuestionSetService) DeleteQuestionSet(ctx context.Context, id string) error {
+	qSet, err := s.GetQuestionSet(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	qSet.DeletedAt, qSet.DeletedBy, qSet.Version = now(), callerId(ctx), nextVersion()
+	s.sets.put(id, qSet)
+	return nil
+}
+
+func (s *QuestionSetService) RestoreQuestionSet(ctx context.Context, id string) error {
+	qSet, ok := s.sets.get(id)
+	if !ok {
+		return questionSet.ErrNotFound
+	}
+
+	qSet.DeletedAt, qSet.DeletedBy, qSet.Version = nil, nil, nextVersion()
+	s.sets.put(id, qSet)
+	return nil
+}
+
+func (s *QuestionSetService) ImportQuestionSets(ctx context.Context, qSets []questionSet.QuestionSet, dryRun bool) ([]common.ImportResult, error) {
+	return importRecords(s.sets, qSets, dryRun, "question-set",
+		func(qSet questionSet.QuestionSet) string { return *qSet.TechnologyName },
+		func(qSet questionSet.QuestionSet) bool { return qSet.DeletedAt != nil },
+		func(qSet questionSet.QuestionSet, id string) questionSet.QuestionSet {
+			qSet.Id, qSet.DeletedAt, qSet.DeletedBy, qSet.Version = id, nil, nil, nextVersion()
+			return qSet
+		},
+	), nil
+}
+
+func (s *QuestionSetService) ExportQuestionSets(ctx context.Context, each func(questionSet.QuestionSet) error) error {
+	for _, id := range s.sets.ids() {
+		if qSet, err := s.GetQuestionSet(ctx, id); err == nil {
+			if err := each(qSet); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type CourseOutlineService struct {
+	outlines *table[outline.CourseOutline]
+}
+
+func NewCourseOutlineService() *CourseOutlineService {
+	return &CourseOutlineService{outlines: newTable[outline.CourseOutline]()}
+}
+
+func (s *CourseOutlineService) PostCourseOutline(ctx context.Context, courseOutline outline.CourseOutline) (outline.CourseOutline, error) {
+	duplicates := s.outlines.list(1, 1, func(held outline.CourseOutline) bool {
+		return *held.CourseCode == *courseOutline.CourseCode
+	})
+	if len(duplicates) > 0 {
+		return outline.CourseOutline{}, outline.ErrDuplicateCourseCode
+	}
+
+	courseOutline.Id = s.outlines.nextId("course-outline")
+	courseOutline.Version = nextVersion()
+	s.outlines.put(courseOutline.Id, courseOutline)
+	return courseOutline, nil
+}
+
+func (s *CourseOutlineService) GetCourseOutline(ctx context.Context, id string) (outline.CourseOutline, error) {
+	courseOutline, ok := s.outlines.get(id)
+	if !ok || courseOutline.DeletedAt != nil {
+		return outline.CourseOutline{}, outline.ErrNotFound
+	}
+	return courseOutline, nil
+}
+
+// Only the fields the course outline list is filtered on in practice
+func (s *CourseOutlineService) GetCourseOutlinesByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]outline.CourseOutline, error) {
+	return s.outlines.list(page, pageSize, func(courseOutline outline.CourseOutline) bool {
+		if !includeDeleted && courseOutline.DeletedAt != nil {
+			return false
+		}
+
+		switch filterName {
+		case "technology_name":
+			return *courseOutline.TechnologyName == filterValue
+		case "course_code":
+			return *courseOutline.CourseCode == filterValue
+		case "course_name":
+			return *courseOutline.CourseName == filterValue
+		default:
+			return false
+		}
+	}), nil
+}
+
+func (s *CourseOutlineService) GetCourseOutlinesByTechnologyNames(ctx context.Context, technologyNames []string) ([]outline.CourseOutline, error) {
+	wanted := map[string]bool{}
+	for _, technologyName := range technologyNames {
+		wanted[technologyName] = true
+	}
+
+	var courseOutlines []outline.CourseOutline
+	for _, id := range s.outlines.ids() {
+		if courseOutline, err := s.GetCourseOutline(ctx, id); err == nil && wanted[*courseOutline.TechnologyName] {
+			courseOutlines = append(courseOutlines, courseOutline)
+		}
+	}
+	return courseOutlines, nil
+}
+
+func (s *CourseOutlineService) GetAllCourseOutlines(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]outline.CourseOutline, error) {
+	return s.outlines.list(page, pageSize, func(courseOutline outline.CourseOutline) bool {
+		return includeDeleted || courseOutline.DeletedAt == nil
+	}), nil
+}
+
+func (s *CourseOutlineService) UpdateCourseOutline(ctx context.Context, courseOutline outline.CourseOutline) (outline.CourseOutline, error) {
+	current, err := s.GetCourseOutline(ctx, courseOutline.Id)
+	if err != nil {
+		return outline.CourseOutline{}, err
+	}
+	if courseOutline.Version != "" && courseOutline.Version != current.Version {
+		return outline.CourseOutline{}, outline.ErrVersionMismatch
+	}
+
+	courseOutline.Version = nextVersion()
+	s.outlines.put(courseOutline.Id, courseOutline)
+	return courseOutline, nil
+}
+
+func (s *CourseOutlineService) DeleteCourseOutline(ctx context.Context, id string) error {
+	courseOutline, err := s.GetCourseOutline(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	courseOutline.DeletedAt, courseOutline.DeletedBy, courseOutline.Version = now(), callerId(ctx), nextVersion()
+	s.outlines.put(id, courseOutline)
+	return nil
+}
+
+func (s *CourseOutlineService) RestoreCourseOutline(ctx context.Context, id string) error {
+	courseOutline, ok := s.outlines.get(id)
+	if !ok {
+		return outline.ErrNotFound
+	}
+
+	courseOutline.DeletedAt, courseOutline.DeletedBy, courseOutline.Version = nil, nil, nextVersion()
+	s.outlines.put(id, courseOutline)
+	return nil
+}
+
+func (s *CourseOutlineService) ImportCourseOutlines(ctx context.Context, courseOutlines []outline.CourseOutline, dryRun bool) ([]common.ImportResult, error) {
+	return importRecords(s.outlines, courseOutlines, dryRun, "course-outline",
+		func(courseOutline outline.CourseOutline) string { return *courseOutline.CourseCode },
+		func(courseOutline outline.CourseOutline) bool { return courseOutline.DeletedAt != nil },
+		func(courseOutline outline.CourseOutline, id string) outline.CourseOutline {
+			courseOutline.Id, courseOutline.DeletedAt, courseOutline.DeletedBy, courseOutline.Version = id, nil, nil, nextVersion()
+			return courseOutline
+		},
+	), nil
+}
+
+func (s *CourseOutlineService) ExportCourseOutlines(ctx context.Context, each func(outline.CourseOutline) error) error {
+	for _, id := range s.outlines.ids() {
+		if courseOutline, err := s.GetCourseOutline(ctx, id); err == nil {
+			if err := each(courseOutline); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Also serves as the user directory of the access policy
+type UserService struct {
+	users *table[scopingUser.User]
+}
+
+func NewUserService() *UserService {
+	return &UserService{users: newTable[scopingUser.User]()}
+}
+
+func (s *UserService) GetUser(ctx context.Context, id string) (scopingUser.User, error) {
+	user, ok := s.users.get(id)
+	if !ok || user.DeletedAt != nil {
+		return scopingUser.User{}, scopingUser.ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *UserService) GetUsersByIds(ctx context.Context, ids []string) ([]scopingUser.User, error) {
+	var users []scopingUser.User
+	for _, id := range ids {
+		if user, err := s.GetUser(ctx, id); err == nil {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (s *UserService) GetAllUsers(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]scopingUser.User, error) {
+	return s.users.list(page, pageSize, func(user scopingUser.User) bool {
+		return includeDeleted || user.DeletedAt == nil
+	}), nil
+}
+
+// Users are only ever filtered on their organization
+func (s *UserService) GetUsersByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]scopingUser.User, error) {
+	return s.users.list(page, pageSize, func(user scopingUser.User) bool {
+		if !includeDeleted && user.DeletedAt != nil {
+			return false
+		}
+		return filterName == "organization_id" && user.OrganizationId != nil && *user.OrganizationId == filterValue
+	}), nil
+}
+
+func (s *UserService) CreateUser(ctx context.Context, user scopingUser.User) (scopingUser.User, error) {
+	duplicates := s.users.list(1, 1, func(held scopingUser.User) bool {
+		return held.EmailAddress != nil && *held.EmailAddress == *user.EmailAddress
+	})
+	if len(duplicates) > 0 {
+		return scopingUser.User{}, scopingUser.ErrDuplicateEmail
+	}
+
+	if user.ID == "" {
+		user.ID = s.users.nextId("user")
+	}
+	user.Version = nextVersion()
+	s.users.put(user.ID, user)
+	return user, nil
+}
+
+func (s *UserService) UpdateUser(ctx context.Context, user scopingUser.User) (scopingUser.User, error) {
+	current, err := s.GetUser(ctx, user.ID)
+	if err != nil {
+		return scopingUser.User{}, err
+	}
+	if user.Version != "" && user.Version != current.Version {
+		return scopingUser.User{}, scopingUser.ErrVersionMismatch
+	}
+
+	user.Corporate, user.OrganizationId, user.OrganizationRole = current.Corporate, current.OrganizationId, current.OrganizationRole
+	user.Version = nextVersion()
+	s.users.put(user.ID, user)
+	return user, nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id string) error {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	user.DeletedAt, user.DeletedBy, user.Version = now(), callerId(ctx), nextVersion()
+	s.users.put(id, user)
+	return nil
+}
+
+func (s *UserService) RestoreUser(ctx context.Context, id string) error {
+	user, ok := s.users.get(id)
+	if !ok {
+		return scopingUser.ErrNotFound
+	}
+
+	user.DeletedAt, user.DeletedBy, user.Version = nil, nil, nextVersion()
+	s.users.put(id, user)
+	return nil
+}
+
+func (s *UserService) ProvisionUser(ctx context.Context, ident identity.Identity) (scopingUser.User, error) {
+	if user, ok := s.users.get(ident.UID); ok {
+		if user.DeletedAt != nil {
+			return scopingUser.User{}, scopingUser.ErrNotFound
+		}
+		return user, nil
+	}
+
+	if ident.Email == "" {
+		return scopingUser.User{}, scopingUser.ErrMissingClaims
+	}
+
+	name := ident.Email
+	email := ident.Email
+
+	return s.CreateUser(ctx, scopingUser.User{ID: ident.UID, Name: &name, EmailAddress: &email})
+}
+
+type MessageService struct {
+	messages *table[scopingMessage.Message]
+}
+
+func NewMessageService() *MessageService {
+	return &MessageService{messages: newTable[scopingMessage.Message]()}
+}
+
+func (s *MessageService) PostMessage(ctx context.Context, message scopingMessage.Message) (scopingMessage.Message, error) {
+	message.Id = s.messages.nextId("message")
+	message.CreatedAt = now()
+	message.UpdatedAt = message.CreatedAt
+	message.Version = nextVersion()
+	s.messages.put(message.Id, message)
+	return message, nil
+}
+
+// Answers are stored and a canned recommendation stands in for the model
+func (s *MessageService) PostAnswers(ctx context.Context, messages []scopingMessage.Message) (scopingMessage.Message, error) {
+	if len(messages) == 0 {
+		return scopingMessage.Message{}, scopingMessage.ErrNoAnswers
+	}
+
+	for _, message := range messages {
+		if message.Answer == nil {
+			return scopingMessage.Message{}, scopingMessage.ErrInvalidAnswer
+		}
+		if _, err := s.PostMessage(ctx, message); err != nil {
+			return scopingMessage.Message{}, err
+		}
+	}
+
+	recommendation := "Recommended course: contract testing"
+
+	return s.PostMessage(ctx, scopingMessage.Message{UserId: messages[0].UserId, MessageText: &recommendation})
+}
+
+func (s *MessageService) GetMessage(ctx context.Context, messageId string, userId string) (scopingMessage.Message, error) {
+	message, ok := s.messages.get(messageId)
+	if !ok || message.DeletedAt != nil || message.UserId == nil || *message.UserId != userId {
+		return scopingMessage.Message{}, scopingMessage.ErrNotFound
+	}
+	return message, nil
+}
+
+// Recommendations are never pending here, so there is nothing to wait for
+func (s *MessageService) WaitForMessage(ctx context.Context, messageId string, userId string, wait time.Duration) (scopingMessage.Message, error) {
+	return s.GetMessage(ctx, messageId, userId)
+}
+
+func (s *MessageService) GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int, includeDeleted bool) ([]scopingMessage.Message, error) {
+	return s.messages.list(page, pageSize, func(message scopingMessage.Message) bool {
+		if !includeDeleted && message.DeletedAt != nil {
+			return false
+		}
+		return message.UserId != nil && *message.UserId == userId
+	}), nil
+}
+
+func (s *MessageService) UpdateMessage(ctx context.Context, message scopingMessage.Message) (scopingMessage.Message, error) {
+	current, err := s.GetMessage(ctx, message.Id, *message.UserId)
+	if err != nil {
+		return scopingMessage.Message{}, err
+	}
+	if message.Version != "" && message.Version != current.Version {
+		return scopingMessage.Message{}, scopingMessage.ErrVersionMismatch
+	}
+
+	message.CreatedAt = current.CreatedAt
+	message.UpdatedAt = now()
+	message.Version = nextVersion()
+	s.messages.put(message.Id, message)
+	return message, nil
+}
+
+func (s *MessageService) DeleteMessage(ctx context.Context, messageId string, userId string) error {
+	message, err := s.GetMessage(ctx, messageId, userId)
+	if err != nil {
+		return err
+	}
+
+	message.DeletedAt, message.DeletedBy, message.Version = now(), callerId(ctx), nextVersion()
+	s.messages.put(messageId, message)
+	return nil
+}
+
+func (s *MessageService) RestoreMessage(ctx context.Context, messageId string, userId string) error {
+	message, ok := s.messages.get(messageId)
+	if !ok || message.UserId == nil || *message.UserId != userId {
+		return scopingMessage.ErrNotFound
+	}
+
+	message.DeletedAt, message.DeletedBy, message.Version = nil, nil, nextVersion()
+	s.messages.put(messageId, message)
+	return nil
+}
+
+// Memberships are kept on the organization service and mirrored onto the
+// users so corporate managers resolve to their organization
+type OrganizationService struct {
+	orgs    *table[organization.Organization]
+	members *table[organization.Member]
+	users   *UserService
+}
+
+func NewOrganizationService(users *UserService) *OrganizationService {
+	return &OrganizationService{
+		orgs:    newTable[organization.Organization](),
+		members: newTable[organization.Member](),
+		users:   users,
+	}
+}
+
+func (s *OrganizationService) PostOrganization(ctx context.Context, org organization.Organization) (organization.Organization, error) {
+	duplicates := s.orgs.list(1, 1, func(held organization.Organization) bool {
+		return *held.Name == *org.Name
+	})
+	if len(duplicates) > 0 {
+		return organization.Organization{}, organization.ErrDuplicateName
+	}
+
+	org.Id = s.orgs.nextId("organization")
+	org.Version = nextVersion()
+	s.orgs.put(org.Id, org)
+	return org, nil
+}
+
+func (s *OrganizationService) GetOrganization(ctx context.Context, id string) (organization.Organization, error) {
+	org, ok := s.orgs.get(id)
+	if !ok || org.DeletedAt != nil {
+		return organization.Organization{}, organization.ErrNotFound
+	}
+	return org, nil
+}
+
+func (s *OrganizationService) GetAllOrganizations(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]organization.Organization, error) {
+	return s.orgs.list(page, pageSize, func(org organization.Organization) bool {
+		return includeDeleted || org.DeletedAt == nil
+	}), nil
+}
+
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, org organization.Organization) (organization.Organization, error) {
+	current, err := s.GetOrganization(ctx, org.Id)
+	if err != nil {
+		return organization.Organization{}, err
+	}
+	if org.Version != "" && org.Version != current.Version {
+		return organization.Organization{}, organization.ErrVersionMismatch
+	}
+
+	org.Version = nextVersion()
+	s.orgs.put(org.Id, org)
+	return org, nil
+}
+
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, id string) error {
+	org, err := s.GetOrganization(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	org.DeletedAt, org.DeletedBy, org.Version = now(), callerId(ctx), nextVersion()
+	s.orgs.put(id, org)
+	return nil
+}
+
+func (s *OrganizationService) RestoreOrganization(ctx context.Context, id string) error {
+	org, ok := s.orgs.get(id)
+	if !ok {
+		return organization.ErrNotFound
+	}
+
+	org.DeletedAt, org.DeletedBy, org.Version = nil, nil, nextVersion()
+	s.orgs.put(id, org)
+	return nil
+}
+
+func memberKey(orgId string, userId string) string {
+	return orgId + "/" + userId
+}
+
+func (s *OrganizationService) GetMembers(ctx context.Context, orgId string) ([]organization.Member, error) {
+	if _, err := s.GetOrganization(ctx, orgId); err != nil {
+		return nil, err
+	}
+
+	var members []organization.Member
+	for _, key := range s.members.ids() {
+		if member, ok := s.members.get(key); ok && strings.HasPrefix(key, orgId+"/") {
+			members = append(members, member)
+		}
+	}
+
+	return members, nil
+}
+
+func (s *OrganizationService) PutMember(ctx context.Context, orgId string, member organization.Member) (organization.Member, error) {
+	if _, err := s.GetOrganization(ctx, orgId); err != nil {
+		return organization.Member{}, err
+	}
+
+	user, err := s.users.GetUser(ctx, member.UserId)
+	if err != nil {
+		return organization.Member{}, organization.ErrUserNotFound
+	}
+	if user.OrganizationId != nil && *user.OrganizationId != orgId {
+		return organization.Member{}, organization.ErrOtherMembership
+	}
+
+	if member.Role == "" {
+		member.Role = organization.MemberRoleLearner
+	}
+	if held, ok := s.members.get(memberKey(orgId, member.UserId)); ok {
+		member.JoinedAt = held.JoinedAt
+	} else {
+		member.JoinedAt = now()
+	}
+	s.members.put(memberKey(orgId, member.UserId), member)
+
+	user.OrganizationId = &orgId
+	user.OrganizationRole = member.Role
+	user.Corporate = true
+	s.users.users.put(user.ID, user)
+
+	return member, nil
+}
+
+func (s *OrganizationService) RemoveMember(ctx context.Context, orgId string, userId string) error {
+	if _, ok := s.members.get(memberKey(orgId, userId)); !ok {
+		return organization.ErrMemberNotFound
+	}
+	s.members.remove(memberKey(orgId, userId))
+
+	if user, ok := s.users.users.get(userId); ok {
+		user.OrganizationId = nil
+		user.OrganizationRole = ""
+		user.Corporate = false
+		s.users.users.put(userId, user)
+	}
+
+	return nil
+}
+
+// Keys are issued as "sk_<id>.secret" and authenticate by that value alone
+type APIKeyService struct {
+	keys *table[apikeys.APIKey]
+}
+
+func NewAPIKeyService() *APIKeyService {
+	return &APIKeyService{keys: newTable[apikeys.APIKey]()}
+}
+
+func (s *APIKeyService) issue(key apikeys.APIKey) apikeys.IssuedAPIKey {
+	return apikeys.IssuedAPIKey{APIKey: key, Key: "sk_" + key.Id + ".secret"}
+}
+
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, key apikeys.APIKey) (apikeys.IssuedAPIKey, error) {
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+
+	key.Id = s.keys.nextId("key")
+	key.CreatedBy = callerId(ctx)
+	key.CreatedAt = now()
+	s.keys.put(key.Id, key)
+
+	return s.issue(key), nil
+}
+
+func (s *APIKeyService) GetAPIKey(ctx context.Context, id string) (apikeys.APIKey, error) {
+	key, ok := s.keys.get(id)
+	if !ok {
+		return apikeys.APIKey{}, apikeys.ErrNotFound
+	}
+	return key, nil
+}
+
+func (s *APIKeyService) GetAllAPIKeys(ctx context.Context, page int, pageSize int) ([]apikeys.APIKey, error) {
+	return s.keys.list(page, pageSize, all[apikeys.APIKey]), nil
+}
+
+func (s *APIKeyService) RotateAPIKey(ctx context.Context, id string) (apikeys.IssuedAPIKey, error) {
+	key, err := s.GetAPIKey(ctx, id)
+	if err != nil {
+		return apikeys.IssuedAPIKey{}, err
+	}
+	if key.RevokedAt != nil {
+		return apikeys.IssuedAPIKey{}, apikeys.ErrRevoked
+	}
+
+	key.RotatedAt = now()
+	s.keys.put(id, key)
+
+	return s.issue(key), nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	key, err := s.GetAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	key.RevokedAt = now()
+	s.keys.put(id, key)
+	return nil
+}
+
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey string) (apikeys.APIKey, error) {
+	for _, id := range s.keys.ids() {
+		key, _ := s.keys.get(id)
+		if s.issue(key).Key == rawKey && key.RevokedAt == nil {
+			return key, nil
+		}
+	}
+	return apikeys.APIKey{}, apikeys.ErrInvalidKey
+}
+
+type AuditService struct {
+	records *table[audit.Record]
+}
+
+func NewAuditService() *AuditService {
+	return &AuditService{records: newTable[audit.Record]()}
+}
+
+func (s *AuditService) RecordAction(ctx context.Context, record audit.Record) (audit.Record, error) {
+	record.Id = s.records.nextId("audit-record")
+	record.CreatedAt = time.Now().UTC()
+	s.records.put(record.Id, record)
+	return record, nil
+}
+
+func (s *AuditService) GetRecords(ctx context.Context, page int, pageSize int) ([]audit.Record, error) {
+	return s.records.list(page, pageSize, all[audit.Record]), nil
+}
+
+// Erases and exports the records held by the other in-memory services
+type PrivacyService struct {
+	users    *UserService
+	messages *MessageService
+}
+
+func NewPrivacyService(users *UserService, messages *MessageService) *PrivacyService {
+	return &PrivacyService{users: users, messages: messages}
+}
+
+func (s *PrivacyService) EraseUser(ctx context.Context, userId string) (privacy.ErasureRecord, error) {
+	if _, ok := s.users.users.get(userId); !ok {
+		return privacy.ErasureRecord{}, privacy.ErrNotFound
+	}
+
+	deleted := 1
+	s.users.users.remove(userId)
+
+	for _, id := range s.messages.messages.ids() {
+		if message, ok := s.messages.messages.get(id); ok && message.UserId != nil && *message.UserId == userId {
+			s.messages.messages.remove(id)
+			deleted++
+		}
+	}
+
+	return privacy.ErasureRecord{
+		Id:               "erasure-" + userId,
+		UserId:           userId,
+		RequestedBy:      callerId(ctx),
+		DocumentsDeleted: deleted,
+		ErasedAt:         time.Now().UTC(),
+	}, nil
+}
+
+func (s *PrivacyService) ExportUser(ctx context.Context, userId string) (privacy.UserExport, error) {
+	user, ok := s.users.users.get(userId)
+	if !ok {
+		return privacy.UserExport{}, privacy.ErrNotFound
+	}
+
+	export := privacy.UserExport{
+		UserId:     userId,
+		ExportedAt: time.Now().UTC(),
+		Documents: []privacy.Document{{
+			Path: "users/" + userId,
+			Data: map[string]interface{}{"id": user.ID, "email_address": user.EmailAddress},
+		}},
+	}
+
+	return export, nil
+}
+
+// Backs the real idempotency service, so the contract covers its replays
+type IdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+}
+
+func NewIdempotencyRepository() *IdempotencyRepository {
+	return &IdempotencyRepository{records: map[string]idempotency.Record{}}
+}
+
+func (r *IdempotencyRepository) ClaimKey(ctx context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.records[record.Id]; ok && existing.ExpiresAt.After(time.Now()) {
+		return existing, false, nil
+	}
+
+	r.records[record.Id] = record
+	return record, true, nil
+}
+
+func (r *IdempotencyRepository) ExtendClaim(ctx context.Context, record idempotency.Record) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.records[record.Id]
+	if !ok || existing.State != idempotency.StatePending || existing.Fingerprint != record.Fingerprint {
+		return false, nil
+	}
+
+	existing.ExpiresAt = record.ExpiresAt
+	r.records[record.Id] = existing
+	return true, nil
+}
+
+func (r *IdempotencyRepository) SaveResponse(ctx context.Context, record idempotency.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records[record.Id] = record
+	return nil
+}
+
+func (r *IdempotencyRepository) ReleaseKey(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, id)
+	return nil
+}
+
+// Backs the real ticket service
+type TicketRepository struct {
+	mu      sync.Mutex
+	tickets map[string]ticket.Ticket
+}
+
+func NewTicketRepository() *TicketRepository {
+	return &TicketRepository{tickets: map[string]ticket.Ticket{}}
+}
+
+func (r *TicketRepository) PostTicket(ctx context.Context, t ticket.Ticket) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tickets[t.Id] = t
+	return nil
+}
+
+func (r *TicketRepository) TakeTicket(ctx context.Context, id string) (ticket.Ticket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tickets[id]
+	if !ok {
+		return ticket.Ticket{}, ticket.ErrInvalidTicket
+	}
+
+	delete(r.tickets, id)
+	return t, nil
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	apikeys "github.com/zzenonn/scoping-ai/internal/apikey"
+)
+
+// API keys are looked up before the tenant of a request is known, so unlike
+// every other collection they are kept at the root and carry their tenant id.
+type APIKeyRepository struct {
+	client         *firestore.Client
+	CollectionName string
+}
+
+func NewAPIKeyRepository(client *firestore.Client, collectionName string) APIKeyRepository {
+	return APIKeyRepository{
+		client:         client,
+		CollectionName: collectionName,
+	}
+}
+
+func (repo *APIKeyRepository) PostAPIKey(ctx context.Context, key apikeys.APIKey) (apikeys.APIKey, error) {
+	keyMap := map[string]interface{}{
+		"id":          key.Id,
+		"name":        *key.Name,
+		"tenant_id":   key.TenantId,
+		"scopes":      key.Scopes,
+		"secret_hash": key.SecretHash,
+		"created_at":  firestore.ServerTimestamp,
+	}
+
+	if key.Scopes == nil {
+		keyMap["scopes"] = []string{}
+	}
+
+	if key.CreatedBy != nil {
+		keyMap["created_by"] = *key.CreatedBy
+	}
+
+	ref := repo.client.Collection(repo.CollectionName).Doc(key.Id)
+
+	if _, err := ref.Create(ctx, keyMap); err != nil {
+		return apikeys.APIKey{}, translateError(err)
+	}
+
+	return repo.GetAPIKey(ctx, key.Id)
+}
+
+func (repo *APIKeyRepository) GetAPIKey(ctx context.Context, id string) (apikeys.APIKey, error) {
+	doc, err := repo.client.Collection(repo.CollectionName).Doc(id).Get(ctx)
+	if isNotFound(err) {
+		return apikeys.APIKey{}, apikeys.ErrNotFound
+	}
+	if err != nil {
+		return apikeys.APIKey{}, translateError(err)
+	}
+
+	var key apikeys.APIKey
+	if err := doc.DataTo(&key); err != nil {
+		return apikeys.APIKey{}, translateError(err)
+	}
+
+	return key, nil
+}
+
+func (repo *APIKeyRepository) GetAPIKeysByTenant(ctx context.Context, tenantId string, page int, pageSize int) ([]apikeys.APIKey, error) {
+	query := repo.client.Collection(repo.CollectionName).Where("tenant_id", "==", tenantId).OrderBy("created_at", firestore.Asc)
+
+	// Revoked keys are listed too, so they stay visible for auditing
+	docs, err := pagedDocuments(ctx, query, page, pageSize, true)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var keys []apikeys.APIKey
+
+	for _, doc := range docs {
+		var key apikeys.APIKey
+		if err := doc.DataTo(&key); err != nil {
+			return nil, translateError(err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func (repo *APIKeyRepository) RotateAPIKey(ctx context.Context, id string, secretHash string) (apikeys.APIKey, error) {
+	_, err := repo.client.Collection(repo.CollectionName).Doc(id).Update(ctx, []firestore.Update{
+		{Path: "secret_hash", Value: secretHash},
+		{Path: "rotated_at", Value: firestore.ServerTimestamp},
+	})
+	if isNotFound(err) {
+		return apikeys.APIKey{}, apikeys.ErrNotFound
+	}
+	if err != nil {
+		return apikeys.APIKey{}, translateError(err)
+	}
+
+	return repo.GetAPIKey(ctx, id)
+}
+
+func (repo *APIKeyRepository) RevokeAPIKey(ctx context.Context, id string) error {
+	_, err := repo.client.Collection(repo.CollectionName).Doc(id).Update(ctx, []firestore.Update{
+		{Path: "revoked_at", Value: firestore.ServerTimestamp},
+	})
+	if isNotFound(err) {
+		return apikeys.ErrNotFound
+	}
+	return translateError(err)
+}
+
+func (repo *APIKeyRepository) TouchAPIKey(ctx context.Context, id string) error {
+	_, err := repo.client.Collection(repo.CollectionName).Doc(id).Update(ctx, []firestore.Update{
+		{Path: "last_used_at", Value: firestore.ServerTimestamp},
+	})
+	return translateError(err)
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/zzenonn/scoping-ai/internal/audit"
+)
+
+type AuditRepository struct {
+	client         *firestore.Client
+	CollectionName string
+}
+
+func NewAuditRepository(client *firestore.Client, collectionName string) AuditRepository {
+	return AuditRepository{
+		client:         client,
+		CollectionName: collectionName,
+	}
+}
+
+func (repo *AuditRepository) PostRecord(ctx context.Context, record audit.Record) (audit.Record, error) {
+	_, err := tenantCollection(ctx, repo.client, repo.CollectionName).Doc(record.Id).Create(ctx, record)
+	if err != nil {
+		return audit.Record{}, translateError(err)
+	}
+
+	return record, nil
+}
+
+// Lists the newest records first
+func (repo *AuditRepository) GetRecords(ctx context.Context, page int, pageSize int) ([]audit.Record, error) {
+	query := tenantCollection(ctx, repo.client, repo.CollectionName).OrderBy("created_at", firestore.Desc)
+
+	docs, err := pagedDocuments(ctx, query, page, pageSize, true)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var records []audit.Record
+
+	for _, doc := range docs {
+		var record audit.Record
+		if err := doc.DataTo(&record); err != nil {
+			return nil, translateError(err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
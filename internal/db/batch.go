@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// Firestore allows at most 500 writes in a single batch
+const maxBatchSize = 500
+
+// Deletes the documents in batches, returning how many were deleted
+func deleteDocuments(ctx context.Context, client *firestore.Client, refs []*firestore.DocumentRef) (int, error) {
+	for start := 0; start < len(refs); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+
+		batch := client.Batch()
+		for _, ref := range refs[start:end] {
+			batch.Delete(ref)
+		}
+
+		if _, err := batch.Commit(ctx); err != nil {
+			return start, err
+		}
+	}
+
+	return len(refs), nil
+}
+
+// Deletes groups of documents that must go together, such as a document and
+// the reservations of its unique values. Groups are never split across
+// batches, so a failed batch leaves each group whole. Only the last writes of
+// a group larger than a batch are deleted together, the group must start with
+// what can go first, such as the descendants of the document. Returns how
+// many groups were deleted.
+func deleteDocumentGroups(ctx context.Context, client *firestore.Client, groups [][]*firestore.DocumentRef) (int, error) {
+	batch := client.Batch()
+	size, deleted, batched := 0, 0, 0
+
+	commit := func() error {
+		if size == 0 {
+			return nil
+		}
+
+		if _, err := batch.Commit(ctx); err != nil {
+			return err
+		}
+
+		deleted += batched
+		batch, size, batched = client.Batch(), 0, 0
+		return nil
+	}
+
+	for _, group := range groups {
+		if size+len(group) > maxBatchSize {
+			if err := commit(); err != nil {
+				return deleted, err
+			}
+		}
+
+		if len(group) > maxBatchSize {
+			leading := len(group) - maxBatchSize
+			if _, err := deleteDocuments(ctx, client, group[:leading]); err != nil {
+				return deleted, err
+			}
+			group = group[leading:]
+		}
+
+		for _, ref := range group {
+			batch.Delete(ref)
+		}
+		size += len(group)
+		batched++
+	}
+
+	if err := commit(); err != nil {
+		return deleted, err
+	}
+
+	return deleted, nil
+}
+
+// Collects a document and everything in its subcollections, descendants first.
+// Documents that only exist as parents of subcollections are included as well,
+// since deleting a document in Firestore leaves its subcollections behind.
+func collectDocumentTree(ctx context.Context, ref *firestore.DocumentRef) ([]*firestore.DocumentRef, error) {
+	var refs []*firestore.DocumentRef
+
+	collections := ref.Collections(ctx)
+	for {
+		collection, err := collections.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		children := collection.DocumentRefs(ctx)
+		for {
+			child, err := children.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			descendants, err := collectDocumentTree(ctx, child)
+			if err != nil {
+				return nil, err
+			}
+
+			refs = append(refs, descendants...)
+		}
+	}
+
+	return append(refs, ref), nil
+}
@@ -2,12 +2,12 @@ package db
 
 import (
 	"context"
-	"errors"
 	"os"
 	"strings"
 
 	"cloud.google.com/go/firestore"
 	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/pkg/common"
 )
 
 func init() {
@@ -28,7 +28,7 @@ func init() {
 
 }
 
-var ErrMissingRequiredFields = errors.New("missing required fields")
+var ErrMissingRequiredFields = common.NewError(common.KindValidation, "missing required fields")
 
 type FirestoreDb struct {
 	Client *firestore.Client
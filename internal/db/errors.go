@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zzenonn/scoping-ai/pkg/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Translates Firestore failures into the error kinds of the application, so
+// a missing document is reported as not found instead of an internal error.
+// Errors that already carry a kind, and errors that don't come from
+// Firestore, are returned as they are. A failed precondition is left internal
+// as well: it also reports missing indexes, and version mismatches are caught
+// by the callers with isVersionMismatch.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var kindErr *common.Error
+	if errors.As(err, &kindErr) {
+		return err
+	}
+
+	if errors.Is(err, errDocumentNotFound) {
+		return common.Wrap(common.KindNotFound, "document not found", err)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return common.Wrap(common.KindUpstream, "the database did not respond in time", err)
+	}
+
+	grpcStatus, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch grpcStatus.Code() {
+	case codes.NotFound:
+		return common.Wrap(common.KindNotFound, "document not found", err)
+	case codes.AlreadyExists:
+		return common.Wrap(common.KindConflict, "document already exists", err)
+	case codes.Aborted:
+		return common.Wrap(common.KindConflict, "the document was changed by another request, try again", err)
+	case codes.InvalidArgument, codes.OutOfRange:
+		return common.Wrap(common.KindValidation, "invalid request to the database", err)
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Canceled:
+		return common.Wrap(common.KindUpstream, "the database is unavailable", err)
+	case codes.PermissionDenied, codes.Unauthenticated:
+		// The credentials of the server were refused, not those of the caller
+		return common.Wrap(common.KindUpstream, "the database refused the request", err)
+	default:
+		return err
+	}
+}
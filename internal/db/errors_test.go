@@ -0,0 +1,56 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/zzenonn/scoping-ai/pkg/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want common.Kind
+	}{
+		{"missing document", status.Error(codes.NotFound, "no document"), common.KindNotFound},
+		{"deleted document", errDocumentNotFound, common.KindNotFound},
+		{"existing document", status.Error(codes.AlreadyExists, "exists"), common.KindConflict},
+		{"aborted transaction", status.Error(codes.Aborted, "contention"), common.KindConflict},
+		{"missing index", status.Error(codes.FailedPrecondition, "the query requires an index"), common.KindInternal},
+		{"unavailable", status.Error(codes.Unavailable, "down"), common.KindUpstream},
+		{"contended batch", contentionError(status.Error(codes.FailedPrecondition, "stale")), common.KindConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := common.KindOf(translateError(tt.err)); got != tt.want {
+				t.Errorf("got kind %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsVersionMismatch(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"version mismatch", errVersionMismatch, true},
+		{"wrapped version mismatch", fmt.Errorf("update: %w", errVersionMismatch), true},
+		{"missing index", status.Error(codes.FailedPrecondition, "the query requires an index"), false},
+		{"other error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isVersionMismatch(tt.err); got != tt.want {
+				t.Errorf("isVersionMismatch(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/zzenonn/scoping-ai/internal/idempotency"
+)
+
+// Expired records are ignored and overwritten when their key is used again.
+// A TTL policy on expires_at removes the rest.
+type IdempotencyRepository struct {
+	client         *firestore.Client
+	CollectionName string
+}
+
+func NewIdempotencyRepository(client *firestore.Client, collectionName string) IdempotencyRepository {
+	return IdempotencyRepository{
+		client:         client,
+		CollectionName: collectionName,
+	}
+}
+
+func (repo *IdempotencyRepository) ClaimKey(ctx context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+	ref := tenantCollection(ctx, repo.client, repo.CollectionName).Doc(record.Id)
+
+	var existing idempotency.Record
+	var claimed bool
+
+	err := repo.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		existing, claimed = idempotency.Record{}, false
+
+		doc, err := tx.Get(ref)
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+
+		if err == nil {
+			if err := doc.DataTo(&existing); err != nil {
+				return err
+			}
+
+			if existing.ExpiresAt.After(time.Now()) {
+				return nil
+			}
+		}
+
+		claimed = true
+		return tx.Set(ref, record)
+	})
+	if err != nil {
+		return idempotency.Record{}, false, translateError(err)
+	}
+
+	if claimed {
+		return record, true, nil
+	}
+
+	return existing, false, nil
+}
+
+func (repo *IdempotencyRepository) ExtendClaim(ctx context.Context, record idempotency.Record) (bool, error) {
+	ref := tenantCollection(ctx, repo.client, repo.CollectionName).Doc(record.Id)
+
+	var held bool
+
+	err := repo.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		held = false
+
+		doc, err := tx.Get(ref)
+		if isNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var existing idempotency.Record
+		if err := doc.DataTo(&existing); err != nil {
+			return err
+		}
+
+		if existing.State != idempotency.StatePending || existing.Fingerprint != record.Fingerprint {
+			return nil
+		}
+
+		held = true
+		return tx.Update(ref, []firestore.Update{{Path: "expires_at", Value: record.ExpiresAt}})
+	})
+	if err != nil {
+		return false, translateError(err)
+	}
+
+	return held, nil
+}
+
+func (repo *IdempotencyRepository) SaveResponse(ctx context.Context, record idempotency.Record) error {
+	_, err := tenantCollection(ctx, repo.client, repo.CollectionName).Doc(record.Id).Set(ctx, record)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (repo *IdempotencyRepository) ReleaseKey(ctx context.Context, id string) error {
+	_, err := tenantCollection(ctx, repo.client, repo.CollectionName).Doc(id).Delete(ctx)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package db
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+	"google.golang.org/api/iterator"
+)
+
+// A document of a bulk import, found by the value of its unique field
+type importDocument struct {
+	key  string
+	id   string // Only used when the document is created
+	data map[string]interface{}
+}
+
+// The write planned for a row of the import
+type importWrite struct {
+	row         int
+	ref         *firestore.DocumentRef
+	reservation *firestore.DocumentRef
+
+	// The document the key belongs to, or nil when it is created
+	current *firestore.DocumentSnapshot
+
+	// A reservation left behind by a document that no longer exists
+	stale *firestore.DocumentSnapshot
+}
+
+// Creating a document also claims its key
+func (write importWrite) size() int {
+	if write.current == nil {
+		return 2
+	}
+	return 1
+}
+
+// Fetches documents in chunks, so a large import doesn't exceed the size of a
+// single request
+func getAllDocuments(ctx context.Context, client *firestore.Client, refs []*firestore.DocumentRef) ([]*firestore.DocumentSnapshot, error) {
+	docs := make([]*firestore.DocumentSnapshot, 0, len(refs))
+
+	for start := 0; start < len(refs); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+
+		chunk, err := client.GetAll(ctx, refs[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, chunk...)
+	}
+
+	return docs, nil
+}
+
+// Upserts documents by the value of a unique field. The reservation of each
+// value leads to the document holding it, which is updated, or restored if it
+// was soft deleted, and the rest are created. Only the given fields are
+// replaced on existing documents. In a dry run the outcome is reported without
+// writing anything.
+//
+// Writes are committed in batches that fail as a whole. Updates only apply
+// to the version that was read and created keys must still be free, so a
+// batch that raced another request fails instead of overwriting it, and all
+// of its rows are reported failed.
+func importDocuments(
+	ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef,
+	field string, fields []string, rows []importDocument, dryRun bool,
+) ([]common.ImportResult, error) {
+	results := make([]common.ImportResult, len(rows))
+
+	var pending []int
+	var reservationRefs []*firestore.DocumentRef
+
+	seen := map[string]bool{}
+	for i, row := range rows {
+		value := normalizeUniqueValue(row.key)
+		if seen[value] {
+			results[i] = common.ImportFailure(row.key, common.ErrRepeatedImportKey)
+			continue
+		}
+		seen[value] = true
+
+		pending = append(pending, i)
+		reservationRefs = append(reservationRefs, reservationRef(client, collection, field, row.key))
+	}
+
+	reservations, err := getAllDocuments(ctx, client, reservationRefs)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var ownerRefs []*firestore.DocumentRef
+	for _, reservation := range reservations {
+		if owner := stringAt(reservation, "owner_id"); reservation.Exists() && owner != nil {
+			ownerRefs = append(ownerRefs, collection.Doc(*owner))
+		}
+	}
+
+	owners, err := getAllDocuments(ctx, client, ownerRefs)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	ownersById := map[string]*firestore.DocumentSnapshot{}
+	for _, owner := range owners {
+		if owner.Exists() {
+			ownersById[owner.Ref.ID] = owner
+		}
+	}
+
+	writes := make([]importWrite, 0, len(pending))
+
+	for n, i := range pending {
+		write := importWrite{row: i, reservation: reservationRefs[n]}
+		reservation := reservations[n]
+
+		var current *firestore.DocumentSnapshot
+		if owner := stringAt(reservation, "owner_id"); reservation.Exists() && owner != nil {
+			current = ownersById[*owner]
+		}
+
+		switch {
+		case current == nil:
+			write.ref = collection.Doc(rows[i].id)
+			if reservation.Exists() {
+				write.stale = reservation
+			}
+			results[i] = common.ImportResult{Id: rows[i].id, Key: rows[i].key, Action: common.ImportCreated}
+		case isDeleted(current):
+			write.ref, write.current = current.Ref, current
+			results[i] = common.ImportResult{Id: current.Ref.ID, Key: rows[i].key, Action: common.ImportRestored}
+		default:
+			write.ref, write.current = current.Ref, current
+			results[i] = common.ImportResult{Id: current.Ref.ID, Key: rows[i].key, Action: common.ImportUpdated}
+		}
+
+		writes = append(writes, write)
+	}
+
+	if dryRun {
+		// Nothing is created, so there is no id to report yet
+		for _, write := range writes {
+			if write.current == nil {
+				results[write.row].Id = ""
+			}
+		}
+
+		return results, nil
+	}
+
+	batch := client.Batch()
+	var batched []importWrite
+	size := 0
+
+	commit := func() {
+		if len(batched) == 0 {
+			return
+		}
+
+		if _, err := batch.Commit(ctx); err != nil {
+			err = translateError(contentionError(err))
+			for _, write := range batched {
+				results[write.row] = common.ImportFailure(rows[write.row].key, err)
+			}
+		}
+
+		batch, batched, size = client.Batch(), nil, 0
+	}
+
+	for _, write := range writes {
+		if size+write.size() > maxBatchSize {
+			commit()
+		}
+
+		row := rows[write.row]
+
+		switch {
+		case write.current == nil:
+			batch.Create(write.ref, markActive(row.data))
+
+			if write.stale != nil {
+				batch.Update(write.reservation, []firestore.Update{
+					{Path: "value", Value: normalizeUniqueValue(row.key)},
+					{Path: "owner_id", Value: write.ref.ID},
+				}, firestore.LastUpdateTime(write.stale.UpdateTime))
+			} else {
+				batch.Create(write.reservation, map[string]interface{}{
+					"value":    normalizeUniqueValue(row.key),
+					"owner_id": write.ref.ID,
+				})
+			}
+		default:
+			updates := replacementUpdates(row.data, fields...)
+			if isDeleted(write.current) {
+				updates = append(updates,
+					firestore.Update{Path: "deleted_at", Value: nil},
+					firestore.Update{Path: "deleted_by", Value: firestore.Delete},
+				)
+			}
+
+			batch.Update(write.ref, updates, firestore.LastUpdateTime(write.current.UpdateTime))
+		}
+
+		batched = append(batched, write)
+		size += write.size()
+	}
+
+	commit()
+
+	return results, nil
+}
+
+// Calls each with the active documents of the query, in its order
+func eachActiveDocument(ctx context.Context, query firestore.Query, each func(doc *firestore.DocumentSnapshot) error) error {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return translateError(err)
+		}
+
+		if isDeleted(doc) {
+			continue
+		}
+
+		if err := each(doc); err != nil {
+			return err
+		}
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
 	"time"
@@ -9,7 +10,6 @@ import (
 	"cloud.google.com/go/firestore"
 	log "github.com/sirupsen/logrus"
 	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
-	"google.golang.org/api/iterator"
 )
 
 func init() {
@@ -113,67 +113,90 @@ func (repo *MessageRepository) PostMessage(ctx context.Context, message scopingM
 	messageMap, err := convertMessageToMap(message)
 
 	if err != nil {
-		return scopingMessage.Message{}, err
+		return scopingMessage.Message{}, translateError(err)
 	}
 
 	messageMap["created_at"] = firestore.ServerTimestamp
+	messageMap["updated_at"] = firestore.ServerTimestamp
 
 	if err != nil {
-		return scopingMessage.Message{}, err
+		return scopingMessage.Message{}, translateError(err)
 	}
 
 	userId := message.UserId
 
-	_, err = repo.client.Collection(repo.UserCollectionName).Doc(*userId).Collection(repo.MessageCollectionName).Doc(message.Id).Set(ctx, messageMap)
+	_, err = tenantCollection(ctx, repo.client, repo.UserCollectionName).Doc(*userId).Collection(repo.MessageCollectionName).Doc(message.Id).Set(ctx, markActive(messageMap))
 	if err != nil {
-		return scopingMessage.Message{}, err
+		return scopingMessage.Message{}, translateError(err)
 	}
 
 	return message, nil
 }
 
+// Writes all messages in a single batch, so either every message is saved or none is
+func (repo *MessageRepository) PostMessages(ctx context.Context, messages []scopingMessage.Message) ([]scopingMessage.Message, error) {
+	if len(messages) > maxBatchSize {
+		return nil, scopingMessage.ErrTooManyAnswers
+	}
+
+	batch := repo.client.Batch()
+
+	for i, message := range messages {
+		messageMap, err := convertMessageToMap(message)
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: message %d", scopingMessage.ErrInvalidAnswer, i)
+		}
+
+		messageMap["created_at"] = firestore.ServerTimestamp
+		messageMap["updated_at"] = firestore.ServerTimestamp
+
+		batch.Create(tenantCollection(ctx, repo.client, repo.UserCollectionName).Doc(*message.UserId).Collection(repo.MessageCollectionName).Doc(message.Id), markActive(messageMap))
+	}
+
+	if _, err := batch.Commit(ctx); err != nil {
+		return nil, translateError(err)
+	}
+
+	return messages, nil
+}
+
 func (repo *MessageRepository) GetMessage(ctx context.Context, messageId string, userId string) (scopingMessage.Message, error) {
 
-	doc, err := repo.client.Collection(repo.UserCollectionName).Doc(userId).Collection(repo.MessageCollectionName).Doc(messageId).Get(ctx)
+	doc, err := getActiveDocument(ctx, tenantCollection(ctx, repo.client, repo.UserCollectionName).Doc(userId).Collection(repo.MessageCollectionName).Doc(messageId))
+	if isNotFound(err) {
+		return scopingMessage.Message{}, scopingMessage.ErrNotFound
+	}
 	if err != nil {
-		return scopingMessage.Message{}, err
+		return scopingMessage.Message{}, translateError(err)
 	}
 
 	var message scopingMessage.Message
 	err = doc.DataTo(&message)
 	if err != nil {
-		return scopingMessage.Message{}, err
+		return scopingMessage.Message{}, translateError(err)
 	}
 
+	message.Version = versionFromTime(doc.UpdateTime)
+
 	return message, nil
 }
 
-func (repo *MessageRepository) GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int) ([]scopingMessage.Message, error) {
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 {
-		pageSize = 10
-	}
+func (repo *MessageRepository) GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int, includeDeleted bool) ([]scopingMessage.Message, error) {
+	query := tenantCollection(ctx, repo.client, repo.UserCollectionName).Doc(userId).Collection(repo.MessageCollectionName).OrderBy("created_at", firestore.Asc)
 
-	offset := (page - 1) * pageSize
+	docs, err := pagedDocuments(ctx, query, page, pageSize, includeDeleted)
+	if err != nil {
+		return nil, translateError(err)
+	}
 
-	iter := repo.client.Collection(repo.UserCollectionName).Doc(userId).Collection(repo.MessageCollectionName).OrderBy("created_at", firestore.Asc).Offset(offset).Limit(pageSize).Documents(ctx)
 	var messages []scopingMessage.Message
 
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
+	for _, doc := range docs {
 		var message scopingMessage.Message
 		err = doc.DataTo(&message)
 		if err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 
 		messages = append(messages, message)
@@ -185,22 +208,164 @@ func (repo *MessageRepository) GetAllUserMessages(ctx context.Context, userId st
 func (repo *MessageRepository) UpdateMessage(ctx context.Context, message scopingMessage.Message) (scopingMessage.Message, error) {
 	messageMap, err := convertMessageToMap(message)
 	if err != nil {
-		return scopingMessage.Message{}, err
+		return scopingMessage.Message{}, translateError(err)
 	}
 
-	_, err = repo.client.Collection(repo.UserCollectionName).Doc(*message.UserId).Collection(repo.MessageCollectionName).Doc(message.Id).Set(ctx, messageMap, firestore.MergeAll)
+	// Watchers find changed messages by when they were updated
+	messageMap["updated_at"] = firestore.ServerTimestamp
+
+	ref := tenantCollection(ctx, repo.client, repo.UserCollectionName).Doc(*message.UserId).Collection(repo.MessageCollectionName).Doc(message.Id)
+
+	version, err := updateDocument(ctx, repo.client, ref, message.Version, replacementUpdates(messageMap, "message_text", "answer", "updated_at"), nil)
+	if isVersionMismatch(err) {
+		return scopingMessage.Message{}, scopingMessage.ErrVersionMismatch
+	}
+	if isNotFound(err) {
+		return scopingMessage.Message{}, scopingMessage.ErrNotFound
+	}
 	if err != nil {
-		return scopingMessage.Message{}, err
+		return scopingMessage.Message{}, translateError(err)
 	}
 
+	message.Version = version
+
 	return message, nil
 }
 
 func (repo *MessageRepository) DeleteMessage(ctx context.Context, messageId string, userId string) error {
-	_, err := repo.client.Collection(repo.UserCollectionName).Doc(userId).Collection(repo.MessageCollectionName).Doc(messageId).Delete(ctx)
+	err := softDelete(ctx, repo.client, tenantCollection(ctx, repo.client, repo.UserCollectionName).Doc(userId).Collection(repo.MessageCollectionName).Doc(messageId))
+	if isNotFound(err) {
+		return scopingMessage.ErrNotFound
+	}
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (repo *MessageRepository) RestoreMessage(ctx context.Context, messageId string, userId string) error {
+	err := restore(ctx, repo.client, tenantCollection(ctx, repo.client, repo.UserCollectionName).Doc(userId).Collection(repo.MessageCollectionName).Doc(messageId))
+	if isNotFound(err) {
+		return scopingMessage.ErrNotFound
+	}
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
 	return nil
 }
+
+// Messages are purged across all users, which requires a collection group
+// index on deleted_at
+func (repo *MessageRepository) PurgeDeletedMessages(ctx context.Context, before time.Time) (int, error) {
+	return purgeDeleted(ctx, repo.client, repo.client.CollectionGroup(repo.MessageCollectionName).Query, before)
+}
+
+// How long a watch runs before it is replaced by one that starts where it
+// left off. Firestore keeps every matching message in the watch, so it would
+// otherwise grow with every message written while the server runs.
+const messageWatchPeriod = 10 * time.Minute
+
+// How long to wait before watching again after the watch failed
+const messageWatchRetry = 5 * time.Second
+
+// Publishes the changes every instance makes to messages, so the clients of
+// a user hear about them whichever instance they are connected to. Runs until
+// ctx is done. Requires a collection group index on updated_at.
+func (repo *MessageRepository) WatchMessages(ctx context.Context, events scopingMessage.EventPublisher) {
+	since := time.Now()
+
+	// Messages seen pending, so the update that replaces them is reported as
+	// their completion
+	pending := map[string]bool{}
+
+	for ctx.Err() == nil {
+		var err error
+
+		since, err = repo.watchMessagesSince(ctx, since, pending, events)
+		if err != nil && ctx.Err() == nil {
+			log.Errorf("Failed to watch messages, retrying in %s. Error: %v", messageWatchRetry, err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(messageWatchRetry):
+			}
+		}
+	}
+}
+
+// Watches for a period and returns the time the changes were published up to
+func (repo *MessageRepository) watchMessagesSince(ctx context.Context, since time.Time, pending map[string]bool, events scopingMessage.EventPublisher) (time.Time, error) {
+	watchCtx, cancel := context.WithTimeout(ctx, messageWatchPeriod)
+	defer cancel()
+
+	snapshots := repo.client.CollectionGroup(repo.MessageCollectionName).Where("updated_at", ">", since).Snapshots(watchCtx)
+	defer snapshots.Stop()
+
+	for {
+		snapshot, err := snapshots.Next()
+		if watchCtx.Err() != nil {
+			return since, nil
+		}
+		if err != nil {
+			return since, err
+		}
+
+		for _, change := range snapshot.Changes {
+			if change.Kind == firestore.DocumentRemoved {
+				continue
+			}
+
+			event, err := messageEvent(change.Doc, pending)
+			if err != nil {
+				log.Errorf("Failed to read changed message %s. Error: %v", change.Doc.Ref.Path, err)
+				continue
+			}
+
+			events.Publish(event)
+		}
+
+		since = snapshot.ReadTime
+	}
+}
+
+// Messages live under users/{userId}/messages, below tenants/{tenantId} for
+// every tenant but the default one
+func messageEvent(doc *firestore.DocumentSnapshot, pending map[string]bool) (scopingMessage.Event, error) {
+	var message scopingMessage.Message
+	if err := doc.DataTo(&message); err != nil {
+		return scopingMessage.Event{}, err
+	}
+
+	userRef := doc.Ref.Parent.Parent
+	message.UserId = &userRef.ID
+	message.Version = versionFromTime(doc.UpdateTime)
+
+	var tenantId string
+	if tenantRef := userRef.Parent.Parent; tenantRef != nil {
+		tenantId = tenantRef.ID
+	}
+
+	eventType := scopingMessage.EventUpdated
+	switch {
+	case doc.CreateTime.Equal(doc.UpdateTime):
+		eventType = scopingMessage.EventCreated
+	case pending[doc.Ref.Path] && message.IsFailed():
+		eventType = scopingMessage.EventFailed
+	case pending[doc.Ref.Path] && !message.IsPending():
+		eventType = scopingMessage.EventCompleted
+	}
+
+	if message.IsPending() {
+		pending[doc.Ref.Path] = true
+	} else {
+		delete(pending, doc.Ref.Path)
+	}
+
+	return scopingMessage.Event{
+		Type:     eventType,
+		Message:  message,
+		TenantId: tenantId,
+	}, nil
+}
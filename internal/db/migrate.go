@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Claims the reservations of documents written before unique values were
+// reserved, in every tenant. Without them, creates and imports don't see the
+// existing values and write duplicates. Documents whose value is already held
+// by another document are left alone and counted as conflicts, so they can be
+// renamed by hand. Running it again only claims what is still missing.
+func BackfillReservations(ctx context.Context, client *firestore.Client, collectionName string, field string) (int, int, error) {
+	claimed, conflicts := 0, 0
+
+	iter := client.CollectionGroup(collectionName).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return claimed, conflicts, translateError(err)
+		}
+
+		value := stringAt(doc, field)
+		if value == nil {
+			continue
+		}
+
+		ref := reservationRef(client, doc.Ref.Parent, field, *value)
+
+		_, err = ref.Create(ctx, map[string]interface{}{
+			"value":    normalizeUniqueValue(*value),
+			"owner_id": doc.Ref.ID,
+		})
+		if err == nil {
+			claimed++
+			continue
+		}
+		if status.Code(err) != codes.AlreadyExists {
+			return claimed, conflicts, translateError(err)
+		}
+
+		reservation, err := ref.Get(ctx)
+		if err != nil {
+			return claimed, conflicts, translateError(err)
+		}
+
+		owner := stringAt(reservation, "owner_id")
+		if owner != nil && *owner == doc.Ref.ID {
+			continue
+		}
+
+		// A reservation left behind by a document that no longer exists is taken over
+		if owner != nil {
+			_, err := doc.Ref.Parent.Doc(*owner).Get(ctx)
+			if err != nil && !isNotFound(err) {
+				return claimed, conflicts, translateError(err)
+			}
+			if err == nil {
+				log.Warnf("%s of %s is already reserved by another document", field, doc.Ref.Path)
+				conflicts++
+				continue
+			}
+		}
+
+		_, err = ref.Update(ctx, []firestore.Update{{Path: "owner_id", Value: doc.Ref.ID}}, firestore.LastUpdateTime(reservation.UpdateTime))
+		if err != nil {
+			return claimed, conflicts, translateError(err)
+		}
+		claimed++
+	}
+
+	return claimed, conflicts, nil
+}
+
+// Gives documents written before deleted_at was always set a null one, so the
+// queries for active documents find them. Documents changed while it runs are
+// skipped, running it again picks them up.
+func BackfillDeletedAt(ctx context.Context, client *firestore.Client, collectionName string) (int, error) {
+	updated := 0
+
+	iter := client.CollectionGroup(collectionName).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return updated, nil
+		}
+		if err != nil {
+			return updated, translateError(err)
+		}
+
+		if _, err := doc.DataAt("deleted_at"); err == nil {
+			continue
+		}
+
+		_, err = doc.Ref.Update(ctx, []firestore.Update{{Path: "deleted_at", Value: nil}}, firestore.LastUpdateTime(doc.UpdateTime))
+		if status.Code(err) == codes.FailedPrecondition {
+			log.Warnf("%s changed during the migration, run it again", doc.Ref.Path)
+			continue
+		}
+		if err != nil {
+			return updated, translateError(err)
+		}
+
+		updated++
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	"github.com/zzenonn/scoping-ai/pkg/common"
 )
 
 func init() {
@@ -94,7 +95,7 @@ func (repo *OpenAiRepository) PostPrompt(ctx context.Context, aiContext string,
 	resp, err := client.Do(req)
 	if err != nil {
 		log.Errorf("Failed to make the request: %v", err)
-		return scopingMessage.ChatCompletion{}, err
+		return scopingMessage.ChatCompletion{}, common.Wrap(common.KindUpstream, "the language model request failed", err)
 	}
 	defer resp.Body.Close()
 
@@ -103,7 +104,7 @@ func (repo *OpenAiRepository) PostPrompt(ctx context.Context, aiContext string,
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		log.Errorf("Failed to read response body: %v", err)
-		return scopingMessage.ChatCompletion{}, err
+		return scopingMessage.ChatCompletion{}, common.Wrap(common.KindUpstream, "the language model request failed", err)
 	}
 
 	// Logging the response body
@@ -114,7 +115,7 @@ func (repo *OpenAiRepository) PostPrompt(ctx context.Context, aiContext string,
 	err = json.Unmarshal(bodyBytes, &chatCompletion)
 	if err != nil {
 		log.Errorf("Failed to unmarshal response body: %v", err)
-		return scopingMessage.ChatCompletion{}, err
+		return scopingMessage.ChatCompletion{}, common.Wrap(common.KindUpstream, "the language model request failed", err)
 	}
 
 	return chatCompletion, nil
@@ -0,0 +1,371 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	log "github.com/sirupsen/logrus"
+	organization "github.com/zzenonn/scoping-ai/internal/organization"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+// Members are kept in a subcollection of their organization. Each member user
+// also carries the organization id, so users can be filtered by organization.
+type OrganizationRepository struct {
+	client               *firestore.Client
+	CollectionName       string
+	MemberCollectionName string
+	UserCollectionName   string
+}
+
+func NewOrganizationRepository(client *firestore.Client, collectionName string, memberCollectionName string, userCollectionName string) OrganizationRepository {
+	return OrganizationRepository{
+		client:               client,
+		CollectionName:       collectionName,
+		MemberCollectionName: memberCollectionName,
+		UserCollectionName:   userCollectionName,
+	}
+}
+
+// Returns the collection of the tenant in the context
+func (repo *OrganizationRepository) collection(ctx context.Context) *firestore.CollectionRef {
+	return tenantCollection(ctx, repo.client, repo.CollectionName)
+}
+
+func convertOrganizationToMap(org organization.Organization) map[string]interface{} {
+	orgMap := map[string]interface{}{
+		"id":   org.Id,
+		"name": *org.Name,
+	}
+
+	settingsMap := map[string]interface{}{}
+	if org.Settings != nil {
+		if org.Settings.AllowedTechnologies != nil {
+			settingsMap["allowed_technologies"] = org.Settings.AllowedTechnologies
+		}
+		if org.Settings.DefaultQuestionSetId != nil {
+			settingsMap["default_question_set_id"] = *org.Settings.DefaultQuestionSetId
+		}
+	}
+	orgMap["settings"] = settingsMap
+
+	return orgMap
+}
+
+func (repo *OrganizationRepository) PostOrganization(ctx context.Context, org organization.Organization) (organization.Organization, error) {
+	orgMap := convertOrganizationToMap(org)
+
+	name := newUniqueField(repo.client, repo.collection(ctx), "name", nil, org.Name)
+
+	err := createDocument(ctx, repo.client, repo.collection(ctx).Doc(org.Id), orgMap, name)
+	if errors.Is(err, errValueReserved) {
+		return organization.Organization{}, organization.ErrDuplicateName
+	}
+	if err != nil {
+		return organization.Organization{}, translateError(err)
+	}
+
+	return org, nil
+}
+
+func (repo *OrganizationRepository) GetOrganization(ctx context.Context, id string) (organization.Organization, error) {
+	doc, err := getActiveDocument(ctx, repo.collection(ctx).Doc(id))
+	if isNotFound(err) {
+		return organization.Organization{}, organization.ErrNotFound
+	}
+	if err != nil {
+		return organization.Organization{}, translateError(err)
+	}
+
+	var org organization.Organization
+	if err := doc.DataTo(&org); err != nil {
+		return organization.Organization{}, translateError(err)
+	}
+
+	org.Id = doc.Ref.ID
+	org.Version = versionFromTime(doc.UpdateTime)
+
+	return org, nil
+}
+
+func (repo *OrganizationRepository) GetAllOrganizations(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]organization.Organization, error) {
+	query := repo.collection(ctx).OrderBy("name", firestore.Asc)
+
+	docs, err := pagedDocuments(ctx, query, page, pageSize, includeDeleted)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var orgs []organization.Organization
+
+	for _, doc := range docs {
+		var org organization.Organization
+		if err := doc.DataTo(&org); err != nil {
+			return nil, translateError(err)
+		}
+
+		org.Id = doc.Ref.ID
+		org.Version = versionFromTime(doc.UpdateTime)
+
+		orgs = append(orgs, org)
+	}
+
+	return orgs, nil
+}
+
+func (repo *OrganizationRepository) UpdateOrganization(ctx context.Context, org organization.Organization) (organization.Organization, error) {
+	orgMap := convertOrganizationToMap(org)
+
+	version, err := updateDocument(ctx, repo.client, repo.collection(ctx).Doc(org.Id), org.Version, updatesFromMap(orgMap),
+		func(current *firestore.DocumentSnapshot) []*uniqueField {
+			return []*uniqueField{newUniqueField(repo.client, repo.collection(ctx), "name", stringAt(current, "name"), org.Name)}
+		})
+	if errors.Is(err, errValueReserved) {
+		return organization.Organization{}, organization.ErrDuplicateName
+	}
+	if isVersionMismatch(err) {
+		return organization.Organization{}, organization.ErrVersionMismatch
+	}
+	if isNotFound(err) {
+		return organization.Organization{}, organization.ErrNotFound
+	}
+	if err != nil {
+		return organization.Organization{}, translateError(err)
+	}
+
+	org.Version = version
+
+	return org, nil
+}
+
+func (repo *OrganizationRepository) DeleteOrganization(ctx context.Context, id string) error {
+	err := softDelete(ctx, repo.client, repo.collection(ctx).Doc(id))
+	if isNotFound(err) {
+		return organization.ErrNotFound
+	}
+	return translateError(err)
+}
+
+func (repo *OrganizationRepository) RestoreOrganization(ctx context.Context, id string) error {
+	err := restore(ctx, repo.client, repo.collection(ctx).Doc(id))
+	if isNotFound(err) {
+		return organization.ErrNotFound
+	}
+	return translateError(err)
+}
+
+// Purging an organization removes its memberships and unlinks its users
+func (repo *OrganizationRepository) PurgeDeletedOrganizations(ctx context.Context, before time.Time) (int, error) {
+	docs, err := repo.client.CollectionGroup(repo.CollectionName).Where("deleted_at", "<=", before).Documents(ctx).GetAll()
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	reservations, err := heldReservations(ctx, repo.client, docs, "name")
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	for i, doc := range docs {
+		users, err := siblingCollection(repo.client, doc.Ref, repo.UserCollectionName).Where("organization_id", "==", doc.Ref.ID).Documents(ctx).GetAll()
+		if err != nil {
+			return i, translateError(err)
+		}
+
+		for _, user := range users {
+			if _, err := user.Ref.Update(ctx, []firestore.Update{
+				{Path: "organization_id", Value: firestore.Delete},
+				{Path: "organization_role", Value: firestore.Delete},
+			}); err != nil {
+				return i, translateError(err)
+			}
+		}
+
+		refs, err := collectDocumentTree(ctx, doc.Ref)
+		if err != nil {
+			return i, translateError(err)
+		}
+
+		// The organization is collected last, so it goes in the same batch
+		// as its reservation
+		if reservation, ok := reservations[doc.Ref.Path]; ok {
+			refs = append(refs, reservation)
+		}
+
+		if _, err := deleteDocumentGroups(ctx, repo.client, [][]*firestore.DocumentRef{refs}); err != nil {
+			return i, translateError(err)
+		}
+	}
+
+	return len(docs), nil
+}
+
+func (repo *OrganizationRepository) GetMembers(ctx context.Context, orgId string) ([]organization.Member, error) {
+	orgRef := repo.collection(ctx).Doc(orgId)
+
+	if _, err := getActiveDocument(ctx, orgRef); isNotFound(err) {
+		return nil, organization.ErrNotFound
+	} else if err != nil {
+		return nil, translateError(err)
+	}
+
+	docs, err := orgRef.Collection(repo.MemberCollectionName).OrderBy("user_id", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var members []organization.Member
+
+	for _, doc := range docs {
+		var member organization.Member
+		if err := doc.DataTo(&member); err != nil {
+			return nil, translateError(err)
+		}
+
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// Adds the membership and links the user in one transaction, so a user never
+// points at an organization it is not a member of
+func (repo *OrganizationRepository) PutMember(ctx context.Context, orgId string, member organization.Member) (organization.Member, error) {
+	orgRef := repo.collection(ctx).Doc(orgId)
+	memberRef := orgRef.Collection(repo.MemberCollectionName).Doc(member.UserId)
+	userRef := tenantCollection(ctx, repo.client, repo.UserCollectionName).Doc(member.UserId)
+
+	err := repo.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		org, err := tx.Get(orgRef)
+		if isNotFound(err) || (err == nil && isDeleted(org)) {
+			return organization.ErrNotFound
+		}
+		if err != nil {
+			return translateError(err)
+		}
+
+		user, err := tx.Get(userRef)
+		if isNotFound(err) || (err == nil && isDeleted(user)) {
+			return organization.ErrUserNotFound
+		}
+		if err != nil {
+			return translateError(err)
+		}
+
+		if current := stringAt(user, "organization_id"); current != nil && *current != orgId {
+			return organization.ErrOtherMembership
+		}
+
+		existing, err := tx.Get(memberRef)
+		if err != nil && !isNotFound(err) {
+			return translateError(err)
+		}
+
+		memberMap := map[string]interface{}{
+			"user_id": member.UserId,
+			"role":    member.Role,
+		}
+		if err != nil || !existing.Exists() {
+			memberMap["joined_at"] = firestore.ServerTimestamp
+		}
+
+		if err := tx.Set(memberRef, memberMap, firestore.MergeAll); err != nil {
+			return translateError(err)
+		}
+
+		return tx.Update(userRef, []firestore.Update{
+			{Path: "organization_id", Value: orgId},
+			{Path: "organization_role", Value: member.Role},
+			{Path: "corporate", Value: true},
+		})
+	})
+	if err != nil {
+		return organization.Member{}, translateError(err)
+	}
+
+	doc, err := memberRef.Get(ctx)
+	if err != nil {
+		return organization.Member{}, translateError(err)
+	}
+
+	var putMember organization.Member
+	if err := doc.DataTo(&putMember); err != nil {
+		return organization.Member{}, translateError(err)
+	}
+
+	return putMember, nil
+}
+
+func (repo *OrganizationRepository) RemoveMember(ctx context.Context, orgId string, userId string) error {
+	memberRef := repo.collection(ctx).Doc(orgId).Collection(repo.MemberCollectionName).Doc(userId)
+	userRef := tenantCollection(ctx, repo.client, repo.UserCollectionName).Doc(userId)
+
+	return repo.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(memberRef); isNotFound(err) {
+			return organization.ErrMemberNotFound
+		} else if err != nil {
+			return translateError(err)
+		}
+
+		user, err := tx.Get(userRef)
+		if err != nil && !isNotFound(err) {
+			return translateError(err)
+		}
+
+		// Erased users leave no user document behind to unlink
+		userExists := err == nil && user.Exists()
+
+		if err := tx.Delete(memberRef); err != nil {
+			return translateError(err)
+		}
+
+		if !userExists {
+			return nil
+		}
+
+		return tx.Update(userRef, []firestore.Update{
+			{Path: "organization_id", Value: firestore.Delete},
+			{Path: "organization_role", Value: firestore.Delete},
+			{Path: "corporate", Value: false},
+		})
+	})
+}
+
+// Returns the active organization the user is linked to
+func (repo *OrganizationRepository) GetUserOrganization(ctx context.Context, userId string) (organization.Organization, error) {
+	user, err := tenantCollection(ctx, repo.client, repo.UserCollectionName).Doc(userId).Get(ctx)
+	if isNotFound(err) {
+		return organization.Organization{}, organization.ErrNotFound
+	}
+	if err != nil {
+		return organization.Organization{}, translateError(err)
+	}
+
+	orgId := stringAt(user, "organization_id")
+	if orgId == nil {
+		return organization.Organization{}, organization.ErrNotFound
+	}
+
+	return repo.GetOrganization(ctx, *orgId)
+}
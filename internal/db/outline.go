@@ -2,13 +2,15 @@ package db
 
 import (
 	"context"
+	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	log "github.com/sirupsen/logrus"
 	outline "github.com/zzenonn/scoping-ai/internal/outline"
-	"google.golang.org/api/iterator"
+	"github.com/zzenonn/scoping-ai/pkg/common"
 )
 
 func init() {
@@ -34,13 +36,26 @@ type CourseOutlineRepository struct {
 	CollectionName string
 }
 
+// Fields of a course outline replaced by an update
+var outlineFields = []string{"technology_name", "course_code", "course_name", "outline"}
+
 func convertOutlineToMap(courseOutline outline.CourseOutline) map[string]interface{} {
-	return map[string]interface{}{
-		"technology_name": courseOutline.TechnologyName,
-		"course_code":     courseOutline.CourseCode,
-		"course_name":     courseOutline.CourseName,
-		"outline":         courseOutline.Outline,
+	cOutlineMap := map[string]interface{}{}
+
+	if courseOutline.TechnologyName != nil {
+		cOutlineMap["technology_name"] = *courseOutline.TechnologyName
+	}
+	if courseOutline.CourseCode != nil {
+		cOutlineMap["course_code"] = *courseOutline.CourseCode
+	}
+	if courseOutline.CourseName != nil {
+		cOutlineMap["course_name"] = *courseOutline.CourseName
+	}
+	if courseOutline.Outline != nil {
+		cOutlineMap["outline"] = *courseOutline.Outline
 	}
+
+	return cOutlineMap
 }
 
 func NewCourseOutlineRepository(client *firestore.Client, collectionName string) CourseOutlineRepository {
@@ -50,21 +65,34 @@ func NewCourseOutlineRepository(client *firestore.Client, collectionName string)
 	}
 }
 
+// Returns the collection of the tenant in the context
+func (repo *CourseOutlineRepository) collection(ctx context.Context) *firestore.CollectionRef {
+	return tenantCollection(ctx, repo.client, repo.CollectionName)
+}
+
 func (repo *CourseOutlineRepository) PostCourseOutline(ctx context.Context, cOutline outline.CourseOutline) (outline.CourseOutline, error) {
 	cOutlineMap := convertOutlineToMap(cOutline)
 
-	_, err := repo.client.Collection(repo.CollectionName).Doc(cOutline.Id).Set(ctx, cOutlineMap)
+	courseCode := newUniqueField(repo.client, repo.collection(ctx), "course_code", nil, cOutline.CourseCode)
+
+	err := createDocument(ctx, repo.client, repo.collection(ctx).Doc(cOutline.Id), cOutlineMap, courseCode)
+	if errors.Is(err, errValueReserved) {
+		return outline.CourseOutline{}, outline.ErrDuplicateCourseCode
+	}
 	if err != nil {
-		return outline.CourseOutline{}, err
+		return outline.CourseOutline{}, translateError(err)
 	}
 
 	return cOutline, nil
 }
 
 func (repo *CourseOutlineRepository) GetCourseOutline(ctx context.Context, docID string) (outline.CourseOutline, error) {
-	doc, err := repo.client.Collection(repo.CollectionName).Doc(docID).Get(ctx)
+	doc, err := getActiveDocument(ctx, repo.collection(ctx).Doc(docID))
+	if isNotFound(err) {
+		return outline.CourseOutline{}, outline.ErrNotFound
+	}
 	if err != nil {
-		return outline.CourseOutline{}, err
+		return outline.CourseOutline{}, translateError(err)
 	}
 
 	var cOutline outline.CourseOutline
@@ -75,47 +103,32 @@ func (repo *CourseOutlineRepository) GetCourseOutline(ctx context.Context, docID
 	doc.DataTo(&cOutline)
 
 	cOutline.Id = docID
+	cOutline.Version = versionFromTime(doc.UpdateTime)
 
 	return cOutline, nil
 }
 
 func (repo *CourseOutlineRepository) GetCourseOutlinesByFilter(
 	ctx context.Context, page int, pageSize int,
-	filterName string, filterValue string,
+	filterName string, filterValue string, includeDeleted bool,
 ) ([]outline.CourseOutline, error) {
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 {
-		pageSize = 10
-	}
-
-	offset := (page - 1) * pageSize
-
 	// Query broken down for readability
-	query := repo.client.Collection(repo.CollectionName)
+	query := repo.collection(ctx)
 	filteredQuery := query.Where(filterName, "==", filterValue)
 	orderedQuery := filteredQuery.OrderBy(filterName, firestore.Asc)
-	paginatedQuery := orderedQuery.Offset(offset).Limit(pageSize)
 
-	iter := paginatedQuery.Documents(ctx)
+	docs, err := pagedDocuments(ctx, orderedQuery, page, pageSize, includeDeleted)
+	if err != nil {
+		return nil, translateError(err)
+	}
 
 	var cOutlines []outline.CourseOutline
 
-	for {
-		doc, err := iter.Next()
-
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
+	for _, doc := range docs {
 		var outline outline.CourseOutline
 		err = doc.DataTo(&outline)
 		if err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 
 		cOutlines = append(cOutlines, outline)
@@ -124,35 +137,66 @@ func (repo *CourseOutlineRepository) GetCourseOutlinesByFilter(
 	return cOutlines, nil
 }
 
-func (repo *CourseOutlineRepository) GetAllCourseOutlines(ctx context.Context, page int, pageSize int) ([]outline.CourseOutline, error) {
-	if page < 1 {
-		page = 1
+func (repo *CourseOutlineRepository) GetAllCourseOutlines(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]outline.CourseOutline, error) {
+	query := repo.collection(ctx).OrderBy("course_code", firestore.Asc)
+
+	docs, err := pagedDocuments(ctx, query, page, pageSize, includeDeleted)
+	if err != nil {
+		return nil, translateError(err)
 	}
-	if pageSize < 1 {
-		pageSize = 10
+
+	var cOutlines []outline.CourseOutline
+
+	for _, doc := range docs {
+		var outline outline.CourseOutline
+		err = doc.DataTo(&outline)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		cOutlines = append(cOutlines, outline)
 	}
 
-	offset := (page - 1) * pageSize
+	return cOutlines, nil
+}
+
+// Firestore compares a field with at most 30 values in a single "in" filter
+const maxInValues = 30
 
-	iter := repo.client.Collection(repo.CollectionName).OrderBy("course_code", firestore.Asc).Offset(offset).Limit(pageSize).Documents(ctx)
+// Fetches the active course outlines of each technology, querying the
+// technologies in chunks
+func (repo *CourseOutlineRepository) GetCourseOutlinesByTechnologyNames(ctx context.Context, technologyNames []string) ([]outline.CourseOutline, error) {
 	var cOutlines []outline.CourseOutline
 
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
+	for start := 0; start < len(technologyNames); start += maxInValues {
+		end := start + maxInValues
+		if end > len(technologyNames) {
+			end = len(technologyNames)
 		}
 
-		var outline outline.CourseOutline
-		err = doc.DataTo(&outline)
+		query := repo.collection(ctx).Where("technology_name", "in", technologyNames[start:end])
+
+		docs, err := query.Documents(ctx).GetAll()
 		if err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 
-		cOutlines = append(cOutlines, outline)
+		for _, doc := range docs {
+			if isDeleted(doc) {
+				continue
+			}
+
+			var cOutline outline.CourseOutline
+			err = doc.DataTo(&cOutline)
+			if err != nil {
+				return nil, translateError(err)
+			}
+
+			cOutline.Id = doc.Ref.ID
+			cOutline.Version = versionFromTime(doc.UpdateTime)
+
+			cOutlines = append(cOutlines, cOutline)
+		}
 	}
 
 	return cOutlines, nil
@@ -161,19 +205,78 @@ func (repo *CourseOutlineRepository) GetAllCourseOutlines(ctx context.Context, p
 func (repo *CourseOutlineRepository) UpdateCourseOutline(ctx context.Context, cOutline outline.CourseOutline) (outline.CourseOutline, error) {
 	cOutlineMap := convertOutlineToMap(cOutline)
 
-	_, err := repo.client.Collection(repo.CollectionName).Doc(cOutline.Id).Set(ctx, cOutlineMap, firestore.MergeAll)
+	version, err := updateDocument(ctx, repo.client, repo.collection(ctx).Doc(cOutline.Id), cOutline.Version, replacementUpdates(cOutlineMap, outlineFields...),
+		func(current *firestore.DocumentSnapshot) []*uniqueField {
+			return []*uniqueField{newUniqueField(repo.client, repo.collection(ctx), "course_code", stringAt(current, "course_code"), cOutline.CourseCode)}
+		})
+	if errors.Is(err, errValueReserved) {
+		return outline.CourseOutline{}, outline.ErrDuplicateCourseCode
+	}
+	if isVersionMismatch(err) {
+		return outline.CourseOutline{}, outline.ErrVersionMismatch
+	}
+	if isNotFound(err) {
+		return outline.CourseOutline{}, outline.ErrNotFound
+	}
 	if err != nil {
-		return outline.CourseOutline{}, err
+		return outline.CourseOutline{}, translateError(err)
 	}
 
+	cOutline.Version = version
+
 	return cOutline, nil
 }
 
+// Upserts the course outlines by course code
+func (repo *CourseOutlineRepository) ImportCourseOutlines(ctx context.Context, cOutlines []outline.CourseOutline, dryRun bool) ([]common.ImportResult, error) {
+	rows := make([]importDocument, len(cOutlines))
+	for i, cOutline := range cOutlines {
+		rows[i] = importDocument{key: *cOutline.CourseCode, id: cOutline.Id, data: convertOutlineToMap(cOutline)}
+	}
+
+	return importDocuments(ctx, repo.client, repo.collection(ctx), "course_code", outlineFields, rows, dryRun)
+}
+
+// Calls each with every active course outline, ordered by course code
+func (repo *CourseOutlineRepository) ExportCourseOutlines(ctx context.Context, each func(outline.CourseOutline) error) error {
+	query := repo.collection(ctx).OrderBy("course_code", firestore.Asc)
+
+	return eachActiveDocument(ctx, query, func(doc *firestore.DocumentSnapshot) error {
+		var cOutline outline.CourseOutline
+		if err := doc.DataTo(&cOutline); err != nil {
+			return translateError(err)
+		}
+
+		cOutline.Id = doc.Ref.ID
+
+		return each(cOutline)
+	})
+}
+
 func (repo *CourseOutlineRepository) DeleteCourseOutline(ctx context.Context, docID string) error {
-	_, err := repo.client.Collection(repo.CollectionName).Doc(docID).Delete(ctx)
+	err := softDelete(ctx, repo.client, repo.collection(ctx).Doc(docID))
+	if isNotFound(err) {
+		return outline.ErrNotFound
+	}
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
 	return nil
 }
+
+func (repo *CourseOutlineRepository) RestoreCourseOutline(ctx context.Context, docID string) error {
+	err := restore(ctx, repo.client, repo.collection(ctx).Doc(docID))
+	if isNotFound(err) {
+		return outline.ErrNotFound
+	}
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (repo *CourseOutlineRepository) PurgeDeletedCourseOutlines(ctx context.Context, before time.Time) (int, error) {
+	return purgeDeleted(ctx, repo.client, repo.client.CollectionGroup(repo.CollectionName).Query, before, "course_code")
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// Document versions are the Firestore update time in nanoseconds. They are
+// opaque to the rest of the application and are used as ETags by the transport.
+func versionFromTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// Converts a document map into top level field updates for DocumentRef.Update
+func updatesFromMap(docMap map[string]interface{}) []firestore.Update {
+	updates := make([]firestore.Update, 0, len(docMap))
+	for field, value := range docMap {
+		updates = append(updates, firestore.Update{Path: field, Value: value})
+	}
+	return updates
+}
+
+// Converts a document map into updates that replace the given fields. Fields
+// missing from the map are deleted, so the document ends up holding exactly
+// what was sent.
+func replacementUpdates(docMap map[string]interface{}, fields ...string) []firestore.Update {
+	updates := make([]firestore.Update, 0, len(fields))
+	for _, field := range fields {
+		value, ok := docMap[field]
+		if !ok {
+			value = firestore.Delete
+		}
+		updates = append(updates, firestore.Update{Path: field, Value: value})
+	}
+	return updates
+}
+
+// Only the version check of updateDocument is a failed precondition of the
+// request, Firestore reports other failures with the same code
+func isVersionMismatch(err error) bool {
+	return errors.Is(err, errVersionMismatch)
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"github.com/zzenonn/scoping-ai/internal/privacy"
+)
+
+type PrivacyRepository struct {
+	client                     *firestore.Client
+	UserCollectionName         string
+	ErasureCollectionName      string
+	OrganizationCollectionName string
+	MemberCollectionName       string
+}
+
+func NewPrivacyRepository(
+	client *firestore.Client, userCollectionName string, erasureCollectionName string,
+	organizationCollectionName string, memberCollectionName string,
+) PrivacyRepository {
+	return PrivacyRepository{
+		client:                     client,
+		UserCollectionName:         userCollectionName,
+		ErasureCollectionName:      erasureCollectionName,
+		OrganizationCollectionName: organizationCollectionName,
+		MemberCollectionName:       memberCollectionName,
+	}
+}
+
+// Collects the user document and its subcollections. Subcollections are
+// included even when the user document itself is already gone.
+func (repo *PrivacyRepository) userDocuments(ctx context.Context, userId string) ([]*firestore.DocumentSnapshot, error) {
+	refs, err := collectDocumentTree(ctx, tenantCollection(ctx, repo.client, repo.UserCollectionName).Doc(userId))
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := repo.client.GetAll(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []*firestore.DocumentSnapshot
+	for _, doc := range docs {
+		if doc.Exists() {
+			existing = append(existing, doc)
+		}
+	}
+
+	if len(existing) == 0 {
+		return nil, privacy.ErrNotFound
+	}
+
+	return existing, nil
+}
+
+func (repo *PrivacyRepository) EraseUser(ctx context.Context, userId string) (int, error) {
+	docs, err := repo.userDocuments(ctx, userId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	refs := make([]*firestore.DocumentRef, 0, len(docs)+2)
+	for _, doc := range docs {
+		refs = append(refs, doc.Ref)
+	}
+
+	// The user document is collected last and is the only one holding a
+	// reservation, which is deleted in the same batch
+	user := docs[len(docs)-1]
+
+	reservations, err := heldReservations(ctx, repo.client, docs[len(docs)-1:], "email_address")
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	if reservation, ok := reservations[user.Ref.Path]; ok {
+		refs = append(refs, reservation)
+	}
+
+	// The membership names the user, so it goes along with it
+	if orgId := stringAt(user, "organization_id"); orgId != nil {
+		refs = append(refs, tenantCollection(ctx, repo.client, repo.OrganizationCollectionName).Doc(*orgId).Collection(repo.MemberCollectionName).Doc(userId))
+	}
+
+	if _, err := deleteDocumentGroups(ctx, repo.client, [][]*firestore.DocumentRef{refs}); err != nil {
+		return 0, translateError(err)
+	}
+
+	return len(docs), nil
+}
+
+func (repo *PrivacyRepository) ExportUser(ctx context.Context, userId string) ([]privacy.Document, error) {
+	docs, err := repo.userDocuments(ctx, userId)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	documents := make([]privacy.Document, 0, len(docs))
+
+	// Descendants are collected first, so reverse to put the user document on top
+	for i := len(docs) - 1; i >= 0; i-- {
+		documents = append(documents, privacy.Document{
+			Path: relativePath(docs[i].Ref),
+			Data: docs[i].Data(),
+		})
+	}
+
+	return documents, nil
+}
+
+func (repo *PrivacyRepository) PostErasureRecord(ctx context.Context, record privacy.ErasureRecord) (privacy.ErasureRecord, error) {
+	_, err := tenantCollection(ctx, repo.client, repo.ErasureCollectionName).Doc(record.Id).Set(ctx, record)
+	if err != nil {
+		return privacy.ErasureRecord{}, translateError(err)
+	}
+
+	return record, nil
+}
+
+// Strips the project and database prefix from a document path
+func relativePath(ref *firestore.DocumentRef) string {
+	const separator = "/documents/"
+
+	if index := strings.Index(ref.Path, separator); index >= 0 {
+		return ref.Path[index+len(separator):]
+	}
+
+	return ref.Path
+}
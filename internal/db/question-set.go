@@ -2,12 +2,15 @@ package db
 
 import (
 	"context"
+	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	log "github.com/sirupsen/logrus"
 	questionSet "github.com/zzenonn/scoping-ai/internal/question-set"
+	"github.com/zzenonn/scoping-ai/pkg/common"
 	"google.golang.org/api/iterator"
 )
 
@@ -41,6 +44,14 @@ func NewQuestionSetRepository(client *firestore.Client, collectionName string) Q
 	}
 }
 
+// Returns the collection of the tenant in the context
+func (repo *QuestionSetRepository) collection(ctx context.Context) *firestore.CollectionRef {
+	return tenantCollection(ctx, repo.client, repo.CollectionName)
+}
+
+// Fields of a question set replaced by an update
+var questionSetFields = []string{"technology_name", "questions"}
+
 func convertQuestionSetToMap(qSet questionSet.QuestionSet) map[string]interface{} {
 	qSetMap := make(map[string]interface{})
 
@@ -76,78 +87,82 @@ func (repo *QuestionSetRepository) PostQuestionSet(ctx context.Context, qSet que
 
 	qSetMap := convertQuestionSetToMap(qSet)
 
-	_, err := repo.client.Collection(repo.CollectionName).Doc(qSet.Id).Set(ctx, qSetMap)
+	technologyName := newUniqueField(repo.client, repo.collection(ctx), "technology_name", nil, qSet.TechnologyName)
+
+	err := createDocument(ctx, repo.client, repo.collection(ctx).Doc(qSet.Id), qSetMap, technologyName)
+	if errors.Is(err, errValueReserved) {
+		return questionSet.QuestionSet{}, questionSet.ErrDuplicateTechnologyName
+	}
 	if err != nil {
-		return questionSet.QuestionSet{}, err
+		return questionSet.QuestionSet{}, translateError(err)
 	}
 
 	return qSet, nil
 }
 
 func (repo *QuestionSetRepository) GetQuestionSet(ctx context.Context, docID string) (questionSet.QuestionSet, error) {
-	doc, err := repo.client.Collection(repo.CollectionName).Doc(docID).Get(ctx)
+	doc, err := getActiveDocument(ctx, repo.collection(ctx).Doc(docID))
+	if isNotFound(err) {
+		return questionSet.QuestionSet{}, questionSet.ErrNotFound
+	}
 	if err != nil {
-		return questionSet.QuestionSet{}, err
+		return questionSet.QuestionSet{}, translateError(err)
 	}
 
 	var qSet questionSet.QuestionSet
 	doc.DataTo(&qSet)
 	qSet.Id = doc.Ref.ID
+	qSet.Version = versionFromTime(doc.UpdateTime)
 
 	return qSet, nil
 }
 
 func (repo *QuestionSetRepository) GetQuestionSetByTechName(ctx context.Context, techName string) (questionSet.QuestionSet, error) {
-	iter := repo.client.Collection(repo.CollectionName).Where("technology_name", "==", techName).Documents(ctx)
+	iter := repo.collection(ctx).Where("technology_name", "==", techName).Documents(ctx)
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return questionSet.QuestionSet{}, err
+			return questionSet.QuestionSet{}, translateError(err)
+		}
+
+		if isDeleted(doc) {
+			continue
 		}
 
 		var qSet questionSet.QuestionSet
 		err = doc.DataTo(&qSet)
 		qSet.Id = doc.Ref.ID
+		qSet.Version = versionFromTime(doc.UpdateTime)
 
 		if err != nil {
-			return questionSet.QuestionSet{}, err
+			return questionSet.QuestionSet{}, translateError(err)
 		}
 		return qSet, nil
 	}
-	return questionSet.QuestionSet{}, nil
+	return questionSet.QuestionSet{}, questionSet.ErrNotFound
 }
 
-func (repo *QuestionSetRepository) GetAllQuestionSets(ctx context.Context, page int, pageSize int) ([]questionSet.QuestionSet, error) {
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 {
-		pageSize = 10
-	}
+func (repo *QuestionSetRepository) GetAllQuestionSets(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]questionSet.QuestionSet, error) {
+	query := repo.collection(ctx).OrderBy("technology_name", firestore.Asc)
 
-	offset := (page - 1) * pageSize
+	docs, err := pagedDocuments(ctx, query, page, pageSize, includeDeleted)
+	if err != nil {
+		return nil, translateError(err)
+	}
 
-	iter := repo.client.Collection(repo.CollectionName).OrderBy("technology_name", firestore.Asc).Offset(offset).Limit(pageSize).Documents(ctx)
 	var qSets []questionSet.QuestionSet
 
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
+	for _, doc := range docs {
 		var qSet questionSet.QuestionSet
 		err = doc.DataTo(&qSet)
 		qSet.Id = doc.Ref.ID
+		qSet.Version = versionFromTime(doc.UpdateTime)
 
 		if err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 
 		qSets = append(qSets, qSet)
@@ -158,11 +173,71 @@ func (repo *QuestionSetRepository) GetAllQuestionSets(ctx context.Context, page
 func (repo *QuestionSetRepository) UpdateQuestionSet(ctx context.Context, qSet questionSet.QuestionSet) (questionSet.QuestionSet, error) {
 	qSetMap := convertQuestionSetToMap(qSet)
 	log.Debugf("Updating question set: %v", qSet.Id)
-	_, err := repo.client.Collection(repo.CollectionName).Doc(qSet.Id).Set(ctx, qSetMap, firestore.MergeAll)
-	return qSet, err
+
+	version, err := updateDocument(ctx, repo.client, repo.collection(ctx).Doc(qSet.Id), qSet.Version, replacementUpdates(qSetMap, questionSetFields...),
+		func(current *firestore.DocumentSnapshot) []*uniqueField {
+			return []*uniqueField{newUniqueField(repo.client, repo.collection(ctx), "technology_name", stringAt(current, "technology_name"), qSet.TechnologyName)}
+		})
+	if errors.Is(err, errValueReserved) {
+		return questionSet.QuestionSet{}, questionSet.ErrDuplicateTechnologyName
+	}
+	if isVersionMismatch(err) {
+		return questionSet.QuestionSet{}, questionSet.ErrVersionMismatch
+	}
+	if isNotFound(err) {
+		return questionSet.QuestionSet{}, questionSet.ErrNotFound
+	}
+	if err != nil {
+		return questionSet.QuestionSet{}, translateError(err)
+	}
+
+	qSet.Version = version
+
+	return qSet, nil
+}
+
+// Upserts the question sets by technology name
+func (repo *QuestionSetRepository) ImportQuestionSets(ctx context.Context, qSets []questionSet.QuestionSet, dryRun bool) ([]common.ImportResult, error) {
+	rows := make([]importDocument, len(qSets))
+	for i, qSet := range qSets {
+		rows[i] = importDocument{key: *qSet.TechnologyName, id: qSet.Id, data: convertQuestionSetToMap(qSet)}
+	}
+
+	return importDocuments(ctx, repo.client, repo.collection(ctx), "technology_name", questionSetFields, rows, dryRun)
+}
+
+// Calls each with every active question set, ordered by technology name
+func (repo *QuestionSetRepository) ExportQuestionSets(ctx context.Context, each func(questionSet.QuestionSet) error) error {
+	query := repo.collection(ctx).OrderBy("technology_name", firestore.Asc)
+
+	return eachActiveDocument(ctx, query, func(doc *firestore.DocumentSnapshot) error {
+		var qSet questionSet.QuestionSet
+		if err := doc.DataTo(&qSet); err != nil {
+			return translateError(err)
+		}
+
+		qSet.Id = doc.Ref.ID
+
+		return each(qSet)
+	})
 }
 
 func (repo *QuestionSetRepository) DeleteQuestionSet(ctx context.Context, docID string) error {
-	_, err := repo.client.Collection(repo.CollectionName).Doc(docID).Delete(ctx)
-	return err
+	err := softDelete(ctx, repo.client, repo.collection(ctx).Doc(docID))
+	if isNotFound(err) {
+		return questionSet.ErrNotFound
+	}
+	return translateError(err)
+}
+
+func (repo *QuestionSetRepository) RestoreQuestionSet(ctx context.Context, docID string) error {
+	err := restore(ctx, repo.client, repo.collection(ctx).Doc(docID))
+	if isNotFound(err) {
+		return questionSet.ErrNotFound
+	}
+	return translateError(err)
+}
+
+func (repo *QuestionSetRepository) PurgeDeletedQuestionSets(ctx context.Context, before time.Time) (int, error) {
+	return purgeDeleted(ctx, repo.client, repo.client.CollectionGroup(repo.CollectionName).Query, before, "technology_name")
 }
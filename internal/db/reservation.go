@@ -0,0 +1,226 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Unique values are claimed by a reservation document whose id is derived from
+// the value. Reading it inside a transaction detects duplicates that a query
+// could miss between the check and the write.
+const reservationCollectionName = "unique_keys"
+
+var errValueReserved = errors.New("value is reserved by another document")
+
+// Values are compared case insensitively, so "Acme" and "ACME " collide
+func normalizeUniqueValue(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// Kinds are namespaced by collection and field, e.g. question_sets.technology_name
+func uniqueKind(collectionName string, field string) string {
+	return collectionName + "." + field
+}
+
+// Reservations are kept next to the collection they guard, so every tenant
+// claims its values independently
+func reservationRef(client *firestore.Client, collection *firestore.CollectionRef, field string, value string) *firestore.DocumentRef {
+	reservations := client.Collection(reservationCollectionName)
+	if collection.Parent != nil {
+		reservations = collection.Parent.Collection(reservationCollectionName)
+	}
+
+	return reservations.Doc(uniqueKind(collection.ID, field) + ":" + url.PathEscape(normalizeUniqueValue(value)))
+}
+
+// Returns the id of the document holding the reservation, or an empty string
+// when the value is free. Like every read it must happen before the writes of
+// the transaction.
+func reservationOwner(tx *firestore.Transaction, ref *firestore.DocumentRef) (string, error) {
+	return ownerOf(tx.Get(ref))
+}
+
+func ownerOf(doc *firestore.DocumentSnapshot, err error) (string, error) {
+	if status.Code(err) == codes.NotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	owner, err := doc.DataAt("owner_id")
+	if err != nil {
+		return "", err
+	}
+
+	ownerId, _ := owner.(string)
+	return ownerId, nil
+}
+
+// Reads the owner of a reservation, either in a transaction or directly
+type reservationReader func(ref *firestore.DocumentRef) (string, error)
+
+func transactionReader(tx *firestore.Transaction) reservationReader {
+	return func(ref *firestore.DocumentRef) (string, error) {
+		return reservationOwner(tx, ref)
+	}
+}
+
+func directReader(ctx context.Context) reservationReader {
+	return func(ref *firestore.DocumentRef) (string, error) {
+		return ownerOf(ref.Get(ctx))
+	}
+}
+
+// Tracks a unique field of a document whose value is set or changed by a write
+type uniqueField struct {
+	oldValue  *string
+	newValue  *string
+	oldRef    *firestore.DocumentRef
+	newRef    *firestore.DocumentRef
+	oldHolder string
+	newHolder string
+}
+
+func newUniqueField(client *firestore.Client, collection *firestore.CollectionRef, name string, oldValue *string, newValue *string) *uniqueField {
+	field := &uniqueField{oldValue: oldValue, newValue: newValue}
+
+	if oldValue != nil {
+		field.oldRef = reservationRef(client, collection, name, *oldValue)
+	}
+	if newValue != nil {
+		field.newRef = reservationRef(client, collection, name, *newValue)
+	}
+
+	return field
+}
+
+func (field *uniqueField) changed() bool {
+	if field.oldValue == nil || field.newValue == nil {
+		return field.oldValue != field.newValue
+	}
+	return normalizeUniqueValue(*field.oldValue) != normalizeUniqueValue(*field.newValue)
+}
+
+// Reads the reservations involved in the change. Fails if the new value is held
+// by another document.
+func (field *uniqueField) read(owner reservationReader, ownerId string) error {
+	if !field.changed() {
+		return nil
+	}
+
+	var err error
+
+	if field.oldRef != nil {
+		if field.oldHolder, err = owner(field.oldRef); err != nil {
+			return err
+		}
+	}
+
+	if field.newRef != nil {
+		if field.newHolder, err = owner(field.newRef); err != nil {
+			return err
+		}
+	}
+
+	if field.newHolder != "" && field.newHolder != ownerId {
+		return errValueReserved
+	}
+
+	return nil
+}
+
+// Moves the reservation from the old value to the new one
+func (field *uniqueField) write(tx *firestore.Transaction, ownerId string) error {
+	if !field.changed() {
+		return nil
+	}
+
+	if field.newRef != nil {
+		err := tx.Set(field.newRef, map[string]interface{}{
+			"value":    normalizeUniqueValue(*field.newValue),
+			"owner_id": ownerId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Documents written before reservations existed may not hold their old value
+	if field.oldRef != nil && field.oldHolder == ownerId {
+		return tx.Delete(field.oldRef)
+	}
+
+	return nil
+}
+
+// Adds the move of the reservation to a batch. A free value is claimed with a
+// create, so the batch fails if another document claimed it since it was read.
+func (field *uniqueField) batch(batch *firestore.WriteBatch, ownerId string) {
+	if !field.changed() {
+		return
+	}
+
+	if field.newRef != nil {
+		data := map[string]interface{}{
+			"value":    normalizeUniqueValue(*field.newValue),
+			"owner_id": ownerId,
+		}
+
+		if field.newHolder == "" {
+			batch.Create(field.newRef, data)
+		} else {
+			batch.Set(field.newRef, data)
+		}
+	}
+
+	if field.oldRef != nil && field.oldHolder == ownerId {
+		batch.Delete(field.oldRef)
+	}
+}
+
+// Returns the reservations of the given field that are held by the documents
+// themselves, by the path of the document holding them
+func heldReservations(ctx context.Context, client *firestore.Client, docs []*firestore.DocumentSnapshot, field string) (map[string]*firestore.DocumentRef, error) {
+	var refs []*firestore.DocumentRef
+	var owners []*firestore.DocumentRef
+
+	for _, doc := range docs {
+		value := stringAt(doc, field)
+		if value == nil {
+			continue
+		}
+
+		refs = append(refs, reservationRef(client, doc.Ref.Parent, field, *value))
+		owners = append(owners, doc.Ref)
+	}
+
+	held := map[string]*firestore.DocumentRef{}
+
+	if len(refs) == 0 {
+		return held, nil
+	}
+
+	reservations, err := getAllDocuments(ctx, client, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, reservation := range reservations {
+		if !reservation.Exists() {
+			continue
+		}
+
+		if owner := stringAt(reservation, "owner_id"); owner != nil && *owner == owners[i].ID {
+			held[owners[i].Path] = reservation.Ref
+		}
+	}
+
+	return held, nil
+}
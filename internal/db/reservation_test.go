@@ -0,0 +1,91 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+)
+
+func TestUniqueFieldChanged(t *testing.T) {
+	value := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		oldValue *string
+		newValue *string
+		want     bool
+	}{
+		{"same value", value("AWS"), value("AWS"), false},
+		{"other case and spacing", value("AWS"), value(" aws "), false},
+		{"other value", value("AWS"), value("GCP"), true},
+		{"value set", nil, value("AWS"), true},
+		{"value cleared", value("AWS"), nil, true},
+		{"no value", nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &uniqueField{oldValue: tt.oldValue, newValue: tt.newValue}
+			if got := field.changed(); got != tt.want {
+				t.Errorf("changed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueFieldRead(t *testing.T) {
+	oldValue, newValue := "AWS", "GCP"
+	oldRef := &firestore.DocumentRef{ID: "question_sets.technology_name:aws"}
+	newRef := &firestore.DocumentRef{ID: "question_sets.technology_name:gcp"}
+	errDatabase := errors.New("database unavailable")
+
+	tests := []struct {
+		name       string
+		holders    map[string]string
+		readErr    error
+		wantErr    error
+		wantHolder string
+	}{
+		{"free value", map[string]string{oldRef.ID: "doc-1"}, nil, nil, ""},
+		{"value held by the document", map[string]string{newRef.ID: "doc-1"}, nil, nil, "doc-1"},
+		{"value held by another document", map[string]string{newRef.ID: "doc-2"}, nil, errValueReserved, "doc-2"},
+		{"read fails", nil, errDatabase, errDatabase, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &uniqueField{oldValue: &oldValue, newValue: &newValue, oldRef: oldRef, newRef: newRef}
+
+			owner := func(ref *firestore.DocumentRef) (string, error) {
+				return tt.holders[ref.ID], tt.readErr
+			}
+
+			if err := field.read(owner, "doc-1"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if field.newHolder != tt.wantHolder {
+				t.Errorf("got holder %q of the new value, want %q", field.newHolder, tt.wantHolder)
+			}
+			if field.oldHolder != tt.holders[oldRef.ID] {
+				t.Errorf("got holder %q of the old value, want %q", field.oldHolder, tt.holders[oldRef.ID])
+			}
+		})
+	}
+}
+
+func TestUniqueFieldReadUnchanged(t *testing.T) {
+	value := "AWS"
+	ref := &firestore.DocumentRef{ID: "question_sets.technology_name:aws"}
+	field := &uniqueField{oldValue: &value, newValue: &value, oldRef: ref, newRef: ref}
+
+	// Reservations of a value that doesn't change are left alone
+	owner := func(ref *firestore.DocumentRef) (string, error) {
+		t.Fatalf("read the reservation %s", ref.ID)
+		return "", nil
+	}
+
+	if err := field.read(owner, "doc-1"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errDocumentNotFound = errors.New("document not found")
+
+func isNotFound(err error) bool {
+	return errors.Is(err, errDocumentNotFound) || status.Code(err) == codes.NotFound
+}
+
+// Documents are created with a null deleted_at and get it back when they are
+// restored, so active documents can be queried with deleted_at == null.
+// Documents written before that are given one by the migration.
+func markActive(data map[string]interface{}) map[string]interface{} {
+	data["deleted_at"] = nil
+	return data
+}
+
+func isDeleted(doc *firestore.DocumentSnapshot) bool {
+	deletedAt, err := doc.DataAt("deleted_at")
+	return err == nil && deletedAt != nil
+}
+
+// Runs a paginated query. Unless they are included, soft deleted documents are
+// filtered out by Firestore, which needs a composite index on deleted_at and
+// the fields the query orders by.
+func pagedDocuments(ctx context.Context, query firestore.Query, page int, pageSize int, includeDeleted bool) ([]*firestore.DocumentSnapshot, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if !includeDeleted {
+		query = query.Where("deleted_at", "==", nil)
+	}
+
+	return query.Offset((page - 1) * pageSize).Limit(pageSize).Documents(ctx).GetAll()
+}
+
+// Fetches a document, treating soft deleted documents as missing
+func getActiveDocument(ctx context.Context, ref *firestore.DocumentRef) (*firestore.DocumentSnapshot, error) {
+	doc, err := ref.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if isDeleted(doc) {
+		return nil, errDocumentNotFound
+	}
+
+	return doc, nil
+}
+
+// Marks a document as deleted by the caller in the context
+func softDelete(ctx context.Context, client *firestore.Client, ref *firestore.DocumentRef) error {
+	var deletedBy interface{} = firestore.Delete
+	if ident, ok := identity.FromContext(ctx); ok {
+		deletedBy = ident.UID
+	}
+
+	return client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+
+		if isDeleted(doc) {
+			return errDocumentNotFound
+		}
+
+		return tx.Update(ref, []firestore.Update{
+			{Path: "deleted_at", Value: firestore.ServerTimestamp},
+			{Path: "deleted_by", Value: deletedBy},
+		})
+	})
+}
+
+// Clears the deletion markers of a document. Restoring a document that is not
+// deleted is a no-op.
+func restore(ctx context.Context, client *firestore.Client, ref *firestore.DocumentRef) error {
+	return client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+
+		if !isDeleted(doc) {
+			return nil
+		}
+
+		return tx.Update(ref, []firestore.Update{
+			{Path: "deleted_at", Value: nil},
+			{Path: "deleted_by", Value: firestore.Delete},
+		})
+	})
+}
+
+// Permanently deletes documents that were soft deleted before the given time,
+// releasing the reservations they hold for the given unique fields. Callers
+// pass collection group queries so the purge covers every tenant.
+func purgeDeleted(ctx context.Context, client *firestore.Client, query firestore.Query, before time.Time, uniqueFields ...string) (int, error) {
+	docs, err := query.Where("deleted_at", "<=", before).Documents(ctx).GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	// Each document goes in the same batch as its reservations
+	groups := make([][]*firestore.DocumentRef, len(docs))
+	for i, doc := range docs {
+		groups[i] = []*firestore.DocumentRef{doc.Ref}
+	}
+
+	for _, field := range uniqueFields {
+		reservations, err := heldReservations(ctx, client, docs, field)
+		if err != nil {
+			return 0, err
+		}
+
+		for i, doc := range docs {
+			if reservation, ok := reservations[doc.Ref.Path]; ok {
+				groups[i] = append(groups[i], reservation)
+			}
+		}
+	}
+
+	return deleteDocumentGroups(ctx, client, groups)
+}
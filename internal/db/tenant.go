@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/zzenonn/scoping-ai/internal/tenant"
+)
+
+// Collections of a tenant live under tenants/{tenantId}. The default tenant
+// keeps its collections at the root, where they were before tenants existed.
+const tenantCollectionName = "tenants"
+
+// Returns the named collection of the tenant in the context. Every repository
+// goes through here, so no query can reach the data of another tenant.
+func tenantCollection(ctx context.Context, client *firestore.Client, name string) *firestore.CollectionRef {
+	tenantId, ok := tenant.FromContext(ctx)
+	if !ok {
+		return client.Collection(name)
+	}
+
+	return client.Collection(tenantCollectionName).Doc(tenantId).Collection(name)
+}
+
+// Returns the named collection of the tenant a top level document belongs to
+func siblingCollection(client *firestore.Client, ref *firestore.DocumentRef, name string) *firestore.CollectionRef {
+	if ref.Parent.Parent == nil {
+		return client.Collection(name)
+	}
+
+	return ref.Parent.Parent.Collection(name)
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/zzenonn/scoping-ai/internal/ticket"
+)
+
+// Tickets are redeemed before the tenant of a request is known, so like API
+// keys they are kept at the root and carry their tenant id. Redeemed tickets
+// are deleted, a TTL policy on expires_at removes the rest.
+type TicketRepository struct {
+	client         *firestore.Client
+	CollectionName string
+}
+
+func NewTicketRepository(client *firestore.Client, collectionName string) TicketRepository {
+	return TicketRepository{
+		client:         client,
+		CollectionName: collectionName,
+	}
+}
+
+func (repo *TicketRepository) PostTicket(ctx context.Context, t ticket.Ticket) error {
+	if _, err := repo.client.Collection(repo.CollectionName).Doc(t.Id).Create(ctx, t); err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (repo *TicketRepository) TakeTicket(ctx context.Context, id string) (ticket.Ticket, error) {
+	ref := repo.client.Collection(repo.CollectionName).Doc(id)
+
+	var taken ticket.Ticket
+
+	err := repo.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if isNotFound(err) {
+			return ticket.ErrInvalidTicket
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := doc.DataTo(&taken); err != nil {
+			return err
+		}
+
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		return ticket.Ticket{}, translateError(err)
+	}
+
+	return taken, nil
+}
@@ -2,13 +2,17 @@ package db
 
 import (
 	"context"
+	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	log "github.com/sirupsen/logrus"
 	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func init() {
@@ -41,70 +45,131 @@ func NewUserRepository(client *firestore.Client, collectionName string) UserRepo
 	}
 }
 
+// Returns the collection of the tenant in the context
+func (repo *UserRepository) collection(ctx context.Context) *firestore.CollectionRef {
+	return tenantCollection(ctx, repo.client, repo.CollectionName)
+}
+
 func convertUserToMap(user scopingUser.User) (map[string]interface{}, error) {
 	if user.Name == nil || user.EmailAddress == nil {
 		return nil, ErrMissingRequiredFields
 	}
 
+	// Corporate, organization_id and organization_role follow the organization
+	// membership of the user and are only written by the organization repository
 	userMap := map[string]interface{}{
 		"id":            user.ID,
 		"name":          *user.Name,
 		"email_address": *user.EmailAddress,
-		"corporate":     user.Corporate,
 	}
 
-	if user.Company != nil {
-		userMap["company"] = *user.Company
+	// Roles are left untouched unless they are explicitly set
+	if user.Roles != nil {
+		userMap["roles"] = user.Roles
 	}
 
 	return userMap, nil
 }
 
 func (repo *UserRepository) GetUser(ctx context.Context, id string) (scopingUser.User, error) {
-	doc, err := repo.client.Collection(repo.CollectionName).Doc(id).Get(ctx)
+	doc, err := getActiveDocument(ctx, repo.collection(ctx).Doc(id))
+	if isNotFound(err) {
+		return scopingUser.User{}, scopingUser.ErrNotFound
+	}
 	if err != nil {
-		return scopingUser.User{}, err
+		return scopingUser.User{}, translateError(err)
 	}
 
 	var user scopingUser.User
 	err = doc.DataTo(&user)
 	if err != nil {
-		return scopingUser.User{}, err
+		return scopingUser.User{}, translateError(err)
 	}
 
 	user.ID = id
+	user.Version = versionFromTime(doc.UpdateTime)
 
 	return user, nil
 }
 
-func (repo *UserRepository) GetAllUsers(ctx context.Context, page int, pageSize int) ([]scopingUser.User, error) {
-	if page < 1 {
-		page = 1
+func (repo *UserRepository) GetAllUsers(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]scopingUser.User, error) {
+	query := repo.collection(ctx).OrderBy("email_address", firestore.Asc)
+
+	docs, err := pagedDocuments(ctx, query, page, pageSize, includeDeleted)
+	if err != nil {
+		return nil, translateError(err)
 	}
-	if pageSize < 1 {
-		pageSize = 10
+
+	var users []scopingUser.User
+
+	for _, doc := range docs {
+		var u scopingUser.User
+		err = doc.DataTo(&u)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		users = append(users, u)
 	}
 
-	offset := (page - 1) * pageSize
+	return users, nil
+}
+
+func (repo *UserRepository) GetUsersByFilter(
+	ctx context.Context, page int, pageSize int,
+	filterName string, filterValue string, includeDeleted bool,
+) ([]scopingUser.User, error) {
+	query := repo.collection(ctx).Where(filterName, "==", filterValue).OrderBy("email_address", firestore.Asc)
+
+	docs, err := pagedDocuments(ctx, query, page, pageSize, includeDeleted)
+	if err != nil {
+		return nil, translateError(err)
+	}
 
-	iter := repo.client.Collection(repo.CollectionName).OrderBy("email_address", firestore.Asc).Offset(offset).Limit(pageSize).Documents(ctx)
 	var users []scopingUser.User
 
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
+	for _, doc := range docs {
+		var u scopingUser.User
+		err = doc.DataTo(&u)
 		if err != nil {
-			return nil, err
+			return nil, translateError(err)
+		}
+
+		users = append(users, u)
+	}
+
+	return users, nil
+}
+
+// Fetches the users in as few round trips as Firestore allows. Missing and
+// soft deleted users are left out.
+func (repo *UserRepository) GetUsersByIds(ctx context.Context, ids []string) ([]scopingUser.User, error) {
+	refs := make([]*firestore.DocumentRef, 0, len(ids))
+	for _, id := range ids {
+		refs = append(refs, repo.collection(ctx).Doc(id))
+	}
+
+	docs, err := repo.client.GetAll(ctx, refs)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var users []scopingUser.User
+
+	for _, doc := range docs {
+		if !doc.Exists() || isDeleted(doc) {
+			continue
 		}
 
 		var u scopingUser.User
 		err = doc.DataTo(&u)
 		if err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 
+		u.ID = doc.Ref.ID
+		u.Version = versionFromTime(doc.UpdateTime)
+
 		users = append(users, u)
 	}
 
@@ -114,36 +179,239 @@ func (repo *UserRepository) GetAllUsers(ctx context.Context, page int, pageSize
 func (repo *UserRepository) CreateUser(ctx context.Context, user scopingUser.User) (scopingUser.User, error) {
 	userMap, err := convertUserToMap(user)
 	if err != nil {
-		return scopingUser.User{}, err
+		return scopingUser.User{}, translateError(err)
 	}
 
-	_, err = repo.client.Collection(repo.CollectionName).Doc(user.ID).Set(ctx, userMap)
+	emailAddress := newUniqueField(repo.client, repo.collection(ctx), "email_address", nil, user.EmailAddress)
+
+	err = createDocument(ctx, repo.client, repo.collection(ctx).Doc(user.ID), userMap, emailAddress)
+	if errors.Is(err, errValueReserved) {
+		return scopingUser.User{}, scopingUser.ErrDuplicateEmail
+	}
+	if status.Code(err) == codes.AlreadyExists {
+		return scopingUser.User{}, scopingUser.ErrUserExists
+	}
 	if err != nil {
-		return scopingUser.User{}, err
+		return scopingUser.User{}, translateError(err)
 	}
 
 	return user, nil
 }
 
+// Returns the user keyed by the uid of the caller, creating it on first sign in.
+// When linkExisting is set, a user created by an admin under a UUID with the
+// same email address is linked instead: it is moved to the uid along with its
+// subcollections. Any other holder of the address is a conflict.
+func (repo *UserRepository) ProvisionUser(ctx context.Context, user scopingUser.User, linkExisting bool) (scopingUser.User, error) {
+	userMap, err := convertUserToMap(user)
+	if err != nil {
+		return scopingUser.User{}, translateError(err)
+	}
+
+	ref := repo.collection(ctx).Doc(user.ID)
+	reservation := reservationRef(repo.client, repo.collection(ctx), "email_address", *user.EmailAddress)
+
+	var linkedRef *firestore.DocumentRef
+
+	err = repo.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		linkedRef = nil
+
+		current, err := tx.Get(ref)
+		if err == nil {
+			if isDeleted(current) {
+				return errDocumentNotFound
+			}
+			return nil
+		}
+		if !isNotFound(err) {
+			return translateError(err)
+		}
+
+		ownerId, err := reservationOwner(tx, reservation)
+		if err != nil {
+			return translateError(err)
+		}
+
+		if ownerId != "" && ownerId != user.ID && linkExisting && scopingUser.IsLinkableId(ownerId) {
+			existing, err := tx.Get(repo.collection(ctx).Doc(ownerId))
+			if err != nil && !isNotFound(err) {
+				return translateError(err)
+			}
+
+			// Link the existing user, keeping what was entered for it
+			if err == nil && existing.Exists() && !isDeleted(existing) {
+				linkedRef = existing.Ref
+				userMap = existing.Data()
+				userMap["id"] = user.ID
+			}
+		}
+
+		if ownerId != "" && ownerId != user.ID && linkedRef == nil {
+			return errValueReserved
+		}
+
+		if err := tx.Set(reservation, map[string]interface{}{
+			"value":    normalizeUniqueValue(*user.EmailAddress),
+			"owner_id": user.ID,
+		}); err != nil {
+			return translateError(err)
+		}
+
+		if linkedRef != nil {
+			if err := tx.Delete(linkedRef); err != nil {
+				return translateError(err)
+			}
+		}
+
+		return tx.Create(ref, markActive(userMap))
+	})
+	if errors.Is(err, errValueReserved) {
+		return scopingUser.User{}, scopingUser.ErrDuplicateEmail
+	}
+	if isNotFound(err) {
+		return scopingUser.User{}, scopingUser.ErrNotFound
+	}
+	if err != nil {
+		return scopingUser.User{}, translateError(err)
+	}
+
+	if linkedRef != nil {
+		log.Infof("Linked user %s to %s", linkedRef.ID, user.ID)
+
+		if err := repo.moveSubcollections(ctx, linkedRef, ref); err != nil {
+			log.Errorf("Failed to move the subcollections of %s to %s, they have to be moved again", linkedRef.Path, ref.Path)
+			return scopingUser.User{}, translateError(err)
+		}
+	}
+
+	return repo.GetUser(ctx, user.ID)
+}
+
+// Moves the documents of every subcollection of a user to another user. Runs
+// after linking, moving a document twice only writes it again.
+func (repo *UserRepository) moveSubcollections(ctx context.Context, from *firestore.DocumentRef, to *firestore.DocumentRef) error {
+	collections := from.Collections(ctx)
+	for {
+		collection, err := collections.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		docs, err := collection.Documents(ctx).GetAll()
+		if err != nil {
+			return err
+		}
+
+		// Each document takes two writes
+		for start := 0; start < len(docs); start += maxBatchSize / 2 {
+			end := start + maxBatchSize/2
+			if end > len(docs) {
+				end = len(docs)
+			}
+
+			batch := repo.client.Batch()
+			for _, doc := range docs[start:end] {
+				data := doc.Data()
+				if _, ok := data["user_id"]; ok {
+					data["user_id"] = to.ID
+				}
+
+				batch.Set(to.Collection(collection.ID).Doc(doc.Ref.ID), data)
+				batch.Delete(doc.Ref)
+			}
+
+			if _, err := batch.Commit(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func (repo *UserRepository) UpdateUser(ctx context.Context, user scopingUser.User) (scopingUser.User, error) {
 	userMap, err := convertUserToMap(user)
 	if err != nil {
-		return scopingUser.User{}, err
+		return scopingUser.User{}, translateError(err)
 	}
 
-	_, err = repo.client.Collection(repo.CollectionName).Doc(user.ID).Set(ctx, userMap, firestore.MergeAll)
+	version, err := updateDocument(ctx, repo.client, repo.collection(ctx).Doc(user.ID), user.Version, updatesFromMap(userMap),
+		func(current *firestore.DocumentSnapshot) []*uniqueField {
+			return []*uniqueField{newUniqueField(repo.client, repo.collection(ctx), "email_address", stringAt(current, "email_address"), user.EmailAddress)}
+		})
+	if errors.Is(err, errValueReserved) {
+		return scopingUser.User{}, scopingUser.ErrDuplicateEmail
+	}
+	if isVersionMismatch(err) {
+		return scopingUser.User{}, scopingUser.ErrVersionMismatch
+	}
+	if isNotFound(err) {
+		return scopingUser.User{}, scopingUser.ErrNotFound
+	}
 	if err != nil {
-		return scopingUser.User{}, err
+		return scopingUser.User{}, translateError(err)
 	}
 
+	user.Version = version
+
 	return user, nil
 }
 
 func (repo *UserRepository) DeleteUser(ctx context.Context, id string) error {
-	_, err := repo.client.Collection(repo.CollectionName).Doc(id).Delete(ctx)
+	err := softDelete(ctx, repo.client, repo.collection(ctx).Doc(id))
+	if isNotFound(err) {
+		return scopingUser.ErrNotFound
+	}
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
 	return nil
 }
+
+func (repo *UserRepository) RestoreUser(ctx context.Context, id string) error {
+	err := restore(ctx, repo.client, repo.collection(ctx).Doc(id))
+	if isNotFound(err) {
+		return scopingUser.ErrNotFound
+	}
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// Purging a user also removes everything stored under it, such as its messages
+func (repo *UserRepository) PurgeDeletedUsers(ctx context.Context, before time.Time) (int, error) {
+	docs, err := repo.client.CollectionGroup(repo.CollectionName).Where("deleted_at", "<=", before).Documents(ctx).GetAll()
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	reservations, err := heldReservations(ctx, repo.client, docs, "email_address")
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	// The user document is collected last, so it goes in the same batch as
+	// its reservation
+	for i, doc := range docs {
+		refs, err := collectDocumentTree(ctx, doc.Ref)
+		if err != nil {
+			return i, translateError(err)
+		}
+
+		if reservation, ok := reservations[doc.Ref.Path]; ok {
+			refs = append(refs, reservation)
+		}
+
+		if _, err := deleteDocumentGroups(ctx, repo.client, [][]*firestore.DocumentRef{refs}); err != nil {
+			return i, translateError(err)
+		}
+	}
+
+	return len(docs), nil
+}
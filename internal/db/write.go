@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"cloud.google.com/go/firestore"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errVersionMismatch = errors.New("document was modified since the expected version")
+
+// Returns the string value of a field, or nil if it is missing
+func stringAt(doc *firestore.DocumentSnapshot, field string) *string {
+	value, err := doc.DataAt(field)
+	if err != nil {
+		return nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return nil
+	}
+
+	return &s
+}
+
+// Creates a document and claims its unique values in one transaction
+func createDocument(ctx context.Context, client *firestore.Client, ref *firestore.DocumentRef, data map[string]interface{}, uniqueFields ...*uniqueField) error {
+	return client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		for _, field := range uniqueFields {
+			if err := field.read(transactionReader(tx), ref.ID); err != nil {
+				return err
+			}
+		}
+
+		for _, field := range uniqueFields {
+			if err := field.write(tx, ref.ID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(ref, markActive(data))
+	})
+}
+
+// Updates are retried when the document or one of its reservations changed
+// between reading and committing them, as often as transactions are
+const maxUpdateAttempts = 5
+
+// Updates an active document and returns its new version. The update fails if
+// the document changed since the expected version, when one is given. Unique
+// values are moved along with the update; uniqueFields receives the current
+// document to compare against.
+func updateDocument(
+	ctx context.Context, client *firestore.Client, ref *firestore.DocumentRef,
+	version string, updates []firestore.Update,
+	uniqueFields func(current *firestore.DocumentSnapshot) []*uniqueField,
+) (string, error) {
+	for attempt := 1; ; attempt++ {
+		newVersion, err := commitUpdate(ctx, client, ref, version, updates, uniqueFields)
+		if attempt < maxUpdateAttempts && isContended(err) {
+			continue
+		}
+
+		return newVersion, contentionError(err)
+	}
+}
+
+// Reads the document and its reservations, then commits the update in a batch
+// guarded by preconditions. Unlike a transaction, the batch reports the time
+// of its writes, which is the new version of the document.
+func commitUpdate(
+	ctx context.Context, client *firestore.Client, ref *firestore.DocumentRef,
+	version string, updates []firestore.Update,
+	uniqueFields func(current *firestore.DocumentSnapshot) []*uniqueField,
+) (string, error) {
+	current, err := ref.Get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if isDeleted(current) {
+		return "", errDocumentNotFound
+	}
+
+	if version != "" && version != versionFromTime(current.UpdateTime) {
+		return "", errVersionMismatch
+	}
+
+	var fields []*uniqueField
+	if uniqueFields != nil {
+		fields = uniqueFields(current)
+	}
+
+	for _, field := range fields {
+		if err := field.read(directReader(ctx), ref.ID); err != nil {
+			return "", err
+		}
+	}
+
+	batch := client.Batch()
+	for _, field := range fields {
+		field.batch(batch, ref.ID)
+	}
+
+	// Fails the whole batch if the document changed since it was read
+	batch.Update(ref, updates, firestore.LastUpdateTime(current.UpdateTime))
+
+	results, err := batch.Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return versionFromTime(results[len(results)-1].UpdateTime), nil
+}
+
+// Reports whether a batch failed because a document it guards changed
+func isContended(err error) bool {
+	code := status.Code(err)
+	return code == codes.FailedPrecondition || code == codes.AlreadyExists
+}
+
+// Reports a batch that lost to concurrent writes like an aborted transaction
+func contentionError(err error) error {
+	if !isContended(err) {
+		return err
+	}
+	return common.Wrap(common.KindConflict, "the document was changed by another request, try again", err)
+}
@@ -0,0 +1,208 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+const (
+	StatePending   = "pending"
+	StateCompleted = "completed"
+)
+
+const MaxKeyLength = 255
+
+// How long a key stays claimed by a request that is still being served. The
+// claim is extended while the request runs, so a server that stops while
+// serving it doesn't hold the key for the whole window.
+const (
+	pendingTimeout = 2 * time.Minute
+	renewInterval  = pendingTimeout / 4
+)
+
+var (
+	ErrKeyTooLong = common.NewError(common.KindValidation, "the idempotency key must be at most 255 characters")
+	ErrKeyInUse   = common.NewError(common.KindConflict, "a request with this idempotency key is still being served")
+	ErrKeyReused  = common.NewError(common.KindUnprocessable, "the idempotency key was already used for a different request")
+)
+
+// The response to a request made with an idempotency key. Keys are scoped to
+// the caller and the route, the id is their hash so any key makes a valid
+// document id.
+type Record struct {
+	Id          string            `firestore:"id"`
+	Fingerprint string            `firestore:"fingerprint"`
+	State       string            `firestore:"state"`
+	Status      int               `firestore:"status,omitempty"`
+	Header      map[string]string `firestore:"header,omitempty"`
+	Body        []byte            `firestore:"body,omitempty"`
+	CreatedAt   time.Time         `firestore:"created_at"`
+	ExpiresAt   time.Time         `firestore:"expires_at"`
+}
+
+// Implements the idempotency repository interface design pattern
+type IdempotencyRepository interface {
+	// Stores the record unless an unexpired record holds its id, in which
+	// case that record is returned and nothing is stored
+	ClaimKey(ctx context.Context, record Record) (Record, bool, error)
+	// Moves the expiry of a pending record with the same fingerprint,
+	// returning false when the key no longer holds it
+	ExtendClaim(ctx context.Context, record Record) (bool, error)
+	SaveResponse(ctx context.Context, record Record) error
+	ReleaseKey(ctx context.Context, id string) error
+}
+
+type IdempotencyService struct {
+	idempotencyRepository IdempotencyRepository
+	window                time.Duration
+}
+
+// Responses are replayed for the window after they were sent
+func NewIdempotencyService(idempotencyRepository IdempotencyRepository, window time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepository: idempotencyRepository,
+		window:                window,
+	}
+}
+
+func recordId(scope string, key string) string {
+	sum := sha256.Sum256([]byte(scope + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Claims the key for a request. When the key was already used for the same
+// request, the record of its response is returned to be replayed.
+func (service *IdempotencyService) Begin(ctx context.Context, scope string, key string, fingerprint string) (Record, bool, error) {
+	log.Debug("Claiming idempotency key . . .")
+
+	if len(key) > MaxKeyLength {
+		return Record{}, false, ErrKeyTooLong
+	}
+
+	now := time.Now().UTC()
+
+	record := Record{
+		Id:          recordId(scope, key),
+		Fingerprint: fingerprint,
+		State:       StatePending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(pendingTimeout),
+	}
+
+	existing, claimed, err := service.idempotencyRepository.ClaimKey(ctx, record)
+	if err != nil {
+		log.Error("Failed to claim idempotency key")
+		return Record{}, false, err
+	}
+
+	if claimed {
+		return record, false, nil
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return Record{}, false, ErrKeyReused
+	}
+
+	if existing.State != StateCompleted {
+		return Record{}, false, ErrKeyInUse
+	}
+
+	return existing, true, nil
+}
+
+// Keeps the key claimed while the request is served, however long the
+// handler takes. The returned function stops extending the claim and must be
+// called before the request is completed or released.
+func (service *IdempotencyService) Hold(ctx context.Context, record Record) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(renewInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			record.ExpiresAt = time.Now().UTC().Add(pendingTimeout)
+
+			held, err := service.idempotencyRepository.ExtendClaim(ctx, record)
+			if err != nil {
+				log.Errorf("Failed to extend the claim of idempotency key %s", record.Id)
+				continue
+			}
+
+			if !held {
+				log.Warnf("Idempotency key %s is no longer claimed by its request", record.Id)
+				return
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// Keeps the response for the window, so retries get it instead of running
+// the request again
+func (service *IdempotencyService) Complete(ctx context.Context, record Record, status int, header map[string]string, body []byte) error {
+	log.Debugf("Saving the response of idempotency key %s . . .", record.Id)
+
+	record.State = StateCompleted
+	record.Status = status
+	record.Header = header
+	record.Body = body
+	record.ExpiresAt = time.Now().UTC().Add(service.window)
+
+	if err := service.idempotencyRepository.SaveResponse(ctx, record); err != nil {
+		log.Errorf("Failed to save the response of idempotency key %s", record.Id)
+		return err
+	}
+
+	return nil
+}
+
+// Frees the key of a request that failed, so a retry runs it again
+func (service *IdempotencyService) Release(ctx context.Context, record Record) error {
+	log.Debugf("Releasing idempotency key %s . . .", record.Id)
+
+	if err := service.idempotencyRepository.ReleaseKey(ctx, record.Id); err != nil {
+		log.Errorf("Failed to release idempotency key %s", record.Id)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package identity
+
+import "context"
+
+// Identity of the caller of a request, taken from its verified token
+type Identity struct {
+	UID      string
+	Email    string
+	TenantId string
+	Claims   map[string]interface{}
+	Roles    []string
+	APIKeyId string
+	Scopes   []string
+
+	// Set when an admin acts as this user, holds the UID of the admin
+	ImpersonatorId string
+}
+
+// Integrations calling with an API key are limited by scopes instead of roles
+func (ident Identity) IsAPIKey() bool {
+	return ident.APIKeyId != ""
+}
+
+func (ident Identity) IsImpersonated() bool {
+	return ident.ImpersonatorId != ""
+}
+
+func (ident Identity) HasScope(scope string) bool {
+	for _, held := range ident.Scopes {
+		if held == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (ident Identity) HasRole(roles ...string) bool {
+	for _, held := range ident.Roles {
+		for _, role := range roles {
+			if held == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, ident Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, ident)
+}
+
+func FromContext(ctx context.Context) (Identity, bool) {
+	ident, ok := ctx.Value(contextKey{}).(Identity)
+	return ident, ok
+}
@@ -0,0 +1,149 @@
+package messages
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/tenant"
+)
+
+type EventType string
+
+const (
+	EventCreated EventType = "message.created"
+	EventUpdated EventType = "message.updated"
+
+	// The AI response replaced the pending message
+	EventCompleted EventType = "message.completed"
+
+	// No AI response could be generated, the pending message says so instead
+	EventFailed EventType = "message.failed"
+)
+
+// A change to a message of a user. Events carry the tenant of the message,
+// since user ids are only unique within a tenant.
+type Event struct {
+	Type     EventType `json:"type"`
+	Message  Message   `json:"message"`
+	TenantId string    `json:"-"`
+}
+
+// Receives the changes the service makes to messages
+type EventPublisher interface {
+	Publish(event Event)
+}
+
+type EventSubscriber interface {
+	Subscribe(tenantId string, userId string) (<-chan Event, func())
+}
+
+// Where the service publishes its changes and hears about changes to the
+// messages it waits for
+type EventBus interface {
+	EventPublisher
+	EventSubscriber
+}
+
+// Events a subscriber may fall behind by before it is dropped
+const subscriberBuffer = 32
+
+// Delivers the events of a user to everyone on this instance who subscribed
+// to them
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: map[string]map[chan Event]struct{}{},
+	}
+}
+
+func subscriberKey(tenantId string, userId string) string {
+	return tenantId + "/" + userId
+}
+
+// Returns the events of the user and a function that ends the subscription.
+// The channel is closed when the subscription ends, including when the
+// subscriber falls behind, so it never silently misses an event.
+func (hub *Hub) Subscribe(tenantId string, userId string) (<-chan Event, func()) {
+	key := subscriberKey(tenantId, userId)
+	events := make(chan Event, subscriberBuffer)
+
+	hub.mu.Lock()
+	if hub.subscribers[key] == nil {
+		hub.subscribers[key] = map[chan Event]struct{}{}
+	}
+	hub.subscribers[key][events] = struct{}{}
+	hub.mu.Unlock()
+
+	return events, func() {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+
+		hub.remove(key, events)
+	}
+}
+
+// Must be called with the lock held
+func (hub *Hub) remove(key string, events chan Event) {
+	if _, ok := hub.subscribers[key][events]; !ok {
+		return
+	}
+
+	delete(hub.subscribers[key], events)
+	if len(hub.subscribers[key]) == 0 {
+		delete(hub.subscribers, key)
+	}
+
+	close(events)
+}
+
+func (hub *Hub) Publish(event Event) {
+	if event.Message.UserId == nil {
+		return
+	}
+
+	key := subscriberKey(event.TenantId, *event.Message.UserId)
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	for events := range hub.subscribers[key] {
+		select {
+		case events <- event:
+		default:
+			log.Errorf("Dropping a subscriber of user %s that fell behind", *event.Message.UserId)
+			hub.remove(key, events)
+		}
+	}
+}
+
+// A hub fed by a watch of the database, which sees the changes of every
+// instance. The service leaves publishing to the watch, so nothing is
+// delivered twice.
+func (hub *Hub) Watched() EventBus {
+	return watchedHub{hub}
+}
+
+type watchedHub struct {
+	*Hub
+}
+
+func (watchedHub) Publish(event Event) {}
+
+func (service *MessageService) publish(ctx context.Context, eventType EventType, message Message) {
+	if service.events == nil {
+		return
+	}
+
+	tenantId, _ := tenant.FromContext(ctx)
+
+	service.events.Publish(Event{
+		Type:     eventType,
+		Message:  message,
+		TenantId: tenantId,
+	})
+}
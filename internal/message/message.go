@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/tenant"
 	scopingaicommon "github.com/zzenonn/scoping-ai/pkg/common"
 )
 
@@ -32,23 +34,54 @@ func init() {
 }
 
 var (
-	ErrNotImplemented = errors.New("this function is not yet implemented")
+	ErrNotImplemented  = scopingaicommon.NewError(scopingaicommon.KindInternal, "this function is not yet implemented")
+	ErrVersionMismatch = scopingaicommon.NewError(scopingaicommon.KindPreconditionFailed, "message was modified since it was last read")
+	ErrNotFound        = scopingaicommon.NewError(scopingaicommon.KindNotFound, "message not found")
+	ErrNoAnswers       = scopingaicommon.NewError(scopingaicommon.KindValidation, "at least one answer is required")
+	ErrTooManyAnswers  = scopingaicommon.NewError(scopingaicommon.KindValidation, "too many answers in a single submission")
+	ErrInvalidAnswer   = scopingaicommon.NewError(scopingaicommon.KindValidation, "answer is missing required fields, such as the text of its question")
+	ErrPostingAnswers  = scopingaicommon.NewError(scopingaicommon.KindUpstream, "failed to save the answers, none of them were submitted")
+
+	ErrTechnologyNotAllowed = scopingaicommon.NewError(scopingaicommon.KindForbidden, "the organization of the user does not allow this technology")
 )
 
+// Answers and the pending message are written in a single Firestore batch,
+// which holds at most 500 writes
+const MaxAnswers = 499
+
+// Text of the message that stands in for the AI response until it arrives
+const PendingMessageText = "Thank you for your message. Please wait for the AI Engine to generate a response."
+
+// Text the pending message is replaced with when no AI response can be generated
+const FailedMessageText = "The AI Engine could not generate a response. Please submit your answers again."
+
 type Answer struct {
 	Question       *scopingaicommon.Question `json:"question,omitempty" firestore:"question,omitempty"`
-	TechnologyName *string                   `json:"technology_name,omitempty" firestore:"technology_name,omitempty"`
-	Answer         *string                   `json:"answer,omitempty" firestore:"answer,omitempty"`
+	TechnologyName *string                   `json:"technology_name,omitempty" firestore:"technology_name,omitempty" validate:"omitempty,max=100"`
+	Answer         *string                   `json:"answer,omitempty" firestore:"answer,omitempty" validate:"omitempty,max=5000"`
 }
 
 // Message representation
 type Message struct {
 	Id          string     `json:"id" firestore:"id"`
 	UserId      *string    `json:"user_id,omitempty" firestore:"-"`
-	MessageText *string    `json:"message_text,omitempty" firestore:"message_text,omitempty"`
-	Answer      *Answer    `json:"answer,omitempty" firestore:"answer,omitempty"`
+	MessageText *string    `json:"message_text,omitempty" firestore:"message_text,omitempty" validate:"required_without=Answer,omitempty,max=10000"`
+	Answer      *Answer    `json:"answer,omitempty" firestore:"answer,omitempty" validate:"required_without=MessageText"`
 	CreatedAt   *time.Time `json:"created_at,omitempty" firestore:"created_at,omitempty"`
 	UpdatedAt   *time.Time `json:"updated_at,omitempty" firestore:"updated_at,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" firestore:"deleted_at,omitempty"`
+	DeletedBy   *string    `json:"deleted_by,omitempty" firestore:"deleted_by,omitempty"`
+	Version     string     `json:"-" firestore:"-"`
+}
+
+// The AI response for the answers has not replaced the message yet
+func (message Message) IsPending() bool {
+	return message.MessageText != nil && *message.MessageText == PendingMessageText
+}
+
+// No AI response could be generated for the answers
+func (message Message) IsFailed() bool {
+	return message.MessageText != nil && *message.MessageText == FailedMessageText
 }
 
 type ChatCompletion struct {
@@ -80,28 +113,84 @@ type Usage struct {
 // Implements the message repository interface design pattern
 type MessageRepository interface {
 	GetMessage(ctx context.Context, messageId string, userId string) (Message, error)
-	GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int) ([]Message, error)
+	GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int, includeDeleted bool) ([]Message, error)
 	PostMessage(ctx context.Context, message Message) (Message, error)
+	PostMessages(ctx context.Context, messages []Message) ([]Message, error)
 	UpdateMessage(ctx context.Context, message Message) (Message, error)
 	DeleteMessage(ctx context.Context, messageId string, userId string) error
+	RestoreMessage(ctx context.Context, messageId string, userId string) error
+	PurgeDeletedMessages(ctx context.Context, before time.Time) (int, error)
 }
 
 type OpenAiRepository interface {
 	PostPrompt(ctx context.Context, aiContext string, prompt string) (ChatCompletion, error)
 }
 
+// Looks up the technologies the organization of a user allows it to answer
+// questions about. Nil allows every technology.
+type TechnologyPolicy interface {
+	AllowedTechnologies(ctx context.Context, userId string) ([]string, error)
+}
+
 type MessageService struct {
 	messageRepository MessageRepository
 	openAiRepository  OpenAiRepository
+	events            EventBus
+	technologies      TechnologyPolicy
 }
 
-func NewMessageService(messageRepository MessageRepository, openAiRepository OpenAiRepository) *MessageService {
+// Changes to messages are published to events. Without events, waiting for a
+// message returns it as it is. Without a technology policy, answers may be
+// about any technology.
+func NewMessageService(messageRepository MessageRepository, openAiRepository OpenAiRepository, events EventBus, technologies TechnologyPolicy) *MessageService {
 	return &MessageService{
 		messageRepository: messageRepository,
 		openAiRepository:  openAiRepository,
+		events:            events,
+		technologies:      technologies,
 	}
 }
 
+// Rejects answers about technologies the organization of the user doesn't
+// train its learners on
+func (service *MessageService) checkTechnologies(ctx context.Context, answers []Message) error {
+	if service.technologies == nil || answers[0].UserId == nil {
+		return nil
+	}
+
+	allowed, err := service.technologies.AllowedTechnologies(ctx, *answers[0].UserId)
+	if err != nil {
+		return err
+	}
+	if allowed == nil {
+		return nil
+	}
+
+	for _, answer := range answers {
+		if answer.Answer == nil || answer.Answer.TechnologyName == nil {
+			continue
+		}
+
+		if !containsFold(allowed, *answer.Answer.TechnologyName) {
+			return scopingaicommon.WithDetails(ErrTechnologyNotAllowed, map[string]interface{}{
+				"technology_name": *answer.Answer.TechnologyName,
+			})
+		}
+	}
+
+	return nil
+}
+
+// Technology names are matched like the unique names of question sets
+func containsFold(values []string, value string) bool {
+	for _, held := range values {
+		if strings.EqualFold(strings.TrimSpace(held), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
+
 func (service *MessageService) PostMessage(ctx context.Context, message Message) (Message, error) {
 	log.Debug("Posting message . . .")
 
@@ -114,10 +203,14 @@ func (service *MessageService) PostMessage(ctx context.Context, message Message)
 		return Message{}, err
 	}
 
+	service.publish(ctx, EventCreated, postedMessage)
+
 	return postedMessage, nil
 }
 
-func (service *MessageService) promptOpenAi(postedMessages []Message, responseMessageId string) (Message, error) {
+// Runs after the request has finished, so ctx must not carry its cancellation.
+// It still carries the tenant the response is written to.
+func (service *MessageService) promptOpenAi(ctx context.Context, postedMessages []Message, responseMessageId string) (Message, error) {
 	log.Debug("Prompting the Open AI API . . .")
 
 	var promptBuilder strings.Builder
@@ -145,7 +238,7 @@ func (service *MessageService) promptOpenAi(postedMessages []Message, responseMe
 	Do not ask for any additional feedback or elaboration from the customer.`
 
 	for _, msg := range postedMessages {
-		if msg.Answer != nil && msg.Answer.Question != nil && msg.Answer.Answer != nil {
+		if msg.Answer != nil && msg.Answer.Question != nil && msg.Answer.Question.Text != nil && msg.Answer.Answer != nil {
 			promptBuilder.WriteString("Question: ")
 			promptBuilder.WriteString(*msg.Answer.Question.Text)
 			promptBuilder.WriteString("\nAnswer: ")
@@ -158,11 +251,11 @@ func (service *MessageService) promptOpenAi(postedMessages []Message, responseMe
 
 	prompt := promptBuilder.String()
 
-	chatCompletion, err := service.openAiRepository.PostPrompt(context.Background(), aiContext, prompt)
+	chatCompletion, err := service.openAiRepository.PostPrompt(ctx, aiContext, prompt)
 
 	if err != nil {
-		log.Error("Failed to prompt Open AI API")
-		return Message{}, err
+		log.Errorf("Failed to prompt Open AI API for pending message %s. Error: %v", responseMessageId, err)
+		return service.failPendingMessage(ctx, postedMessages[0].UserId, responseMessageId, err)
 	}
 
 	var message Message
@@ -173,51 +266,98 @@ func (service *MessageService) promptOpenAi(postedMessages []Message, responseMe
 	jsonData, err := json.Marshal(chatCompletion)
 	if err != nil {
 		log.Error("Error marshaling struct")
-		return Message{}, err
+		return service.failPendingMessage(ctx, message.UserId, responseMessageId, err)
 	}
 
 	jsonString := string(jsonData)
 
 	message.MessageText = &jsonString
 
-	completionMessage, err := service.UpdateMessage(context.Background(), message)
+	completionMessage, err := service.updateMessage(ctx, message, EventCompleted)
+	if err != nil {
+		return Message{}, err
+	}
 
 	return completionMessage, nil
 }
 
+// Replaces the pending message when no AI response can be generated, so
+// clients waiting for it stop waiting. Returns the error of the response.
+func (service *MessageService) failPendingMessage(ctx context.Context, userId *string, responseMessageId string, cause error) (Message, error) {
+	failedText := FailedMessageText
+
+	message := Message{
+		Id:          responseMessageId,
+		UserId:      userId,
+		MessageText: &failedText,
+	}
+
+	if _, err := service.updateMessage(ctx, message, EventFailed); err != nil {
+		log.Errorf("Failed to mark pending message %s as failed", responseMessageId)
+	}
+
+	return Message{}, cause
+}
+
+// Saves the answers together with a pending message that is later replaced by
+// the AI response. Either all of them are saved or none are.
 func (service *MessageService) PostAnswers(ctx context.Context, messages []Message) (Message, error) {
 	log.Debug("Posting multiple answers...")
 
-	postedMessages := make([]Message, 0, len(messages))
+	if len(messages) == 0 {
+		return Message{}, ErrNoAnswers
+	}
 
-	for _, message := range messages {
-		message.Id = uuid.New().String()
-		postedMessage, err := service.PostMessage(ctx, message)
-		if err != nil {
-			log.Errorf("Failed to post message with ID %s. Error: %v", message.Id, err)
+	if len(messages) > MaxAnswers {
+		return Message{}, ErrTooManyAnswers
+	}
 
-			continue
+	if err := service.checkTechnologies(ctx, messages); err != nil {
+		log.Error("Failed to check the technologies of the answers")
+		return Message{}, err
+	}
+
+	answers := make([]Message, 0, len(messages))
+
+	for i, message := range messages {
+		// Every answer is sent to the model with the text of its question
+		if message.Answer == nil || message.Answer.Question == nil || message.Answer.Question.Text == nil {
+			return Message{}, scopingaicommon.WithDetails(ErrInvalidAnswer, map[string]interface{}{"index": i})
 		}
-		postedMessages = append(postedMessages, postedMessage)
+
+		message.Id = uuid.New().String()
+		answers = append(answers, message)
 	}
 
-	messagePending := "Thank you for your message. Please wait for the AI Engine to generate a response."
+	messagePending := PendingMessageText
 
 	pendingMessage := Message{
 		Id:          uuid.New().String(),
-		UserId:      postedMessages[0].UserId,
+		UserId:      answers[0].UserId,
 		MessageText: &messagePending,
 	}
 
-	postedPendingMessage, err := service.PostMessage(ctx, pendingMessage)
+	postedMessages, err := service.messageRepository.PostMessages(ctx, append(answers, pendingMessage))
 
-	if err != nil {
-		log.Errorf("Failed to post pending message with ID %s. Error: %v", pendingMessage.Id, err)
+	if errors.Is(err, ErrInvalidAnswer) {
+		log.Errorf("Rejected answers for pending message %s. Error: %v", pendingMessage.Id, err)
 		return Message{}, err
 	}
 
+	if err != nil {
+		log.Errorf("Failed to post answers for pending message %s. Error: %v", pendingMessage.Id, err)
+		return Message{}, fmt.Errorf("%w: %v", ErrPostingAnswers, err)
+	}
+
+	postedAnswers := postedMessages[:len(postedMessages)-1]
+	postedPendingMessage := postedMessages[len(postedMessages)-1]
+
+	for _, postedMessage := range postedMessages {
+		service.publish(ctx, EventCreated, postedMessage)
+	}
+
 	defer func() {
-		go service.promptOpenAi(postedMessages, postedPendingMessage.Id)
+		go service.promptOpenAi(context.WithoutCancel(ctx), postedAnswers, postedPendingMessage.Id)
 	}()
 
 	log.Debug("Completed posting messages.")
@@ -236,10 +376,49 @@ func (service *MessageService) GetMessage(ctx context.Context, messageId string,
 
 	return message, nil
 }
-func (service *MessageService) GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int) ([]Message, error) {
+
+// Returns the message once it is no longer pending, or as it is when it is
+// still pending after the wait. Subscribing before the message is read makes
+// sure a completion in between isn't missed.
+func (service *MessageService) WaitForMessage(ctx context.Context, messageId string, userId string, wait time.Duration) (Message, error) {
+	log.Debugf("Waiting up to %s for message %s of user %s . . .", wait, messageId, userId)
+
+	if service.events == nil || wait <= 0 {
+		return service.GetMessage(ctx, messageId, userId)
+	}
+
+	tenantId, _ := tenant.FromContext(ctx)
+
+	events, unsubscribe := service.events.Subscribe(tenantId, userId)
+	defer unsubscribe()
+
+	message, err := service.GetMessage(ctx, messageId, userId)
+	if err != nil || !message.IsPending() {
+		return message, err
+	}
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-timeout.C:
+			return message, nil
+		case event, ok := <-events:
+			// A dropped subscription may have missed the completion
+			if !ok || (event.Message.Id == messageId && !event.Message.IsPending()) {
+				return service.GetMessage(ctx, messageId, userId)
+			}
+		}
+	}
+}
+
+func (service *MessageService) GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int, includeDeleted bool) ([]Message, error) {
 	log.Debug("Retreiving all course messages . . .")
 
-	messages, err := service.messageRepository.GetAllUserMessages(ctx, userId, page, pageSize)
+	messages, err := service.messageRepository.GetAllUserMessages(ctx, userId, page, pageSize, includeDeleted)
 
 	if err != nil {
 		log.Error("Failed to retrieve all messages")
@@ -249,6 +428,10 @@ func (service *MessageService) GetAllUserMessages(ctx context.Context, userId st
 	return messages, nil
 }
 func (service *MessageService) UpdateMessage(ctx context.Context, message Message) (Message, error) {
+	return service.updateMessage(ctx, message, EventUpdated)
+}
+
+func (service *MessageService) updateMessage(ctx context.Context, message Message, eventType EventType) (Message, error) {
 	log.Debugf("Updating message %s", message.Id)
 
 	updatedMessage, err := service.messageRepository.UpdateMessage(ctx, message)
@@ -258,9 +441,12 @@ func (service *MessageService) UpdateMessage(ctx context.Context, message Messag
 		return Message{}, err
 	}
 
+	service.publish(ctx, eventType, updatedMessage)
+
 	return updatedMessage, nil
 }
 
+// Soft deletes the message. It can be restored until it is purged.
 func (service *MessageService) DeleteMessage(ctx context.Context, messageId string, userId string) error {
 	log.Debugf("Deleting message %s from user %s. . .", messageId, userId)
 
@@ -273,3 +459,30 @@ func (service *MessageService) DeleteMessage(ctx context.Context, messageId stri
 
 	return nil
 }
+
+func (service *MessageService) RestoreMessage(ctx context.Context, messageId string, userId string) error {
+	log.Debugf("Restoring message %s from user %s. . .", messageId, userId)
+
+	err := service.messageRepository.RestoreMessage(ctx, messageId, userId)
+
+	if err != nil {
+		log.Errorf("Failed to restore message %s from user %s", messageId, userId)
+		return err
+	}
+
+	return nil
+}
+
+// Permanently removes messages that were deleted before the given time
+func (service *MessageService) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	log.Debug("Purging deleted messages . . .")
+
+	purged, err := service.messageRepository.PurgeDeletedMessages(ctx, before)
+
+	if err != nil {
+		log.Error("Failed to purge deleted messages")
+		return purged, err
+	}
+
+	return purged, nil
+}
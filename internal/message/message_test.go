@@ -0,0 +1,170 @@
+package messages
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Keeps the messages of a single user in memory
+type memoryRepository struct {
+	messages map[string]Message
+	failing  bool
+}
+
+func (repo *memoryRepository) GetMessage(ctx context.Context, messageId string, userId string) (Message, error) {
+	message, ok := repo.messages[messageId]
+	if !ok {
+		return Message{}, ErrNotFound
+	}
+	return message, nil
+}
+
+func (repo *memoryRepository) GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int, includeDeleted bool) ([]Message, error) {
+	return nil, ErrNotImplemented
+}
+
+func (repo *memoryRepository) PostMessage(ctx context.Context, message Message) (Message, error) {
+	repo.messages[message.Id] = message
+	return message, nil
+}
+
+func (repo *memoryRepository) PostMessages(ctx context.Context, messages []Message) ([]Message, error) {
+	for _, message := range messages {
+		repo.messages[message.Id] = message
+	}
+	return messages, nil
+}
+
+func (repo *memoryRepository) UpdateMessage(ctx context.Context, message Message) (Message, error) {
+	if repo.failing {
+		return Message{}, errors.New("database unavailable")
+	}
+	if _, ok := repo.messages[message.Id]; !ok {
+		return Message{}, ErrNotFound
+	}
+	repo.messages[message.Id] = message
+	return message, nil
+}
+
+func (repo *memoryRepository) DeleteMessage(ctx context.Context, messageId string, userId string) error {
+	return ErrNotImplemented
+}
+
+func (repo *memoryRepository) RestoreMessage(ctx context.Context, messageId string, userId string) error {
+	return ErrNotImplemented
+}
+
+func (repo *memoryRepository) PurgeDeletedMessages(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrNotImplemented
+}
+
+type stubOpenAi struct {
+	err error
+}
+
+func (s stubOpenAi) PostPrompt(ctx context.Context, aiContext string, prompt string) (ChatCompletion, error) {
+	if s.err != nil {
+		return ChatCompletion{}, s.err
+	}
+	return ChatCompletion{Id: "completion"}, nil
+}
+
+func TestPromptOpenAi(t *testing.T) {
+	errUpstream := errors.New("model unavailable")
+
+	tests := []struct {
+		name      string
+		openAi    stubOpenAi
+		failing   bool
+		wantErr   error
+		wantEvent EventType
+		wantText  string
+	}{
+		{"completed", stubOpenAi{}, false, nil, EventCompleted, ""},
+		{"model fails", stubOpenAi{err: errUpstream}, false, errUpstream, EventFailed, FailedMessageText},
+		{"saving the response fails", stubOpenAi{}, true, errors.New("database unavailable"), "", PendingMessageText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userId := "user-1"
+			text, pending := "How often?", PendingMessageText
+
+			answer := Message{Id: "answer", UserId: &userId, Answer: &Answer{Answer: &text}}
+
+			repo := &memoryRepository{messages: map[string]Message{
+				"answer":  answer,
+				"pending": {Id: "pending", UserId: &userId, MessageText: &pending},
+			}}
+
+			hub := NewHub()
+			events, unsubscribe := hub.Subscribe("", userId)
+			defer unsubscribe()
+
+			service := NewMessageService(repo, tt.openAi, hub, nil)
+
+			repo.failing = tt.failing
+			_, err := service.promptOpenAi(context.Background(), []Message{answer}, "pending")
+
+			if (err == nil) != (tt.wantErr == nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			select {
+			case event := <-events:
+				if event.Type != tt.wantEvent {
+					t.Errorf("got event %s, want %s", event.Type, tt.wantEvent)
+				}
+			default:
+				if tt.wantEvent != "" {
+					t.Errorf("no event, want %s", tt.wantEvent)
+				}
+			}
+
+			stored := repo.messages["pending"]
+			if stored.IsPending() && tt.wantText != PendingMessageText {
+				t.Error("the message is still pending")
+			}
+			if tt.wantText != "" && *stored.MessageText != tt.wantText {
+				t.Errorf("got message text %q, want %q", *stored.MessageText, tt.wantText)
+			}
+		})
+	}
+}
+
+type allowedTechnologies []string
+
+func (allowed allowedTechnologies) AllowedTechnologies(ctx context.Context, userId string) ([]string, error) {
+	return allowed, nil
+}
+
+func TestCheckTechnologies(t *testing.T) {
+	userId := "user-1"
+	answer := func(technology string) Message {
+		return Message{UserId: &userId, Answer: &Answer{TechnologyName: &technology}}
+	}
+
+	tests := []struct {
+		name    string
+		allowed allowedTechnologies
+		answers []Message
+		wantErr error
+	}{
+		{"every technology allowed", nil, []Message{answer("Azure")}, nil},
+		{"allowed technology", allowedTechnologies{"AWS", "GCP"}, []Message{answer("AWS")}, nil},
+		{"other case and spacing", allowedTechnologies{"AWS"}, []Message{answer(" aws ")}, nil},
+		{"technology not allowed", allowedTechnologies{"AWS"}, []Message{answer("AWS"), answer("Azure")}, ErrTechnologyNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewMessageService(&memoryRepository{}, stubOpenAi{}, nil, tt.allowed)
+
+			if err := service.checkTechnologies(context.Background(), tt.answers); !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package Organizations
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+var (
+	ErrVersionMismatch = common.NewError(common.KindPreconditionFailed, "organization was modified since it was last read")
+	ErrNotFound        = common.NewError(common.KindNotFound, "organization not found")
+	ErrMemberNotFound  = common.NewError(common.KindNotFound, "member not found")
+	ErrUserNotFound    = common.NewError(common.KindNotFound, "user not found")
+	ErrDuplicateName   = common.NewError(common.KindConflict, "an organization with this name already exists")
+	ErrMissingName     = common.NewError(common.KindValidation, "organization name is required")
+	ErrInvalidRole     = common.NewError(common.KindValidation, "invalid member role")
+	ErrOtherMembership = common.NewError(common.KindConflict, "user is a member of another organization")
+)
+
+// Roles a member holds within its organization
+const (
+	MemberRoleManager = "manager"
+	MemberRoleLearner = "learner"
+)
+
+// Organization-wide settings applied to its learners
+type Settings struct {
+	AllowedTechnologies  []string `json:"allowed_technologies,omitempty" firestore:"allowed_technologies,omitempty" validate:"omitempty,dive,max=100"`
+	DefaultQuestionSetId *string  `json:"default_question_set_id,omitempty" firestore:"default_question_set_id,omitempty"`
+}
+
+// A corporate client of the training provider
+type Organization struct {
+	Id        string     `json:"id,omitempty" firestore:"id,omitempty"`
+	Name      *string    `json:"name,omitempty" firestore:"name,omitempty" validate:"required,max=200"`
+	Settings  *Settings  `json:"settings,omitempty" firestore:"settings,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" firestore:"deleted_at,omitempty"`
+	DeletedBy *string    `json:"deleted_by,omitempty" firestore:"deleted_by,omitempty"`
+	Version   string     `json:"-" firestore:"-"`
+}
+
+// Membership of a user in an organization
+type Member struct {
+	UserId   string     `json:"user_id" firestore:"user_id"`
+	Role     string     `json:"role" firestore:"role" validate:"omitempty,oneof=manager learner"`
+	JoinedAt *time.Time `json:"joined_at,omitempty" firestore:"joined_at,omitempty"`
+}
+
+type OrganizationRepository interface {
+	PostOrganization(ctx context.Context, org Organization) (Organization, error)
+	GetOrganization(ctx context.Context, id string) (Organization, error)
+	GetAllOrganizations(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]Organization, error)
+	UpdateOrganization(ctx context.Context, org Organization) (Organization, error)
+	DeleteOrganization(ctx context.Context, id string) error
+	RestoreOrganization(ctx context.Context, id string) error
+	PurgeDeletedOrganizations(ctx context.Context, before time.Time) (int, error)
+	GetMembers(ctx context.Context, orgId string) ([]Member, error)
+	PutMember(ctx context.Context, orgId string, member Member) (Member, error)
+	RemoveMember(ctx context.Context, orgId string, userId string) error
+	GetUserOrganization(ctx context.Context, userId string) (Organization, error)
+}
+
+type OrganizationService struct {
+	organizationRepository OrganizationRepository
+}
+
+func NewOrganizationService(organizationRepository OrganizationRepository) *OrganizationService {
+	return &OrganizationService{organizationRepository: organizationRepository}
+}
+
+func (service *OrganizationService) PostOrganization(ctx context.Context, org Organization) (Organization, error) {
+	log.Debug("Posting organization . . .")
+
+	if org.Name == nil || strings.TrimSpace(*org.Name) == "" {
+		return Organization{}, ErrMissingName
+	}
+
+	org.Id = uuid.New().String()
+
+	postedOrg, err := service.organizationRepository.PostOrganization(ctx, org)
+
+	if err != nil {
+		log.Error("Failed to post organization")
+		return Organization{}, err
+	}
+
+	return postedOrg, nil
+}
+
+func (service *OrganizationService) GetOrganization(ctx context.Context, id string) (Organization, error) {
+	log.Debug("Retrieving organization by id . . .")
+
+	org, err := service.organizationRepository.GetOrganization(ctx, id)
+
+	if err != nil {
+		log.Error("Failed to retrieve organization by id")
+		return Organization{}, err
+	}
+
+	return org, nil
+}
+
+func (service *OrganizationService) GetAllOrganizations(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]Organization, error) {
+	log.Debug("Retrieving all organizations . . .")
+
+	orgs, err := service.organizationRepository.GetAllOrganizations(ctx, page, pageSize, includeDeleted)
+
+	if err != nil {
+		log.Error("Failed to retrieve all organizations")
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+func (service *OrganizationService) UpdateOrganization(ctx context.Context, org Organization) (Organization, error) {
+	log.Debug("Updating organization . . .")
+
+	if org.Name == nil || strings.TrimSpace(*org.Name) == "" {
+		return Organization{}, ErrMissingName
+	}
+
+	updatedOrg, err := service.organizationRepository.UpdateOrganization(ctx, org)
+
+	if err != nil {
+		log.Error("Failed to update organization")
+		return Organization{}, err
+	}
+
+	return updatedOrg, nil
+}
+
+// Soft deletes the organization. It can be restored until it is purged.
+func (service *OrganizationService) DeleteOrganization(ctx context.Context, id string) error {
+	log.Debug("Deleting organization . . .")
+
+	err := service.organizationRepository.DeleteOrganization(ctx, id)
+
+	if err != nil {
+		log.Error("Failed to delete organization")
+		return err
+	}
+
+	return nil
+}
+
+func (service *OrganizationService) RestoreOrganization(ctx context.Context, id string) error {
+	log.Debug("Restoring organization . . .")
+
+	err := service.organizationRepository.RestoreOrganization(ctx, id)
+
+	if err != nil {
+		log.Error("Failed to restore organization")
+		return err
+	}
+
+	return nil
+}
+
+// Permanently removes organizations that were deleted before the given time
+func (service *OrganizationService) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	log.Debug("Purging deleted organizations . . .")
+
+	purged, err := service.organizationRepository.PurgeDeletedOrganizations(ctx, before)
+
+	if err != nil {
+		log.Error("Failed to purge deleted organizations")
+		return purged, err
+	}
+
+	return purged, nil
+}
+
+func (service *OrganizationService) GetMembers(ctx context.Context, orgId string) ([]Member, error) {
+	log.Debug("Retrieving organization members . . .")
+
+	members, err := service.organizationRepository.GetMembers(ctx, orgId)
+
+	if err != nil {
+		log.Error("Failed to retrieve organization members")
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// Adds a user to the organization or changes its role. The user is linked to
+// the organization through its organization id.
+func (service *OrganizationService) PutMember(ctx context.Context, orgId string, member Member) (Member, error) {
+	log.Debug("Putting organization member . . .")
+
+	if member.Role == "" {
+		member.Role = MemberRoleLearner
+	}
+
+	if member.Role != MemberRoleManager && member.Role != MemberRoleLearner {
+		return Member{}, ErrInvalidRole
+	}
+
+	putMember, err := service.organizationRepository.PutMember(ctx, orgId, member)
+
+	if err != nil {
+		log.Error("Failed to put organization member")
+		return Member{}, err
+	}
+
+	return putMember, nil
+}
+
+func (service *OrganizationService) RemoveMember(ctx context.Context, orgId string, userId string) error {
+	log.Debug("Removing organization member . . .")
+
+	err := service.organizationRepository.RemoveMember(ctx, orgId, userId)
+
+	if err != nil {
+		log.Error("Failed to remove organization member")
+		return err
+	}
+
+	return nil
+}
+
+// Returns the technologies the organization of the user allows, or nil when
+// the user is outside of any organization or it allows every technology
+func (service *OrganizationService) AllowedTechnologies(ctx context.Context, userId string) ([]string, error) {
+	log.Debug("Retrieving allowed technologies . . .")
+
+	org, err := service.organizationRepository.GetUserOrganization(ctx, userId)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error("Failed to retrieve allowed technologies")
+		return nil, err
+	}
+
+	if org.Settings == nil || len(org.Settings.AllowedTechnologies) == 0 {
+		return nil, nil
+	}
+
+	return org.Settings.AllowedTechnologies, nil
+}
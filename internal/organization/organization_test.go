@@ -0,0 +1,133 @@
+package Organizations
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Keeps a single organization and its members in memory
+type memoryRepository struct {
+	org     *Organization
+	members map[string]Member
+}
+
+func (repo *memoryRepository) PostOrganization(ctx context.Context, org Organization) (Organization, error) {
+	repo.org = &org
+	return org, nil
+}
+
+func (repo *memoryRepository) GetOrganization(ctx context.Context, id string) (Organization, error) {
+	if repo.org == nil || repo.org.Id != id {
+		return Organization{}, ErrNotFound
+	}
+	return *repo.org, nil
+}
+
+func (repo *memoryRepository) GetAllOrganizations(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]Organization, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (repo *memoryRepository) UpdateOrganization(ctx context.Context, org Organization) (Organization, error) {
+	return Organization{}, errors.New("not implemented")
+}
+
+func (repo *memoryRepository) DeleteOrganization(ctx context.Context, id string) error {
+	return errors.New("not implemented")
+}
+
+func (repo *memoryRepository) RestoreOrganization(ctx context.Context, id string) error {
+	return errors.New("not implemented")
+}
+
+func (repo *memoryRepository) PurgeDeletedOrganizations(ctx context.Context, before time.Time) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (repo *memoryRepository) GetMembers(ctx context.Context, orgId string) ([]Member, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (repo *memoryRepository) PutMember(ctx context.Context, orgId string, member Member) (Member, error) {
+	repo.members[member.UserId] = member
+	return member, nil
+}
+
+func (repo *memoryRepository) RemoveMember(ctx context.Context, orgId string, userId string) error {
+	return errors.New("not implemented")
+}
+
+func (repo *memoryRepository) GetUserOrganization(ctx context.Context, userId string) (Organization, error) {
+	if _, ok := repo.members[userId]; !ok || repo.org == nil {
+		return Organization{}, ErrNotFound
+	}
+	return *repo.org, nil
+}
+
+func TestPutMember(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		wantRole string
+		wantErr  error
+	}{
+		{"learner by default", "", MemberRoleLearner, nil},
+		{"manager", MemberRoleManager, MemberRoleManager, nil},
+		{"unknown role", "owner", "", ErrInvalidRole},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryRepository{members: map[string]Member{}}
+
+			member, err := NewOrganizationService(repo).PutMember(context.Background(), "org-1", Member{UserId: "user-1", Role: tt.role})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if member.Role != tt.wantRole {
+				t.Errorf("got role %q, want %q", member.Role, tt.wantRole)
+			}
+			if _, stored := repo.members["user-1"]; stored != (tt.wantErr == nil) {
+				t.Errorf("member stored = %v, want %v", stored, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestAllowedTechnologies(t *testing.T) {
+	name := "Acme"
+
+	tests := []struct {
+		name     string
+		settings *Settings
+		member   bool
+		want     []string
+	}{
+		{"outside of any organization", &Settings{AllowedTechnologies: []string{"AWS"}}, false, nil},
+		{"no settings", nil, true, nil},
+		{"every technology", &Settings{}, true, nil},
+		{"some technologies", &Settings{AllowedTechnologies: []string{"AWS", "GCP"}}, true, []string{"AWS", "GCP"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryRepository{
+				org:     &Organization{Id: "org-1", Name: &name, Settings: tt.settings},
+				members: map[string]Member{},
+			}
+			if tt.member {
+				repo.members["user-1"] = Member{UserId: "user-1", Role: MemberRoleLearner}
+			}
+
+			got, err := NewOrganizationService(repo).AllowedTechnologies(context.Background(), "user-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,13 +2,14 @@ package CourseOutlines
 
 import (
 	"context"
-	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/pkg/common"
 )
 
 func init() {
@@ -32,25 +33,36 @@ func init() {
 const COLLECTION_NAME = "course_outlines"
 
 var (
-	ErrFetchingOutline = errors.New("failed to fetch TNA questions by technology")
-	ErrNotImplemented  = errors.New("this function is not yet implemented")
+	ErrFetchingOutline     = common.NewError(common.KindUpstream, "failed to fetch TNA questions by technology")
+	ErrNotImplemented      = common.NewError(common.KindInternal, "this function is not yet implemented")
+	ErrVersionMismatch     = common.NewError(common.KindPreconditionFailed, "course outline was modified since it was last read")
+	ErrNotFound            = common.NewError(common.KindNotFound, "course outline not found")
+	ErrDuplicateCourseCode = common.NewError(common.KindConflict, "a course outline with this course code already exists")
 )
 
 type CourseOutline struct {
-	Id             string  `json:"id,omitempty" firestore:"id,omitempty"`
-	TechnologyName *string `json:"technology_name,omitempty" firestore:"technology_name,omitempty"`
-	CourseCode     *string `json:"course_code,omitempty" firestore:"course_code,omitempty"`
-	CourseName     *string `json:"course_name,omitempty" firestore:"course_name,omitempty"`
-	Outline        *string `json:"outline,omitempty" firestore:"outline,omitempty"`
+	Id             string     `json:"id,omitempty" firestore:"id,omitempty"`
+	TechnologyName *string    `json:"technology_name,omitempty" firestore:"technology_name,omitempty" validate:"required,max=100"`
+	CourseCode     *string    `json:"course_code,omitempty" firestore:"course_code,omitempty" validate:"required,max=50"`
+	CourseName     *string    `json:"course_name,omitempty" firestore:"course_name,omitempty" validate:"required,max=200"`
+	Outline        *string    `json:"outline,omitempty" firestore:"outline,omitempty" validate:"omitempty,max=100000"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" firestore:"deleted_at,omitempty"`
+	DeletedBy      *string    `json:"deleted_by,omitempty" firestore:"deleted_by,omitempty"`
+	Version        string     `json:"-" firestore:"-"`
 }
 
 type CourseOutlineRepository interface {
 	PostCourseOutline(ctx context.Context, courseOutline CourseOutline) (CourseOutline, error)
 	GetCourseOutline(ctx context.Context, id string) (CourseOutline, error)
-	GetAllCourseOutlines(ctx context.Context, page int, pageSize int) ([]CourseOutline, error)
-	GetCourseOutlinesByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string) ([]CourseOutline, error)
+	GetAllCourseOutlines(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]CourseOutline, error)
+	GetCourseOutlinesByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]CourseOutline, error)
+	GetCourseOutlinesByTechnologyNames(ctx context.Context, technologyNames []string) ([]CourseOutline, error)
 	UpdateCourseOutline(ctx context.Context, courseOutline CourseOutline) (CourseOutline, error)
 	DeleteCourseOutline(ctx context.Context, id string) error
+	RestoreCourseOutline(ctx context.Context, id string) error
+	PurgeDeletedCourseOutlines(ctx context.Context, before time.Time) (int, error)
+	ImportCourseOutlines(ctx context.Context, courseOutlines []CourseOutline, dryRun bool) ([]common.ImportResult, error)
+	ExportCourseOutlines(ctx context.Context, each func(CourseOutline) error) error
 }
 
 type CourseOutlineService struct {
@@ -93,10 +105,10 @@ func (service *CourseOutlineService) GetCourseOutline(ctx context.Context, id st
 // e.g. GetCourseOutlineByFilter(ctx, "technology_name", "Java")
 // Can also be used to get course outline by course code.
 // e.g. GetCourseOutlineByFilter(ctx, "course_code", "JAV101")
-func (service *CourseOutlineService) GetCourseOutlinesByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string) ([]CourseOutline, error) {
+func (service *CourseOutlineService) GetCourseOutlinesByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]CourseOutline, error) {
 	log.Debug("Retreiving all course outlines by filter . . .")
 
-	courseOutlines, err := service.courseOutlineRepository.GetCourseOutlinesByFilter(ctx, page, pageSize, filterName, filterValue)
+	courseOutlines, err := service.courseOutlineRepository.GetCourseOutlinesByFilter(ctx, page, pageSize, filterName, filterValue, includeDeleted)
 
 	if err != nil {
 		log.Error("Failed to retrieve course outlines by filter")
@@ -106,10 +118,24 @@ func (service *CourseOutlineService) GetCourseOutlinesByFilter(ctx context.Conte
 	return courseOutlines, nil
 }
 
-func (service *CourseOutlineService) GetAllCourseOutlines(ctx context.Context, page int, pageSize int) ([]CourseOutline, error) {
+// Returns the course outlines of several technologies at once
+func (service *CourseOutlineService) GetCourseOutlinesByTechnologyNames(ctx context.Context, technologyNames []string) ([]CourseOutline, error) {
+	log.Debug("Retreiving course outlines by technology names . . .")
+
+	courseOutlines, err := service.courseOutlineRepository.GetCourseOutlinesByTechnologyNames(ctx, technologyNames)
+
+	if err != nil {
+		log.Error("Failed to retrieve course outlines by technology names")
+		return nil, err
+	}
+
+	return courseOutlines, nil
+}
+
+func (service *CourseOutlineService) GetAllCourseOutlines(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]CourseOutline, error) {
 	log.Debug("Retreiving all course outlines . . .")
 
-	courseOutlines, err := service.courseOutlineRepository.GetAllCourseOutlines(ctx, page, pageSize)
+	courseOutlines, err := service.courseOutlineRepository.GetAllCourseOutlines(ctx, page, pageSize, includeDeleted)
 
 	if err != nil {
 		log.Error("Failed to retrieve all course outlines")
@@ -132,6 +158,7 @@ func (service *CourseOutlineService) UpdateCourseOutline(ctx context.Context, co
 	return updatedCourseOutline, nil
 }
 
+// Soft deletes the course outline. It can be restored until it is purged.
 func (service *CourseOutlineService) DeleteCourseOutline(ctx context.Context, id string) error {
 	log.Debug("Deleting course outline . . .")
 
@@ -144,3 +171,64 @@ func (service *CourseOutlineService) DeleteCourseOutline(ctx context.Context, id
 
 	return nil
 }
+
+func (service *CourseOutlineService) RestoreCourseOutline(ctx context.Context, id string) error {
+	log.Debug("Restoring course outline . . .")
+
+	err := service.courseOutlineRepository.RestoreCourseOutline(ctx, id)
+
+	if err != nil {
+		log.Error("Failed to restore course outline")
+		return err
+	}
+
+	return nil
+}
+
+// Permanently removes course outlines that were deleted before the given time
+func (service *CourseOutlineService) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	log.Debug("Purging deleted course outlines . . .")
+
+	purged, err := service.courseOutlineRepository.PurgeDeletedCourseOutlines(ctx, before)
+
+	if err != nil {
+		log.Error("Failed to purge deleted course outlines")
+		return purged, err
+	}
+
+	return purged, nil
+}
+
+// Creates or updates the course outlines by course code. Soft deleted outlines
+// with the same course code are restored. Results follow the order of the
+// outlines, with the rows that failed among them.
+func (service *CourseOutlineService) ImportCourseOutlines(ctx context.Context, courseOutlines []CourseOutline, dryRun bool) ([]common.ImportResult, error) {
+	log.Debugf("Importing %d course outlines . . .", len(courseOutlines))
+
+	for i := range courseOutlines {
+		courseOutlines[i].Id = uuid.New().String()
+	}
+
+	results, err := service.courseOutlineRepository.ImportCourseOutlines(ctx, courseOutlines, dryRun)
+
+	if err != nil {
+		log.Error("Failed to import course outlines")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Calls each with every course outline that isn't deleted
+func (service *CourseOutlineService) ExportCourseOutlines(ctx context.Context, each func(CourseOutline) error) error {
+	log.Debug("Exporting course outlines . . .")
+
+	err := service.courseOutlineRepository.ExportCourseOutlines(ctx, each)
+
+	if err != nil {
+		log.Error("Failed to export course outlines")
+		return err
+	}
+
+	return nil
+}
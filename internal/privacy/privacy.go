@@ -0,0 +1,124 @@
+package privacy
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+var (
+	ErrNotFound = common.NewError(common.KindNotFound, "no data is held for this user")
+)
+
+// Audit record of a completed erasure. It deliberately holds no personal data
+// beyond the id of the erased user.
+type ErasureRecord struct {
+	Id               string    `json:"id" firestore:"id"`
+	UserId           string    `json:"user_id" firestore:"user_id"`
+	RequestedBy      *string   `json:"requested_by,omitempty" firestore:"requested_by,omitempty"`
+	DocumentsDeleted int       `json:"documents_deleted" firestore:"documents_deleted"`
+	ErasedAt         time.Time `json:"erased_at" firestore:"erased_at"`
+}
+
+// A stored document that belongs to a user, keyed by its path in the database
+type Document struct {
+	Path string                 `json:"path"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// Everything held about a user, including soft deleted records
+type UserExport struct {
+	UserId     string     `json:"user_id"`
+	ExportedAt time.Time  `json:"exported_at"`
+	Documents  []Document `json:"documents"`
+}
+
+// Implements the privacy repository interface design pattern
+type PrivacyRepository interface {
+	EraseUser(ctx context.Context, userId string) (int, error)
+	ExportUser(ctx context.Context, userId string) ([]Document, error)
+	PostErasureRecord(ctx context.Context, record ErasureRecord) (ErasureRecord, error)
+}
+
+type PrivacyService struct {
+	privacyRepository PrivacyRepository
+}
+
+func NewPrivacyService(privacyRepository PrivacyRepository) *PrivacyService {
+	return &PrivacyService{
+		privacyRepository: privacyRepository,
+	}
+}
+
+// Permanently removes the user and everything stored under it, then records the erasure
+func (service *PrivacyService) EraseUser(ctx context.Context, userId string) (ErasureRecord, error) {
+	log.Debugf("Erasing user %s . . .", userId)
+
+	deleted, err := service.privacyRepository.EraseUser(ctx, userId)
+
+	if err != nil {
+		log.Errorf("Failed to erase user %s", userId)
+		return ErasureRecord{}, err
+	}
+
+	record := ErasureRecord{
+		Id:               uuid.New().String(),
+		UserId:           userId,
+		DocumentsDeleted: deleted,
+		ErasedAt:         time.Now().UTC(),
+	}
+
+	if ident, ok := identity.FromContext(ctx); ok {
+		record.RequestedBy = &ident.UID
+	}
+
+	postedRecord, err := service.privacyRepository.PostErasureRecord(ctx, record)
+
+	if err != nil {
+		log.Errorf("User %s was erased but the audit record could not be saved", userId)
+		return ErasureRecord{}, err
+	}
+
+	return postedRecord, nil
+}
+
+func (service *PrivacyService) ExportUser(ctx context.Context, userId string) (UserExport, error) {
+	log.Debugf("Exporting data of user %s . . .", userId)
+
+	documents, err := service.privacyRepository.ExportUser(ctx, userId)
+
+	if err != nil {
+		log.Errorf("Failed to export data of user %s", userId)
+		return UserExport{}, err
+	}
+
+	return UserExport{
+		UserId:     userId,
+		ExportedAt: time.Now().UTC(),
+		Documents:  documents,
+	}, nil
+}
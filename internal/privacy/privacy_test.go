@@ -0,0 +1,98 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zzenonn/scoping-ai/internal/identity"
+)
+
+// Keeps the erasure records it is given instead of storing them
+type memoryRepository struct {
+	deleted   int
+	eraseErr  error
+	recordErr error
+	records   []ErasureRecord
+	documents []Document
+}
+
+func (repo *memoryRepository) EraseUser(ctx context.Context, userId string) (int, error) {
+	return repo.deleted, repo.eraseErr
+}
+
+func (repo *memoryRepository) ExportUser(ctx context.Context, userId string) ([]Document, error) {
+	if len(repo.documents) == 0 {
+		return nil, ErrNotFound
+	}
+	return repo.documents, nil
+}
+
+func (repo *memoryRepository) PostErasureRecord(ctx context.Context, record ErasureRecord) (ErasureRecord, error) {
+	if repo.recordErr != nil {
+		return ErasureRecord{}, repo.recordErr
+	}
+	repo.records = append(repo.records, record)
+	return record, nil
+}
+
+func TestEraseUser(t *testing.T) {
+	errDatabase := errors.New("database unavailable")
+
+	tests := []struct {
+		name        string
+		repo        *memoryRepository
+		wantErr     error
+		wantRecords int
+	}{
+		{"erased", &memoryRepository{deleted: 4}, nil, 1},
+		{"erasure fails", &memoryRepository{eraseErr: errDatabase}, errDatabase, 0},
+		{"record fails", &memoryRepository{deleted: 4, recordErr: errDatabase}, errDatabase, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := identity.NewContext(context.Background(), identity.Identity{UID: "admin"})
+
+			record, err := NewPrivacyService(tt.repo).EraseUser(ctx, "user-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if len(tt.repo.records) != tt.wantRecords {
+				t.Fatalf("got %d erasure records, want %d", len(tt.repo.records), tt.wantRecords)
+			}
+			if err != nil {
+				return
+			}
+
+			if record.UserId != "user-1" || record.DocumentsDeleted != 4 {
+				t.Errorf("got record of %s with %d documents, want user-1 with 4", record.UserId, record.DocumentsDeleted)
+			}
+			if record.RequestedBy == nil || *record.RequestedBy != "admin" {
+				t.Errorf("got requester %v, want admin", record.RequestedBy)
+			}
+			if record.Id == "" || record.ErasedAt.IsZero() {
+				t.Error("the record has no id or erasure time")
+			}
+		})
+	}
+}
+
+func TestExportUser(t *testing.T) {
+	repo := &memoryRepository{documents: []Document{
+		{Path: "users/user-1", Data: map[string]interface{}{"name": "Lee"}},
+		{Path: "users/user-1/messages/message-1", Data: map[string]interface{}{"message_text": "Hi"}},
+	}}
+
+	export, err := NewPrivacyService(repo).ExportUser(context.Background(), "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.UserId != "user-1" || len(export.Documents) != 2 || export.ExportedAt.IsZero() {
+		t.Errorf("got export %+v, want both documents of user-1", export)
+	}
+
+	if _, err := NewPrivacyService(&memoryRepository{}).ExportUser(context.Background(), "user-2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrNotFound)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
@@ -12,8 +13,11 @@ import (
 )
 
 var (
-	ErrFetchingQuestions = errors.New("failed to fetch scoping questions by technology")
-	ErrNotImplemented    = errors.New("this function is not yet implemented")
+	ErrFetchingQuestions       = scopingaicommon.NewError(scopingaicommon.KindUpstream, "failed to fetch scoping questions by technology")
+	ErrNotImplemented          = scopingaicommon.NewError(scopingaicommon.KindInternal, "this function is not yet implemented")
+	ErrVersionMismatch         = scopingaicommon.NewError(scopingaicommon.KindPreconditionFailed, "question set was modified since it was last read")
+	ErrNotFound                = scopingaicommon.NewError(scopingaicommon.KindNotFound, "question set not found")
+	ErrDuplicateTechnologyName = scopingaicommon.NewError(scopingaicommon.KindConflict, "a question set with this technology name already exists")
 )
 
 func init() {
@@ -37,18 +41,25 @@ func init() {
 // Question set representation
 type QuestionSet struct {
 	Id             string                     `json:"id,omitempty" firestore:"id,omitempty"`
-	TechnologyName *string                    `json:"technology_name,omitempty" firestore:"technology_name,omitempty"`
-	Questions      []scopingaicommon.Question `json:"questions,omitempty" firestore:"questions,omitempty"`
+	TechnologyName *string                    `json:"technology_name,omitempty" firestore:"technology_name,omitempty" validate:"required,max=100"`
+	Questions      []scopingaicommon.Question `json:"questions,omitempty" firestore:"questions,omitempty" validate:"dive"`
+	DeletedAt      *time.Time                 `json:"deleted_at,omitempty" firestore:"deleted_at,omitempty"`
+	DeletedBy      *string                    `json:"deleted_by,omitempty" firestore:"deleted_by,omitempty"`
+	Version        string                     `json:"-" firestore:"-"`
 }
 
 // Implements the question set repository interface design pattern
 type QuestionSetRepository interface {
 	GetQuestionSet(ctx context.Context, technologyName string) (QuestionSet, error)
-	GetAllQuestionSets(ctx context.Context, page int, pageSize int) ([]QuestionSet, error)
+	GetAllQuestionSets(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]QuestionSet, error)
 	GetQuestionSetByTechName(ctx context.Context, technologyName string) (QuestionSet, error)
 	PostQuestionSet(ctx context.Context, questionSet QuestionSet) (QuestionSet, error)
 	UpdateQuestionSet(ctx context.Context, questionSet QuestionSet) (QuestionSet, error)
 	DeleteQuestionSet(ctx context.Context, id string) error
+	RestoreQuestionSet(ctx context.Context, id string) error
+	PurgeDeletedQuestionSets(ctx context.Context, before time.Time) (int, error)
+	ImportQuestionSets(ctx context.Context, questionSets []QuestionSet, dryRun bool) ([]scopingaicommon.ImportResult, error)
+	ExportQuestionSets(ctx context.Context, each func(QuestionSet) error) error
 }
 
 type QuestionSetService struct {
@@ -74,10 +85,10 @@ func (q *QuestionSetService) GetQuestionSet(ctx context.Context, technologyName
 	return questionSet, nil
 }
 
-func (q *QuestionSetService) GetAllQuestionSets(ctx context.Context, page int, pageSize int) ([]QuestionSet, error) {
+func (q *QuestionSetService) GetAllQuestionSets(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]QuestionSet, error) {
 	log.Debug("Retreiving all question sets . . .")
 
-	questionSets, err := q.questionSetRepository.GetAllQuestionSets(ctx, page, pageSize)
+	questionSets, err := q.questionSetRepository.GetAllQuestionSets(ctx, page, pageSize, includeDeleted)
 
 	if err != nil {
 		log.Error("Failed to retrieve all question sets")
@@ -92,6 +103,10 @@ func (q *QuestionSetService) GetQuestionSetByTechName(ctx context.Context, techn
 
 	qSet, err := q.questionSetRepository.GetQuestionSetByTechName(ctx, technologyName)
 
+	if errors.Is(err, ErrNotFound) {
+		return QuestionSet{}, err
+	}
+
 	if err != nil {
 		log.Error("Failed to retrieve question set by technology name")
 		return QuestionSet{}, ErrFetchingQuestions
@@ -127,8 +142,70 @@ func (q *QuestionSetService) UpdateQuestionSet(ctx context.Context, qSet Questio
 	return updatedQSet, nil
 }
 
+// Soft deletes the question set. It can be restored until it is purged.
 func (q *QuestionSetService) DeleteQuestionSet(ctx context.Context, id string) error {
 	err := q.questionSetRepository.DeleteQuestionSet(ctx, id)
 
 	return err
 }
+
+func (q *QuestionSetService) RestoreQuestionSet(ctx context.Context, id string) error {
+	log.Debug("Restoring question set . . .")
+
+	err := q.questionSetRepository.RestoreQuestionSet(ctx, id)
+
+	if err != nil {
+		log.Error("Failed to restore question set")
+		return err
+	}
+
+	return nil
+}
+
+// Permanently removes question sets that were deleted before the given time
+func (q *QuestionSetService) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	log.Debug("Purging deleted question sets . . .")
+
+	purged, err := q.questionSetRepository.PurgeDeletedQuestionSets(ctx, before)
+
+	if err != nil {
+		log.Error("Failed to purge deleted question sets")
+		return purged, err
+	}
+
+	return purged, nil
+}
+
+// Creates or updates the question sets by technology name. Soft deleted
+// question sets with the same technology name are restored. Results follow
+// the order of the question sets, with the rows that failed among them.
+func (q *QuestionSetService) ImportQuestionSets(ctx context.Context, qSets []QuestionSet, dryRun bool) ([]scopingaicommon.ImportResult, error) {
+	log.Debugf("Importing %d question sets . . .", len(qSets))
+
+	for i := range qSets {
+		qSets[i].Id = uuid.New().String()
+	}
+
+	results, err := q.questionSetRepository.ImportQuestionSets(ctx, qSets, dryRun)
+
+	if err != nil {
+		log.Error("Failed to import question sets")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Calls each with every question set that isn't deleted
+func (q *QuestionSetService) ExportQuestionSets(ctx context.Context, each func(QuestionSet) error) error {
+	log.Debug("Exporting question sets . . .")
+
+	err := q.questionSetRepository.ExportQuestionSets(ctx, each)
+
+	if err != nil {
+		log.Error("Failed to export question sets")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// A token bucket holding up to Burst requests that refills at Burst
+// requests per Period
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+func PerMinute(requests int) Limit {
+	return Limit{Burst: requests, Period: time.Minute}
+}
+
+// Outcome of taking a token from a bucket. Reset is how long the bucket takes
+// to fill up again and RetryAfter how long until the next token when the
+// request was refused.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Stores the buckets. The in-memory store only limits a single instance,
+// deployments running several instances plug in a shared backend such as
+// Redis behind the same interface.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	capacity := float64(limit.Burst)
+	rate := capacity / limit.Period.Seconds()
+
+	s.sweep(now, limit.Period)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	result := Result{Limit: limit.Burst}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+
+	return result, nil
+}
+
+// Drops buckets that have been idle long enough to be full again, a new
+// bucket starts out full anyway
+func (s *MemoryStore) sweep(now time.Time, period time.Duration) {
+	if now.Sub(s.lastSweep) < period {
+		return
+	}
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= period {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.lastSweep = now
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(math.Ceil(value)) * time.Second
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	// Refills a token a second, which keeps the arithmetic exact
+	limit := Limit{Burst: 2, Period: 2 * time.Second}
+
+	// Each step takes a token after advancing the clock by elapsed
+	type step struct {
+		key        string
+		elapsed    time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "burst then refused",
+			steps: []step{
+				{"a", 0, true, 1, 0},
+				{"a", 0, true, 0, 0},
+				{"a", 0, false, 0, time.Second},
+			},
+		},
+		{
+			name: "refills over the period",
+			steps: []step{
+				{"a", 0, true, 1, 0},
+				{"a", 0, true, 0, 0},
+				{"a", time.Second, true, 0, 0},
+				{"a", 500 * time.Millisecond, false, 0, time.Second},
+			},
+		},
+		{
+			name: "never holds more than the burst",
+			steps: []step{
+				{"a", 0, true, 1, 0},
+				{"a", time.Hour, true, 1, 0},
+				{"a", 0, true, 0, 0},
+				{"a", 0, false, 0, time.Second},
+			},
+		},
+		{
+			name: "keys have their own buckets",
+			steps: []step{
+				{"a", 0, true, 1, 0},
+				{"a", 0, true, 0, 0},
+				{"b", 0, true, 1, 0},
+				{"a", 0, false, 0, time.Second},
+			},
+		},
+		{
+			name: "swept buckets start full",
+			steps: []step{
+				{"a", 0, true, 1, 0},
+				{"a", 0, true, 0, 0},
+				{"b", time.Minute, true, 1, 0},
+				{"a", 0, true, 1, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			store := NewMemoryStore()
+			store.now = func() time.Time { return now }
+
+			for i, s := range tt.steps {
+				now = now.Add(s.elapsed)
+
+				result, err := store.Take(context.Background(), s.key, limit)
+				if err != nil {
+					t.Fatalf("step %d: unexpected error: %v", i, err)
+				}
+
+				if result.Allowed != s.allowed {
+					t.Errorf("step %d: allowed = %v, want %v", i, result.Allowed, s.allowed)
+				}
+				if result.Remaining != s.remaining {
+					t.Errorf("step %d: remaining = %d, want %d", i, result.Remaining, s.remaining)
+				}
+				if result.RetryAfter != s.retryAfter {
+					t.Errorf("step %d: retry after = %s, want %s", i, result.RetryAfter, s.retryAfter)
+				}
+				if result.Limit != limit.Burst {
+					t.Errorf("step %d: limit = %d, want %d", i, result.Limit, limit.Burst)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package retention
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+// Implemented by every service that soft deletes its records
+type Purger interface {
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+}
+
+// Periodically purges soft deleted records once they are older than the retention period
+type Scheduler struct {
+	retention time.Duration
+	interval  time.Duration
+	purgers   map[string]Purger
+}
+
+func NewScheduler(retention time.Duration, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		retention: retention,
+		interval:  interval,
+		purgers:   map[string]Purger{},
+	}
+}
+
+func (s *Scheduler) AddPurger(name string, purger Purger) {
+	s.purgers[name] = purger
+}
+
+// Purges all registered services once, then on every interval until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) purge(ctx context.Context) {
+	before := time.Now().Add(-s.retention)
+
+	for name, purger := range s.purgers {
+		purged, err := purger.PurgeDeleted(ctx, before)
+		if err != nil {
+			log.Errorf("Failed to purge deleted %s: %v", name, err)
+			continue
+		}
+
+		log.Infof("Purged %d deleted %s older than %s", purged, name, before.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,84 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type purgerFunc func(ctx context.Context, before time.Time) (int, error)
+
+func (f purgerFunc) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	return f(ctx, before)
+}
+
+func TestSchedulerPurge(t *testing.T) {
+	retention := 30 * 24 * time.Hour
+	scheduler := NewScheduler(retention, time.Hour)
+
+	var cutoffs []time.Time
+	record := purgerFunc(func(ctx context.Context, before time.Time) (int, error) {
+		cutoffs = append(cutoffs, before)
+		return 1, nil
+	})
+
+	scheduler.AddPurger("failing", purgerFunc(func(ctx context.Context, before time.Time) (int, error) {
+		return 0, errors.New("database unavailable")
+	}))
+	scheduler.AddPurger("question sets", record)
+	scheduler.AddPurger("messages", record)
+
+	start := time.Now()
+	scheduler.purge(context.Background())
+	end := time.Now()
+
+	// A failing purger doesn't keep the others from running
+	if len(cutoffs) != 2 {
+		t.Fatalf("got %d purges, want 2", len(cutoffs))
+	}
+
+	// Only records deleted longer than the retention period ago are purged
+	for _, before := range cutoffs {
+		if before.Before(start.Add(-retention)) || before.After(end.Add(-retention)) {
+			t.Errorf("purged records deleted before %s, want %s ago", before, retention)
+		}
+	}
+}
+
+func TestSchedulerRunStopsWithContext(t *testing.T) {
+	scheduler := NewScheduler(time.Hour, time.Millisecond)
+
+	purges := make(chan struct{}, 10)
+	scheduler.AddPurger("question sets", purgerFunc(func(ctx context.Context, before time.Time) (int, error) {
+		select {
+		case purges <- struct{}{}:
+		default:
+		}
+		return 0, nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	// The first purge runs right away, the next one on the interval
+	for i := 0; i < 2; i++ {
+		select {
+		case <-purges:
+		case <-time.After(time.Second):
+			t.Fatal("the scheduler did not purge")
+		}
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the scheduler kept running after the context was cancelled")
+	}
+}
@@ -0,0 +1,17 @@
+package tenant
+
+import "context"
+
+// Tenants are the training providers the application is deployed for. Each
+// tenant only ever sees its own catalog and learners. Requests without a
+// tenant belong to the default tenant.
+type contextKey struct{}
+
+func NewContext(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, contextKey{}, tenantId)
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	tenantId, ok := ctx.Value(contextKey{}).(string)
+	return tenantId, ok && tenantId != ""
+}
@@ -0,0 +1,147 @@
+package ticket
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+// How long a ticket can be redeemed after it was issued. Clients ask for one
+// right before they connect.
+const Lifetime = 30 * time.Second
+
+var ErrInvalidTicket = common.NewError(common.KindUnauthorized, "the ticket is invalid, expired or was already used")
+
+// Lets a browser open a WebSocket without sending its ID token, which would
+// end up in the Sec-WebSocket-Protocol header. A ticket is issued to an
+// authenticated caller for a single path and redeemed once, shortly after.
+// Only the hash of the secret is stored, the id is that hash.
+type Ticket struct {
+	Id             string    `firestore:"id"`
+	Path           string    `firestore:"path"`
+	UID            string    `firestore:"uid"`
+	Email          string    `firestore:"email,omitempty"`
+	TenantId       string    `firestore:"tenant_id,omitempty"`
+	Roles          []string  `firestore:"roles,omitempty"`
+	APIKeyId       string    `firestore:"api_key_id,omitempty"`
+	Scopes         []string  `firestore:"scopes,omitempty"`
+	ImpersonatorId string    `firestore:"impersonator_id,omitempty"`
+	ExpiresAt      time.Time `firestore:"expires_at"`
+}
+
+// The caller the ticket was issued to, with the roles it held then
+func (t Ticket) Identity() identity.Identity {
+	return identity.Identity{
+		UID:            t.UID,
+		Email:          t.Email,
+		TenantId:       t.TenantId,
+		Roles:          t.Roles,
+		APIKeyId:       t.APIKeyId,
+		Scopes:         t.Scopes,
+		ImpersonatorId: t.ImpersonatorId,
+	}
+}
+
+// The secret handed to the client and when it stops being accepted
+type IssuedTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Implements the ticket repository interface design pattern
+type TicketRepository interface {
+	PostTicket(ctx context.Context, ticket Ticket) error
+	// Removes the ticket and returns it, so it can't be redeemed twice
+	TakeTicket(ctx context.Context, id string) (Ticket, error)
+}
+
+type TicketService struct {
+	ticketRepository TicketRepository
+}
+
+func NewTicketService(ticketRepository TicketRepository) *TicketService {
+	return &TicketService{
+		ticketRepository: ticketRepository,
+	}
+}
+
+func ticketId(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issues a ticket for the caller to connect to the path
+func (service *TicketService) IssueTicket(ctx context.Context, ident identity.Identity, path string) (IssuedTicket, error) {
+	log.Debug("Issuing ticket . . .")
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		log.Error("Failed to generate ticket")
+		return IssuedTicket{}, err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(raw)
+
+	ticket := Ticket{
+		Id:             ticketId(secret),
+		Path:           path,
+		UID:            ident.UID,
+		Email:          ident.Email,
+		TenantId:       ident.TenantId,
+		Roles:          ident.Roles,
+		APIKeyId:       ident.APIKeyId,
+		Scopes:         ident.Scopes,
+		ImpersonatorId: ident.ImpersonatorId,
+		ExpiresAt:      time.Now().UTC().Add(Lifetime),
+	}
+
+	if err := service.ticketRepository.PostTicket(ctx, ticket); err != nil {
+		log.Error("Failed to issue ticket")
+		return IssuedTicket{}, err
+	}
+
+	return IssuedTicket{Ticket: secret, ExpiresAt: ticket.ExpiresAt}, nil
+}
+
+// Redeems the ticket for a connection to the path
+func (service *TicketService) RedeemTicket(ctx context.Context, secret string, path string) (Ticket, error) {
+	log.Debug("Redeeming ticket . . .")
+
+	ticket, err := service.ticketRepository.TakeTicket(ctx, ticketId(secret))
+	if err != nil {
+		log.Error("Failed to redeem ticket")
+		return Ticket{}, err
+	}
+
+	if ticket.Path != path || !ticket.ExpiresAt.After(time.Now()) {
+		return Ticket{}, ErrInvalidTicket
+	}
+
+	return ticket, nil
+}
@@ -0,0 +1,72 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+var errForbidden = common.NewError(common.KindForbidden, "forbidden")
+
+// Reports the kind of a service error in the extensions of the GraphQL error.
+// Like on the REST API, errors without a kind only show their message in the log.
+type resolverError struct {
+	err error
+}
+
+func (e resolverError) Error() string {
+	var kindErr *common.Error
+	if errors.As(e.err, &kindErr) {
+		return kindErr.Error()
+	}
+	return "internal server error"
+}
+
+func (e resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": string(common.KindOf(e.err))}
+}
+
+func toResolverError(err error) error {
+	log.Error(err)
+	return resolverError{err: err}
+}
+
+// Mirrors the read guards of the REST routes. API keys need the read scope
+// of the resource and users one of the roles, when any are given. Admins may
+// read everything.
+func authorize(ctx context.Context, resource string, roles ...string) error {
+	ident, _ := identity.FromContext(ctx)
+
+	if ident.IsAPIKey() {
+		if !ident.HasScope(resource + ":read") {
+			return toResolverError(errForbidden)
+		}
+		return nil
+	}
+
+	if len(roles) > 0 && !ident.HasRole(append(roles, scopingUser.RoleAdmin)...) {
+		return toResolverError(errForbidden)
+	}
+
+	return nil
+}
+
+// Users read their own records. The roles may read the records of users in
+// their organization.
+func (r *Resolver) authorizeUser(ctx context.Context, resource string, userId string, roles ...string) error {
+	ident, _ := identity.FromContext(ctx)
+
+	if ident.IsAPIKey() || ident.HasRole(scopingUser.RoleAdmin) {
+		return authorize(ctx, resource)
+	}
+
+	if !r.policy.CanAccessUser(ctx, ident, userId, roles...) {
+		return toResolverError(errForbidden)
+	}
+
+	return nil
+}
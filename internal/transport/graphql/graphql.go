@@ -0,0 +1,272 @@
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	gql "github.com/graph-gophers/graphql-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	outline "github.com/zzenonn/scoping-ai/internal/outline"
+	questionSet "github.com/zzenonn/scoping-ai/internal/question-set"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+//go:embed schema.graphql
+var schemaString string
+
+// Limits that keep a single query from fanning out over the whole database
+const (
+	maxDepth       = 8
+	maxQueryLength = 10000
+	maxBodyBytes   = 1 << 20
+
+	// Keys a loader fetches together. Outlines are queried with an "in"
+	// filter, which takes at most 30 values.
+	maxUserBatch    = 100
+	maxOutlineBatch = 30
+	maxMessageBatch = 20
+)
+
+type QuestionSetService interface {
+	GetQuestionSet(ctx context.Context, id string) (questionSet.QuestionSet, error)
+	GetQuestionSetByTechName(ctx context.Context, technologyName string) (questionSet.QuestionSet, error)
+	GetAllQuestionSets(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]questionSet.QuestionSet, error)
+}
+
+type CourseOutlineService interface {
+	GetCourseOutline(ctx context.Context, id string) (outline.CourseOutline, error)
+	GetCourseOutlinesByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]outline.CourseOutline, error)
+	GetCourseOutlinesByTechnologyNames(ctx context.Context, technologyNames []string) ([]outline.CourseOutline, error)
+	GetAllCourseOutlines(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]outline.CourseOutline, error)
+}
+
+type UserService interface {
+	GetUser(ctx context.Context, id string) (scopingUser.User, error)
+	GetUsersByIds(ctx context.Context, ids []string) ([]scopingUser.User, error)
+	GetAllUsers(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]scopingUser.User, error)
+	GetUsersByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]scopingUser.User, error)
+}
+
+type MessageService interface {
+	GetMessage(ctx context.Context, messageId string, userId string) (scopingMessage.Message, error)
+	WaitForMessage(ctx context.Context, messageId string, userId string, wait time.Duration) (scopingMessage.Message, error)
+	GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int, includeDeleted bool) ([]scopingMessage.Message, error)
+}
+
+// Implemented by the access policy of the REST API, so resolvers grant access
+// to users the same way the routes do
+type AccessPolicy interface {
+	CanAccessUser(ctx context.Context, ident identity.Identity, userId string, roles ...string) bool
+}
+
+// Serves GraphQL over HTTP. Callers are authenticated by the REST middleware
+// in front of it. Subscriptions, and any other operation when the client
+// accepts text/event-stream, are streamed as server-sent events.
+type Handler struct {
+	schema   *gql.Schema
+	resolver *Resolver
+}
+
+func NewHandler(questionSets QuestionSetService, outlines CourseOutlineService, users UserService, messages MessageService, policy AccessPolicy) *Handler {
+	resolver := &Resolver{
+		questionSets: questionSets,
+		outlines:     outlines,
+		users:        users,
+		messages:     messages,
+		policy:       policy,
+	}
+
+	return &Handler{
+		schema: gql.MustParseSchema(schemaString, resolver,
+			gql.MaxDepth(maxDepth),
+			gql.MaxQueryLength(maxQueryLength),
+		),
+		resolver: resolver,
+	}
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err := decoder.Decode(&req); err != nil {
+		writeRequestError(w, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	if req.Query == "" {
+		writeRequestError(w, "the query is required")
+		return
+	}
+
+	ctx := withLoaders(r.Context(), h.resolver.newLoaders())
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		h.stream(ctx, w, req)
+		return
+	}
+
+	response := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(err)
+	}
+}
+
+// Sends each response as a next event and ends with a complete event, the
+// distinct connections mode of the GraphQL over server-sent events protocol
+func (h *Handler) stream(ctx context.Context, w http.ResponseWriter, req request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeRequestError(w, "streaming is not supported")
+		return
+	}
+
+	responses, err := h.schema.Subscribe(ctx, req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		writeRequestError(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for response := range responses {
+		data, err := json.Marshal(response)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		fmt.Fprintf(w, "event: next\ndata: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	fmt.Fprint(w, "event: complete\ndata:\n\n")
+	flusher.Flush()
+}
+
+// Requests that can't be executed at all are answered like failed queries
+func writeRequestError(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	response := map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(err)
+	}
+}
+
+// Messages are stored under each user, so they can't be read for many users
+// in one query. Each user's page is fetched concurrently instead, once per
+// request however many times it is selected.
+type messagesKey struct {
+	userId         string
+	page           int
+	pageSize       int
+	includeDeleted bool
+}
+
+func (r *Resolver) newLoaders() *loaders {
+	return &loaders{
+		users: newLoader(func(ctx context.Context, ids []string) (map[string]scopingUser.User, error) {
+			users, err := r.users.GetUsersByIds(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byId := make(map[string]scopingUser.User, len(users))
+			for _, user := range users {
+				byId[user.ID] = user
+			}
+			return byId, nil
+		}, maxUserBatch),
+
+		outlines: newLoader(func(ctx context.Context, technologyNames []string) (map[string][]outline.CourseOutline, error) {
+			courseOutlines, err := r.outlines.GetCourseOutlinesByTechnologyNames(ctx, technologyNames)
+			if err != nil {
+				return nil, err
+			}
+
+			byTechnology := map[string][]outline.CourseOutline{}
+			for _, courseOutline := range courseOutlines {
+				if courseOutline.TechnologyName != nil {
+					byTechnology[*courseOutline.TechnologyName] = append(byTechnology[*courseOutline.TechnologyName], courseOutline)
+				}
+			}
+			return byTechnology, nil
+		}, maxOutlineBatch),
+
+		messages: newLoader(func(ctx context.Context, keys []messagesKey) (map[messagesKey][]scopingMessage.Message, error) {
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			var errs []error
+
+			byKey := make(map[messagesKey][]scopingMessage.Message, len(keys))
+
+			for _, key := range keys {
+				wg.Add(1)
+				go func(key messagesKey) {
+					defer wg.Done()
+
+					messages, err := r.messages.GetAllUserMessages(ctx, key.userId, key.page, key.pageSize, key.includeDeleted)
+
+					mu.Lock()
+					defer mu.Unlock()
+
+					if err != nil {
+						errs = append(errs, err)
+						return
+					}
+					byKey[key] = messages
+				}(key)
+			}
+
+			wg.Wait()
+
+			return byKey, errors.Join(errs...)
+		}, maxMessageBatch),
+	}
+}
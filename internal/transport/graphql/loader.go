@@ -0,0 +1,110 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	outline "github.com/zzenonn/scoping-ai/internal/outline"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+)
+
+// How long a loader collects keys before it fetches them. Resolvers of list
+// items run concurrently, so their keys arrive within this window.
+const batchWait = 2 * time.Millisecond
+
+// Fetches the values of many keys at once. Keys without a value are left out
+// of the map.
+type batchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Collects the keys resolvers ask for and fetches them in one batch. Values
+// are cached for the rest of the request, so each key is fetched once.
+type loader[K comparable, V any] struct {
+	fetch    batchFunc[K, V]
+	maxBatch int
+
+	mu      sync.Mutex
+	pending *batch[K, V]
+	batches map[K]*batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys   []K
+	once   sync.Once
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+func newLoader[K comparable, V any](fetch batchFunc[K, V], maxBatch int) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:    fetch,
+		maxBatch: maxBatch,
+		batches:  map[K]*batch[K, V]{},
+	}
+}
+
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+
+	b, ok := l.batches[key]
+	if !ok {
+		if l.pending == nil {
+			b = &batch[K, V]{done: make(chan struct{})}
+			l.pending = b
+			time.AfterFunc(batchWait, func() { l.dispatch(ctx, b) })
+		}
+
+		b = l.pending
+		b.keys = append(b.keys, key)
+		l.batches[key] = b
+
+		if len(b.keys) >= l.maxBatch {
+			l.pending = nil
+			go l.dispatch(ctx, b)
+		}
+	}
+
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+
+	return b.values[key], b.err
+}
+
+func (l *loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	b.once.Do(func() {
+		l.mu.Lock()
+		if l.pending == b {
+			l.pending = nil
+		}
+		keys := b.keys
+		l.mu.Unlock()
+
+		b.values, b.err = l.fetch(ctx, keys)
+		close(b.done)
+	})
+}
+
+type loadersKey struct{}
+
+// The loaders of a single request
+type loaders struct {
+	users    *loader[string, scopingUser.User]
+	outlines *loader[string, []outline.CourseOutline]
+	messages *loader[messagesKey, []scopingMessage.Message]
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
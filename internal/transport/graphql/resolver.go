@@ -0,0 +1,275 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	gql "github.com/graph-gophers/graphql-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	outline "github.com/zzenonn/scoping-ai/internal/outline"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+)
+
+// How long messageCompleted waits for the recommendation to replace the
+// pending message
+const completionWait = 2 * time.Minute
+
+// Filterable course outline fields by their enum values
+var courseOutlineFields = map[string]string{
+	"TECHNOLOGY_NAME": "technology_name",
+	"COURSE_CODE":     "course_code",
+	"COURSE_NAME":     "course_name",
+}
+
+// Root resolver of queries and subscriptions
+type Resolver struct {
+	questionSets QuestionSetService
+	outlines     CourseOutlineService
+	users        UserService
+	messages     MessageService
+	policy       AccessPolicy
+}
+
+type pageArgs struct {
+	Page           *int32
+	PageSize       *int32
+	IncludeDeleted *bool
+}
+
+// Same defaults as the query parameters of the REST API. Like there, soft
+// deleted records are only listed to admins and API keys.
+func (args pageArgs) values(ctx context.Context) (int, int, bool) {
+	page, pageSize := 1, 10
+
+	if args.Page != nil && *args.Page > 0 {
+		page = int(*args.Page)
+	}
+
+	if args.PageSize != nil && *args.PageSize > 0 {
+		pageSize = int(*args.PageSize)
+	}
+
+	ident, _ := identity.FromContext(ctx)
+	admin := ident.IsAPIKey() || ident.HasRole(scopingUser.RoleAdmin)
+
+	return page, pageSize, admin && args.IncludeDeleted != nil && *args.IncludeDeleted
+}
+
+func (r *Resolver) Me(ctx context.Context) (*userResolver, error) {
+	ident, _ := identity.FromContext(ctx)
+	if ident.IsAPIKey() {
+		return nil, nil
+	}
+
+	return r.loadUser(ctx, ident.UID)
+}
+
+func (r *Resolver) User(ctx context.Context, args struct{ Id gql.ID }) (*userResolver, error) {
+	if err := r.authorizeUser(ctx, "users", string(args.Id), scopingUser.RoleCorporateManager); err != nil {
+		return nil, err
+	}
+
+	return r.loadUser(ctx, string(args.Id))
+}
+
+func (r *Resolver) loadUser(ctx context.Context, id string) (*userResolver, error) {
+	user, err := loadersFrom(ctx).users.Load(ctx, id)
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	if user.ID == "" {
+		return nil, nil
+	}
+
+	return &userResolver{root: r, user: user}, nil
+}
+
+// Corporate managers only list the users of their own organization
+func (r *Resolver) Users(ctx context.Context, args pageArgs) ([]*userResolver, error) {
+	if err := authorize(ctx, "users", scopingUser.RoleCorporateManager); err != nil {
+		return nil, err
+	}
+
+	page, pageSize, includeDeleted := args.values(ctx)
+
+	var users []scopingUser.User
+	var err error
+
+	ident, _ := identity.FromContext(ctx)
+	if ident.HasRole(scopingUser.RoleAdmin) || ident.IsAPIKey() {
+		users, err = r.users.GetAllUsers(ctx, page, pageSize, includeDeleted)
+	} else {
+		var manager scopingUser.User
+		manager, err = r.users.GetUser(ctx, ident.UID)
+		if err == nil && manager.OrganizationId == nil {
+			return nil, toResolverError(errForbidden)
+		}
+		if err == nil {
+			users, err = r.users.GetUsersByFilter(ctx, page, pageSize, "organization_id", *manager.OrganizationId, includeDeleted)
+		}
+	}
+
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	resolvers := make([]*userResolver, 0, len(users))
+	for _, user := range users {
+		resolvers = append(resolvers, &userResolver{root: r, user: user})
+	}
+
+	return resolvers, nil
+}
+
+func (r *Resolver) QuestionSet(ctx context.Context, args struct{ Id gql.ID }) (*questionSetResolver, error) {
+	if err := authorize(ctx, "question-sets"); err != nil {
+		return nil, err
+	}
+
+	qSet, err := r.questionSets.GetQuestionSet(ctx, string(args.Id))
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	return &questionSetResolver{root: r, questionSet: qSet}, nil
+}
+
+func (r *Resolver) QuestionSetByTechnologyName(ctx context.Context, args struct{ TechnologyName string }) (*questionSetResolver, error) {
+	if err := authorize(ctx, "question-sets"); err != nil {
+		return nil, err
+	}
+
+	qSet, err := r.questionSets.GetQuestionSetByTechName(ctx, args.TechnologyName)
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	return &questionSetResolver{root: r, questionSet: qSet}, nil
+}
+
+func (r *Resolver) QuestionSets(ctx context.Context, args pageArgs) ([]*questionSetResolver, error) {
+	if err := authorize(ctx, "question-sets"); err != nil {
+		return nil, err
+	}
+
+	page, pageSize, includeDeleted := args.values(ctx)
+
+	qSets, err := r.questionSets.GetAllQuestionSets(ctx, page, pageSize, includeDeleted)
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	resolvers := make([]*questionSetResolver, 0, len(qSets))
+	for _, qSet := range qSets {
+		resolvers = append(resolvers, &questionSetResolver{root: r, questionSet: qSet})
+	}
+
+	return resolvers, nil
+}
+
+func (r *Resolver) CourseOutline(ctx context.Context, args struct{ Id gql.ID }) (*courseOutlineResolver, error) {
+	if err := authorize(ctx, "course-outlines"); err != nil {
+		return nil, err
+	}
+
+	courseOutline, err := r.outlines.GetCourseOutline(ctx, string(args.Id))
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	return &courseOutlineResolver{courseOutline: courseOutline}, nil
+}
+
+type courseOutlineFilter struct {
+	Field string
+	Value string
+}
+
+type courseOutlinesArgs struct {
+	Page           *int32
+	PageSize       *int32
+	IncludeDeleted *bool
+	Filter         *courseOutlineFilter
+}
+
+func (r *Resolver) CourseOutlines(ctx context.Context, args courseOutlinesArgs) ([]*courseOutlineResolver, error) {
+	if err := authorize(ctx, "course-outlines"); err != nil {
+		return nil, err
+	}
+
+	page, pageSize, includeDeleted := pageArgs{args.Page, args.PageSize, args.IncludeDeleted}.values(ctx)
+
+	var courseOutlines []outline.CourseOutline
+	var err error
+
+	if args.Filter != nil {
+		courseOutlines, err = r.outlines.GetCourseOutlinesByFilter(ctx, page, pageSize, courseOutlineFields[args.Filter.Field], args.Filter.Value, includeDeleted)
+	} else {
+		courseOutlines, err = r.outlines.GetAllCourseOutlines(ctx, page, pageSize, includeDeleted)
+	}
+
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	return courseOutlineResolvers(courseOutlines), nil
+}
+
+func (r *Resolver) Message(ctx context.Context, args struct {
+	UserId gql.ID
+	Id     gql.ID
+}) (*messageResolver, error) {
+	if err := r.authorizeUser(ctx, "messages", string(args.UserId), scopingUser.RoleTrainer, scopingUser.RoleCorporateManager); err != nil {
+		return nil, err
+	}
+
+	message, err := r.messages.GetMessage(ctx, string(args.Id), string(args.UserId))
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	return &messageResolver{root: r, message: message}, nil
+}
+
+// Sends the message once it is no longer pending and ends the subscription.
+// A failed prompt leaves the message pending, so the wait is bounded.
+func (r *Resolver) MessageCompleted(ctx context.Context, args struct {
+	UserId    gql.ID
+	MessageId gql.ID
+}) (<-chan *messageResolver, error) {
+	userId, messageId := string(args.UserId), string(args.MessageId)
+
+	if err := r.authorizeUser(ctx, "messages", userId, scopingUser.RoleTrainer, scopingUser.RoleCorporateManager); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.messages.GetMessage(ctx, messageId, userId); err != nil {
+		return nil, toResolverError(err)
+	}
+
+	completed := make(chan *messageResolver, 1)
+
+	go func() {
+		defer close(completed)
+
+		message, err := r.messages.WaitForMessage(ctx, messageId, userId, completionWait)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		if message.IsPending() {
+			log.Errorf("No recommendation replaced message %s after %s", messageId, completionWait)
+			return
+		}
+
+		completed <- &messageResolver{root: r, message: message}
+	}()
+
+	return completed, nil
+}
@@ -0,0 +1,295 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	gql "github.com/graph-gophers/graphql-go"
+	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	outline "github.com/zzenonn/scoping-ai/internal/outline"
+	questionSet "github.com/zzenonn/scoping-ai/internal/question-set"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+func toTime(t *time.Time) *gql.Time {
+	if t == nil {
+		return nil
+	}
+	return &gql.Time{Time: *t}
+}
+
+func toOptionalId(id *string) *gql.ID {
+	if id == nil {
+		return nil
+	}
+	optionalId := gql.ID(*id)
+	return &optionalId
+}
+
+type userResolver struct {
+	root *Resolver
+	user scopingUser.User
+}
+
+func (r *userResolver) Id() gql.ID {
+	return gql.ID(r.user.ID)
+}
+
+func (r *userResolver) Name() *string {
+	return r.user.Name
+}
+
+func (r *userResolver) EmailAddress() *string {
+	return r.user.EmailAddress
+}
+
+func (r *userResolver) Corporate() bool {
+	return r.user.Corporate
+}
+
+func (r *userResolver) OrganizationId() *gql.ID {
+	return toOptionalId(r.user.OrganizationId)
+}
+
+func (r *userResolver) Roles() []string {
+	if r.user.Roles == nil {
+		return []string{}
+	}
+	return r.user.Roles
+}
+
+func (r *userResolver) DeletedAt() *gql.Time {
+	return toTime(r.user.DeletedAt)
+}
+
+func (r *userResolver) Version() string {
+	return r.user.Version
+}
+
+// Trainers and corporate managers read the messages of other users, like on
+// the REST API
+func (r *userResolver) Messages(ctx context.Context, args pageArgs) ([]*messageResolver, error) {
+	if err := r.root.authorizeUser(ctx, "messages", r.user.ID, scopingUser.RoleTrainer, scopingUser.RoleCorporateManager); err != nil {
+		return nil, err
+	}
+
+	page, pageSize, includeDeleted := args.values(ctx)
+
+	messages, err := loadersFrom(ctx).messages.Load(ctx, messagesKey{
+		userId:         r.user.ID,
+		page:           page,
+		pageSize:       pageSize,
+		includeDeleted: includeDeleted,
+	})
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	resolvers := make([]*messageResolver, 0, len(messages))
+	for _, message := range messages {
+		resolvers = append(resolvers, &messageResolver{root: r.root, message: message})
+	}
+
+	return resolvers, nil
+}
+
+type questionSetResolver struct {
+	root        *Resolver
+	questionSet questionSet.QuestionSet
+}
+
+func (r *questionSetResolver) Id() gql.ID {
+	return gql.ID(r.questionSet.Id)
+}
+
+func (r *questionSetResolver) TechnologyName() *string {
+	return r.questionSet.TechnologyName
+}
+
+func (r *questionSetResolver) Questions() []*questionResolver {
+	resolvers := make([]*questionResolver, 0, len(r.questionSet.Questions))
+	for i := range r.questionSet.Questions {
+		resolvers = append(resolvers, &questionResolver{question: &r.questionSet.Questions[i]})
+	}
+	return resolvers
+}
+
+func (r *questionSetResolver) DeletedAt() *gql.Time {
+	return toTime(r.questionSet.DeletedAt)
+}
+
+func (r *questionSetResolver) Version() string {
+	return r.questionSet.Version
+}
+
+func (r *questionSetResolver) CourseOutlines(ctx context.Context) ([]*courseOutlineResolver, error) {
+	if r.questionSet.TechnologyName == nil {
+		return []*courseOutlineResolver{}, nil
+	}
+
+	if err := authorize(ctx, "course-outlines"); err != nil {
+		return nil, err
+	}
+
+	courseOutlines, err := loadersFrom(ctx).outlines.Load(ctx, *r.questionSet.TechnologyName)
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	return courseOutlineResolvers(courseOutlines), nil
+}
+
+type questionResolver struct {
+	question *common.Question
+}
+
+func (r *questionResolver) Category() *string {
+	return r.question.Category
+}
+
+func (r *questionResolver) Text() *string {
+	return r.question.Text
+}
+
+func (r *questionResolver) Options() *optionsResolver {
+	if r.question.Options == nil {
+		return nil
+	}
+	return &optionsResolver{options: r.question.Options}
+}
+
+type optionsResolver struct {
+	options *common.Options
+}
+
+func (r *optionsResolver) MultiAnswer() bool {
+	return r.options.MultiAnswer
+}
+
+func (r *optionsResolver) PossibleOptions() []string {
+	if r.options.PossibleOptions == nil {
+		return []string{}
+	}
+	return r.options.PossibleOptions
+}
+
+type courseOutlineResolver struct {
+	courseOutline outline.CourseOutline
+}
+
+func courseOutlineResolvers(courseOutlines []outline.CourseOutline) []*courseOutlineResolver {
+	resolvers := make([]*courseOutlineResolver, 0, len(courseOutlines))
+	for _, courseOutline := range courseOutlines {
+		resolvers = append(resolvers, &courseOutlineResolver{courseOutline: courseOutline})
+	}
+	return resolvers
+}
+
+func (r *courseOutlineResolver) Id() gql.ID {
+	return gql.ID(r.courseOutline.Id)
+}
+
+func (r *courseOutlineResolver) TechnologyName() *string {
+	return r.courseOutline.TechnologyName
+}
+
+func (r *courseOutlineResolver) CourseCode() *string {
+	return r.courseOutline.CourseCode
+}
+
+func (r *courseOutlineResolver) CourseName() *string {
+	return r.courseOutline.CourseName
+}
+
+func (r *courseOutlineResolver) Outline() *string {
+	return r.courseOutline.Outline
+}
+
+func (r *courseOutlineResolver) DeletedAt() *gql.Time {
+	return toTime(r.courseOutline.DeletedAt)
+}
+
+func (r *courseOutlineResolver) Version() string {
+	return r.courseOutline.Version
+}
+
+type messageResolver struct {
+	root    *Resolver
+	message scopingMessage.Message
+}
+
+func (r *messageResolver) Id() gql.ID {
+	return gql.ID(r.message.Id)
+}
+
+func (r *messageResolver) UserId() gql.ID {
+	if r.message.UserId == nil {
+		return ""
+	}
+	return gql.ID(*r.message.UserId)
+}
+
+// Reading the messages of a user doesn't grant reading the user, trainers
+// only see the messages
+func (r *messageResolver) User(ctx context.Context) (*userResolver, error) {
+	if r.message.UserId == nil {
+		return nil, nil
+	}
+
+	if err := r.root.authorizeUser(ctx, "users", *r.message.UserId, scopingUser.RoleCorporateManager); err != nil {
+		return nil, err
+	}
+
+	return r.root.loadUser(ctx, *r.message.UserId)
+}
+
+func (r *messageResolver) MessageText() *string {
+	return r.message.MessageText
+}
+
+func (r *messageResolver) Answer() *answerResolver {
+	if r.message.Answer == nil {
+		return nil
+	}
+	return &answerResolver{answer: r.message.Answer}
+}
+
+func (r *messageResolver) Pending() bool {
+	return r.message.IsPending()
+}
+
+func (r *messageResolver) CreatedAt() *gql.Time {
+	return toTime(r.message.CreatedAt)
+}
+
+func (r *messageResolver) UpdatedAt() *gql.Time {
+	return toTime(r.message.UpdatedAt)
+}
+
+func (r *messageResolver) DeletedAt() *gql.Time {
+	return toTime(r.message.DeletedAt)
+}
+
+func (r *messageResolver) Version() string {
+	return r.message.Version
+}
+
+type answerResolver struct {
+	answer *scopingMessage.Answer
+}
+
+func (r *answerResolver) Question() *questionResolver {
+	if r.answer.Question == nil {
+		return nil
+	}
+	return &questionResolver{question: r.answer.Question}
+}
+
+func (r *answerResolver) TechnologyName() *string {
+	return r.answer.TechnologyName
+}
+
+func (r *answerResolver) Answer() *string {
+	return r.answer.Answer
+}
@@ -0,0 +1,291 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	apikeys "github.com/zzenonn/scoping-ai/internal/apikey"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/internal/ratelimit"
+	"github.com/zzenonn/scoping-ai/internal/tenant"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, rawToken string) (identity.Identity, error)
+}
+
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (apikeys.APIKey, error)
+}
+
+// Implemented by the access policy of the REST API, so both APIs resolve
+// roles and grant access to users the same way
+type AccessPolicy interface {
+	ResolveRoles(ctx context.Context, ident identity.Identity) ([]string, error)
+	CanAccessUser(ctx context.Context, ident identity.Identity, userId string, roles ...string) bool
+}
+
+var (
+	errNotAuthorized   = status.Error(codes.Unauthenticated, "not authorized")
+	errForbidden       = status.Error(codes.PermissionDenied, "forbidden")
+	errTooManyRequests = status.Error(codes.ResourceExhausted, "too many requests")
+)
+
+// Who may call a method. API keys need the read or write scope of the
+// resource. Users need one of the roles, or when the method is about a user,
+// access to that user. Admins may call every method.
+type rule struct {
+	resource string
+	write    bool
+	roles    []string
+	user     func(req interface{}) string
+}
+
+type authenticator struct {
+	verifier TokenVerifier
+	apiKeys  APIKeyAuthenticator
+	policy   AccessPolicy
+	limits   Limits
+}
+
+// Same as the REST API, API keys go in x-api-key and tokens in authorization
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if rawKeys := md.Get("x-api-key"); len(rawKeys) > 0 && a.apiKeys != nil {
+		key, err := a.apiKeys.Authenticate(ctx, rawKeys[0])
+		if err != nil {
+			log.Errorf("unauthorized api key: %v", err)
+			return nil, errNotAuthorized
+		}
+
+		ident := identity.Identity{
+			UID:      "apikey:" + key.Id,
+			TenantId: key.TenantId,
+			APIKeyId: key.Id,
+			Scopes:   key.Scopes,
+		}
+
+		ctx = tenant.NewContext(ctx, ident.TenantId)
+		return identity.NewContext(ctx, ident), nil
+	}
+
+	authHeader := md.Get("authorization")
+	if len(authHeader) == 0 {
+		log.Error("invalid authorization metadata")
+		return nil, errNotAuthorized
+	}
+
+	authHeaderParts := strings.Split(authHeader[0], " ")
+	if len(authHeaderParts) != 2 || strings.ToLower(authHeaderParts[0]) != "bearer" {
+		log.Error("invalid authorization metadata")
+		return nil, errNotAuthorized
+	}
+
+	ident, err := a.verifier.VerifyToken(ctx, authHeaderParts[1])
+	if err != nil {
+		log.Errorf("unauthorized authorization metadata: %v", err)
+		return nil, errNotAuthorized
+	}
+
+	ctx = tenant.NewContext(ctx, ident.TenantId)
+
+	ident.Roles, err = a.policy.ResolveRoles(ctx, ident)
+	if errors.Is(err, scopingUser.ErrNotFound) {
+		log.Errorf("user %s was deleted", ident.UID)
+		return nil, errForbidden
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return identity.NewContext(ctx, ident), nil
+}
+
+func (a *authenticator) authorize(ctx context.Context, method string, req interface{}) error {
+	ident, _ := identity.FromContext(ctx)
+
+	r, ok := rules[method]
+	if !ok {
+		log.Errorf("no access rule for %s", method)
+		return errForbidden
+	}
+
+	if ident.IsAPIKey() {
+		scope := r.resource + ":read"
+		if r.write {
+			scope = r.resource + ":write"
+		}
+
+		if !ident.HasScope(scope) {
+			log.Errorf("api key %s lacks the %s scope", ident.APIKeyId, scope)
+			return errForbidden
+		}
+		return nil
+	}
+
+	if ident.HasRole(scopingUser.RoleAdmin) {
+		return nil
+	}
+
+	if r.user != nil {
+		userId := r.user(req)
+		if !a.policy.CanAccessUser(ctx, ident, userId, r.roles...) {
+			log.Errorf("user %s is not allowed to access user %s", ident.UID, userId)
+			return errForbidden
+		}
+		return nil
+	}
+
+	if r.roles != nil && !ident.HasRole(r.roles...) {
+		log.Errorf("user %s lacks any of the roles %v", ident.UID, r.roles)
+		return errForbidden
+	}
+
+	return nil
+}
+
+// Callers are counted by API key and by user
+func callerKey(ctx context.Context) string {
+	ident, _ := identity.FromContext(ctx)
+
+	if ident.IsAPIKey() {
+		return "apikey:" + ident.APIKeyId
+	}
+	return "user:" + ident.UID
+}
+
+// Same key as the REST API uses for addresses. Unlike there no load balancer
+// sits in front of the gRPC port, so the peer is the client.
+func addressKey(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "ip:"
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return "ip:" + p.Addr.String()
+	}
+	return "ip:" + host
+}
+
+// Counts the call against a budget shared with the REST API. Like there, an
+// unavailable store lets the call through.
+func (a *authenticator) takeToken(ctx context.Context, name string, key string, limit ratelimit.Limit) error {
+	if a.limits.Store == nil {
+		return nil
+	}
+
+	result, err := a.limits.Store.Take(ctx, name+"|"+key, limit)
+	if err != nil {
+		log.Errorf("rate limit store failed: %v", err)
+		return nil
+	}
+
+	if !result.Allowed {
+		log.Errorf("%s rate limit exceeded by %s", name, key)
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(result.RetryAfter.Seconds()))))
+		return errTooManyRequests
+	}
+
+	return nil
+}
+
+// Authenticates, rate limits and authorizes the call before it is handled.
+// Addresses are limited before authentication, so callers trying keys or
+// tokens are throttled too.
+func (a *authenticator) admit(ctx context.Context, method string, req interface{}) (context.Context, error) {
+	if err := a.takeToken(ctx, "addresses", addressKey(ctx), a.limits.Addresses); err != nil {
+		return nil, err
+	}
+
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.takeToken(ctx, "requests", callerKey(ctx), a.limits.Requests); err != nil {
+		return nil, err
+	}
+
+	if err := a.authorize(ctx, method, req); err != nil {
+		return nil, err
+	}
+
+	if answerMethods[method] {
+		if err := a.takeToken(ctx, "llm", callerKey(ctx), a.limits.Answers); err != nil {
+			return nil, err
+		}
+	}
+
+	return ctx, nil
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.admit(ctx, info.FullMethod, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// Streams are server-streaming only, so the request is read here to authorize
+// it and handed to the handler from the stream
+func (a *authenticator) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if strings.HasPrefix(info.FullMethod, "/grpc.reflection.") {
+		return handler(srv, ss)
+	}
+
+	newRequest, ok := streamRequests[info.FullMethod]
+	if !ok {
+		log.Errorf("no access rule for %s", info.FullMethod)
+		return errForbidden
+	}
+
+	req := newRequest()
+	if err := ss.RecvMsg(req); err != nil {
+		return err
+	}
+
+	ctx, err := a.admit(ss.Context(), info.FullMethod, req)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &admittedStream{ServerStream: ss, ctx: ctx, req: req})
+}
+
+// A server stream whose request was already read by the interceptor
+type admittedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req proto.Message
+}
+
+func (s *admittedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *admittedStream) RecvMsg(m interface{}) error {
+	if s.req == nil {
+		return status.Error(codes.Internal, "the request was already read")
+	}
+
+	proto.Merge(m.(proto.Message), s.req)
+	s.req = nil
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/internal/ratelimit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Refuses every token, like an attacker guessing them would see
+type rejectingVerifier struct{}
+
+func (rejectingVerifier) VerifyToken(ctx context.Context, rawToken string) (identity.Identity, error) {
+	return identity.Identity{}, errors.New("bad token")
+}
+
+func TestAddressRateLimitBeforeAuthentication(t *testing.T) {
+	limit := ratelimit.Limit{Burst: 2, Period: time.Minute}
+
+	a := &authenticator{
+		verifier: rejectingVerifier{},
+		limits: Limits{
+			Store:     ratelimit.NewMemoryStore(),
+			Addresses: limit,
+			Requests:  limit,
+			Answers:   limit,
+		},
+	}
+
+	tests := []struct {
+		name string
+		addr string
+		want codes.Code
+	}{
+		{"first bad token", "192.0.2.1:1000", codes.Unauthenticated},
+		{"second bad token", "192.0.2.1:1001", codes.Unauthenticated},
+		{"third bad token is throttled", "192.0.2.1:1002", codes.ResourceExhausted},
+		{"other address", "192.0.2.2:1000", codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := net.ResolveTCPAddr("tcp", tt.addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer guess"))
+
+			_, err = a.admit(ctx, "/scoping.v1.UserService/GetUser", nil)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("got code %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
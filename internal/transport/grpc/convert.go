@@ -0,0 +1,242 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	outline "github.com/zzenonn/scoping-ai/internal/outline"
+	questionSet "github.com/zzenonn/scoping-ai/internal/question-set"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+	pb "github.com/zzenonn/scoping-ai/pkg/pb/scopingai/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Defaults the REST API uses for missing paging query parameters
+const (
+	defaultPage     = 1
+	defaultPageSize = 10
+)
+
+// Soft deleted records are only listed to admins and API keys, like on the
+// REST API
+func paging(ctx context.Context, page *pb.PageRequest) (int, int, bool) {
+	p, pageSize := int(page.GetPage()), int(page.GetPageSize())
+
+	if p < 1 {
+		p = defaultPage
+	}
+
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	ident, _ := identity.FromContext(ctx)
+	admin := ident.IsAPIKey() || ident.HasRole(scopingUser.RoleAdmin)
+
+	return p, pageSize, admin && page.GetIncludeDeleted()
+}
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// Empty strings stand for fields the message left out
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func toPbQuestions(questions []common.Question) []*pb.Question {
+	var pbQuestions []*pb.Question
+
+	for _, question := range questions {
+		pbQuestions = append(pbQuestions, toPbQuestion(&question))
+	}
+
+	return pbQuestions
+}
+
+func toPbQuestion(question *common.Question) *pb.Question {
+	if question == nil {
+		return nil
+	}
+
+	pbQuestion := &pb.Question{
+		Category: question.Category,
+		Text:     question.Text,
+	}
+
+	if question.Options != nil {
+		pbQuestion.Options = &pb.Options{
+			MultiAnswer:     question.Options.MultiAnswer,
+			PossibleOptions: question.Options.PossibleOptions,
+		}
+	}
+
+	return pbQuestion
+}
+
+func fromPbQuestions(pbQuestions []*pb.Question) []common.Question {
+	var questions []common.Question
+
+	for _, pbQuestion := range pbQuestions {
+		questions = append(questions, *fromPbQuestion(pbQuestion))
+	}
+
+	return questions
+}
+
+func fromPbQuestion(pbQuestion *pb.Question) *common.Question {
+	if pbQuestion == nil {
+		return nil
+	}
+
+	question := &common.Question{
+		Category: pbQuestion.Category,
+		Text:     pbQuestion.Text,
+	}
+
+	if pbQuestion.Options != nil {
+		question.Options = &common.Options{
+			MultiAnswer:     pbQuestion.Options.MultiAnswer,
+			PossibleOptions: pbQuestion.Options.PossibleOptions,
+		}
+	}
+
+	return question
+}
+
+func toPbQuestionSet(qSet questionSet.QuestionSet) *pb.QuestionSet {
+	return &pb.QuestionSet{
+		Id:             qSet.Id,
+		TechnologyName: value(qSet.TechnologyName),
+		Questions:      toPbQuestions(qSet.Questions),
+		DeletedAt:      toTimestamp(qSet.DeletedAt),
+		DeletedBy:      qSet.DeletedBy,
+		Version:        qSet.Version,
+	}
+}
+
+// Soft delete fields are managed by the service and never read from requests
+func fromPbQuestionSet(pbQSet *pb.QuestionSet) questionSet.QuestionSet {
+	return questionSet.QuestionSet{
+		Id:             pbQSet.GetId(),
+		TechnologyName: optional(pbQSet.GetTechnologyName()),
+		Questions:      fromPbQuestions(pbQSet.GetQuestions()),
+		Version:        pbQSet.GetVersion(),
+	}
+}
+
+func toPbCourseOutline(courseOutline outline.CourseOutline) *pb.CourseOutline {
+	return &pb.CourseOutline{
+		Id:             courseOutline.Id,
+		TechnologyName: value(courseOutline.TechnologyName),
+		CourseCode:     value(courseOutline.CourseCode),
+		CourseName:     value(courseOutline.CourseName),
+		Outline:        courseOutline.Outline,
+		DeletedAt:      toTimestamp(courseOutline.DeletedAt),
+		DeletedBy:      courseOutline.DeletedBy,
+		Version:        courseOutline.Version,
+	}
+}
+
+func fromPbCourseOutline(pbOutline *pb.CourseOutline) outline.CourseOutline {
+	return outline.CourseOutline{
+		Id:             pbOutline.GetId(),
+		TechnologyName: optional(pbOutline.GetTechnologyName()),
+		CourseCode:     optional(pbOutline.GetCourseCode()),
+		CourseName:     optional(pbOutline.GetCourseName()),
+		Outline:        pbOutline.Outline,
+		Version:        pbOutline.GetVersion(),
+	}
+}
+
+func toPbUser(user scopingUser.User) *pb.User {
+	return &pb.User{
+		Id:             user.ID,
+		Name:           value(user.Name),
+		EmailAddress:   value(user.EmailAddress),
+		Corporate:      user.Corporate,
+		OrganizationId: user.OrganizationId,
+		Roles:          user.Roles,
+		DeletedAt:      toTimestamp(user.DeletedAt),
+		DeletedBy:      user.DeletedBy,
+		Version:        user.Version,
+	}
+}
+
+func fromPbUser(pbUser *pb.User) scopingUser.User {
+	return scopingUser.User{
+		ID:             pbUser.GetId(),
+		Name:           optional(pbUser.GetName()),
+		EmailAddress:   optional(pbUser.GetEmailAddress()),
+		Corporate:      pbUser.GetCorporate(),
+		OrganizationId: pbUser.OrganizationId,
+		Roles:          pbUser.GetRoles(),
+		Version:        pbUser.GetVersion(),
+	}
+}
+
+func toPbAnswer(answer *scopingMessage.Answer) *pb.Answer {
+	if answer == nil {
+		return nil
+	}
+
+	return &pb.Answer{
+		Question:       toPbQuestion(answer.Question),
+		TechnologyName: answer.TechnologyName,
+		Answer:         answer.Answer,
+	}
+}
+
+func fromPbAnswer(pbAnswer *pb.Answer) *scopingMessage.Answer {
+	if pbAnswer == nil {
+		return nil
+	}
+
+	return &scopingMessage.Answer{
+		Question:       fromPbQuestion(pbAnswer.Question),
+		TechnologyName: pbAnswer.TechnologyName,
+		Answer:         pbAnswer.Answer,
+	}
+}
+
+func toPbMessage(message scopingMessage.Message) *pb.Message {
+	return &pb.Message{
+		Id:          message.Id,
+		UserId:      value(message.UserId),
+		MessageText: message.MessageText,
+		Answer:      toPbAnswer(message.Answer),
+		CreatedAt:   toTimestamp(message.CreatedAt),
+		UpdatedAt:   toTimestamp(message.UpdatedAt),
+		DeletedAt:   toTimestamp(message.DeletedAt),
+		DeletedBy:   message.DeletedBy,
+		Version:     message.Version,
+	}
+}
+
+// The user always comes from the request, like the path does on the REST API
+func fromPbMessage(userId string, pbMessage *pb.Message) scopingMessage.Message {
+	return scopingMessage.Message{
+		Id:          pbMessage.GetId(),
+		UserId:      &userId,
+		MessageText: pbMessage.MessageText,
+		Answer:      fromPbAnswer(pbMessage.GetAnswer()),
+		Version:     pbMessage.GetVersion(),
+	}
+}
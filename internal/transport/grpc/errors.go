@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errMissingId     = common.NewError(common.KindValidation, "ID is required")
+	errMissingUserId = common.NewError(common.KindValidation, "User ID is required")
+	errMissingIds    = common.NewError(common.KindValidation, "User ID and Message ID are required")
+)
+
+func codeForKind(kind common.Kind) codes.Code {
+	switch kind {
+	case common.KindNotFound:
+		return codes.NotFound
+	case common.KindConflict:
+		return codes.AlreadyExists
+	case common.KindValidation, common.KindUnprocessable, common.KindTooLarge:
+		return codes.InvalidArgument
+	case common.KindUnauthorized:
+		return codes.Unauthenticated
+	case common.KindForbidden:
+		return codes.PermissionDenied
+	case common.KindPreconditionFailed:
+		return codes.FailedPrecondition
+	case common.KindRateLimited:
+		return codes.ResourceExhausted
+	case common.KindUpstream:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// Converts a service error to a status with the code of its kind. Like on the
+// REST API, errors without a kind only show their message in the log.
+func toStatus(err error) error {
+	log.Error(err)
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	kind := common.KindOf(err)
+
+	var kindErr *common.Error
+	if !errors.As(err, &kindErr) {
+		return status.Error(codeForKind(kind), "internal server error")
+	}
+
+	return status.Error(codeForKind(kind), kindErr.Error())
+}
@@ -0,0 +1,192 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	pb "github.com/zzenonn/scoping-ai/pkg/pb/scopingai/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// How long SubmitAnswers waits for the recommendation to replace the pending
+// message. A failed prompt leaves the message pending.
+const answerWait = 2 * time.Minute
+
+type MessageService interface {
+	PostMessage(ctx context.Context, message scopingMessage.Message) (scopingMessage.Message, error)
+	PostAnswers(ctx context.Context, messages []scopingMessage.Message) (scopingMessage.Message, error)
+	GetMessage(ctx context.Context, messageId string, userId string) (scopingMessage.Message, error)
+	WaitForMessage(ctx context.Context, messageId string, userId string, wait time.Duration) (scopingMessage.Message, error)
+	GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int, includeDeleted bool) ([]scopingMessage.Message, error)
+	UpdateMessage(ctx context.Context, message scopingMessage.Message) (scopingMessage.Message, error)
+	DeleteMessage(ctx context.Context, messageId string, userId string) error
+	RestoreMessage(ctx context.Context, messageId string, userId string) error
+}
+
+type MessageServer struct {
+	pb.UnimplementedMessageServiceServer
+	messageService MessageService
+}
+
+func NewMessageServer(s MessageService) *MessageServer {
+	return &MessageServer{
+		messageService: s,
+	}
+}
+
+func (s *MessageServer) GetMessage(ctx context.Context, req *pb.GetMessageRequest) (*pb.Message, error) {
+	if req.GetUserId() == "" || req.GetId() == "" {
+		return nil, toStatus(errMissingIds)
+	}
+
+	message, err := s.messageService.GetMessage(ctx, req.GetId(), req.GetUserId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbMessage(message), nil
+}
+
+func (s *MessageServer) ListMessages(ctx context.Context, req *pb.ListMessagesRequest) (*pb.ListMessagesResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, toStatus(errMissingUserId)
+	}
+
+	page, pageSize, includeDeleted := paging(ctx, req.GetPage())
+
+	messages, err := s.messageService.GetAllUserMessages(ctx, req.GetUserId(), page, pageSize, includeDeleted)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.ListMessagesResponse{}
+	for _, message := range messages {
+		response.Messages = append(response.Messages, toPbMessage(message))
+	}
+
+	return response, nil
+}
+
+func (s *MessageServer) CreateMessage(ctx context.Context, req *pb.CreateMessageRequest) (*pb.Message, error) {
+	if req.GetUserId() == "" {
+		return nil, toStatus(errMissingUserId)
+	}
+
+	message := fromPbMessage(req.GetUserId(), req.GetMessage())
+
+	if err := validateEntity(&message); err != nil {
+		return nil, err
+	}
+
+	message, err := s.messageService.PostMessage(ctx, message)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbMessage(message), nil
+}
+
+func (s *MessageServer) UpdateMessage(ctx context.Context, req *pb.UpdateMessageRequest) (*pb.Message, error) {
+	message := fromPbMessage(req.GetUserId(), req.GetMessage())
+
+	if req.GetUserId() == "" || message.Id == "" {
+		return nil, toStatus(errMissingIds)
+	}
+
+	if err := validateEntity(&message); err != nil {
+		return nil, err
+	}
+
+	message, err := s.messageService.UpdateMessage(ctx, message)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbMessage(message), nil
+}
+
+func (s *MessageServer) DeleteMessage(ctx context.Context, req *pb.DeleteMessageRequest) (*emptypb.Empty, error) {
+	if req.GetUserId() == "" || req.GetId() == "" {
+		return nil, toStatus(errMissingIds)
+	}
+
+	if err := s.messageService.DeleteMessage(ctx, req.GetId(), req.GetUserId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *MessageServer) RestoreMessage(ctx context.Context, req *pb.RestoreMessageRequest) (*emptypb.Empty, error) {
+	if req.GetUserId() == "" || req.GetId() == "" {
+		return nil, toStatus(errMissingIds)
+	}
+
+	if err := s.messageService.RestoreMessage(ctx, req.GetId(), req.GetUserId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// Sends the pending message right away and the recommendation once it
+// replaces the pending message, then ends the stream
+func (s *MessageServer) SubmitAnswers(req *pb.SubmitAnswersRequest, stream grpc.ServerStreamingServer[pb.SubmitAnswersResponse]) error {
+	ctx := stream.Context()
+
+	if req.GetUserId() == "" {
+		return toStatus(errMissingUserId)
+	}
+
+	userId := req.GetUserId()
+
+	var messages []scopingMessage.Message
+	for _, answer := range req.GetAnswers() {
+		messages = append(messages, scopingMessage.Message{
+			UserId: &userId,
+			Answer: fromPbAnswer(answer),
+		})
+	}
+
+	if err := validateEntity(&messages); err != nil {
+		return err
+	}
+
+	message, err := s.messageService.PostAnswers(ctx, messages)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	if !message.IsPending() {
+		return stream.Send(&pb.SubmitAnswersResponse{Message: toPbMessage(message), Completed: true})
+	}
+
+	if err := stream.Send(&pb.SubmitAnswersResponse{Message: toPbMessage(message)}); err != nil {
+		return err
+	}
+
+	message, err = s.messageService.WaitForMessage(ctx, message.Id, userId, answerWait)
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if err != nil {
+		return toStatus(err)
+	}
+
+	if message.IsPending() {
+		log.Errorf("No recommendation replaced message %s after %s", message.Id, answerWait)
+		return status.Error(codes.DeadlineExceeded, "the recommendation is still pending")
+	}
+
+	return stream.Send(&pb.SubmitAnswersResponse{Message: toPbMessage(message), Completed: true})
+}
+
+func (s *MessageServer) register(server *grpc.Server) {
+	log.Debug("Registering the message service")
+	pb.RegisterMessageServiceServer(server, s)
+}
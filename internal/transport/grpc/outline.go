@@ -0,0 +1,121 @@
+package grpc
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	outline "github.com/zzenonn/scoping-ai/internal/outline"
+	pb "github.com/zzenonn/scoping-ai/pkg/pb/scopingai/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type CourseOutlineService interface {
+	PostCourseOutline(ctx context.Context, courseOutline outline.CourseOutline) (outline.CourseOutline, error)
+	GetCourseOutline(ctx context.Context, id string) (outline.CourseOutline, error)
+	GetCourseOutlinesByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]outline.CourseOutline, error)
+	GetAllCourseOutlines(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]outline.CourseOutline, error)
+	UpdateCourseOutline(ctx context.Context, courseOutline outline.CourseOutline) (outline.CourseOutline, error)
+	DeleteCourseOutline(ctx context.Context, id string) error
+	RestoreCourseOutline(ctx context.Context, id string) error
+}
+
+type CourseOutlineServer struct {
+	pb.UnimplementedCourseOutlineServiceServer
+	courseOutlineService CourseOutlineService
+}
+
+func NewCourseOutlineServer(s CourseOutlineService) *CourseOutlineServer {
+	return &CourseOutlineServer{
+		courseOutlineService: s,
+	}
+}
+
+func (s *CourseOutlineServer) GetCourseOutline(ctx context.Context, req *pb.GetCourseOutlineRequest) (*pb.CourseOutline, error) {
+	courseOutline, err := s.courseOutlineService.GetCourseOutline(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbCourseOutline(courseOutline), nil
+}
+
+// Filters the course outlines when both a filter name and value are sent
+func (s *CourseOutlineServer) ListCourseOutlines(ctx context.Context, req *pb.ListCourseOutlinesRequest) (*pb.ListCourseOutlinesResponse, error) {
+	page, pageSize, includeDeleted := paging(ctx, req.GetPage())
+
+	var courseOutlines []outline.CourseOutline
+	var err error
+
+	if req.GetFilterName() != "" && req.GetFilterValue() != "" {
+		courseOutlines, err = s.courseOutlineService.GetCourseOutlinesByFilter(ctx, page, pageSize, req.GetFilterName(), req.GetFilterValue(), includeDeleted)
+	} else {
+		courseOutlines, err = s.courseOutlineService.GetAllCourseOutlines(ctx, page, pageSize, includeDeleted)
+	}
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.ListCourseOutlinesResponse{}
+	for _, courseOutline := range courseOutlines {
+		response.CourseOutlines = append(response.CourseOutlines, toPbCourseOutline(courseOutline))
+	}
+
+	return response, nil
+}
+
+func (s *CourseOutlineServer) CreateCourseOutline(ctx context.Context, req *pb.CreateCourseOutlineRequest) (*pb.CourseOutline, error) {
+	courseOutline := fromPbCourseOutline(req.GetCourseOutline())
+
+	if err := validateEntity(&courseOutline); err != nil {
+		return nil, err
+	}
+
+	courseOutline, err := s.courseOutlineService.PostCourseOutline(ctx, courseOutline)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbCourseOutline(courseOutline), nil
+}
+
+func (s *CourseOutlineServer) UpdateCourseOutline(ctx context.Context, req *pb.UpdateCourseOutlineRequest) (*pb.CourseOutline, error) {
+	courseOutline := fromPbCourseOutline(req.GetCourseOutline())
+
+	if courseOutline.Id == "" {
+		return nil, toStatus(errMissingId)
+	}
+
+	if err := validateEntity(&courseOutline); err != nil {
+		return nil, err
+	}
+
+	courseOutline, err := s.courseOutlineService.UpdateCourseOutline(ctx, courseOutline)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbCourseOutline(courseOutline), nil
+}
+
+func (s *CourseOutlineServer) DeleteCourseOutline(ctx context.Context, req *pb.DeleteCourseOutlineRequest) (*emptypb.Empty, error) {
+	if err := s.courseOutlineService.DeleteCourseOutline(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *CourseOutlineServer) RestoreCourseOutline(ctx context.Context, req *pb.RestoreCourseOutlineRequest) (*emptypb.Empty, error) {
+	if err := s.courseOutlineService.RestoreCourseOutline(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *CourseOutlineServer) register(server *grpc.Server) {
+	log.Debug("Registering the course outline service")
+	pb.RegisterCourseOutlineServiceServer(server, s)
+}
@@ -0,0 +1,122 @@
+package grpc
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	questionSet "github.com/zzenonn/scoping-ai/internal/question-set"
+	pb "github.com/zzenonn/scoping-ai/pkg/pb/scopingai/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type QuestionSetService interface {
+	GetQuestionSet(ctx context.Context, technologyName string) (questionSet.QuestionSet, error)
+	GetQuestionSetByTechName(ctx context.Context, technologyName string) (questionSet.QuestionSet, error)
+	GetAllQuestionSets(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]questionSet.QuestionSet, error)
+	PostQuestionSet(ctx context.Context, questionSet questionSet.QuestionSet) (questionSet.QuestionSet, error)
+	UpdateQuestionSet(ctx context.Context, questionSet questionSet.QuestionSet) (questionSet.QuestionSet, error)
+	DeleteQuestionSet(ctx context.Context, id string) error
+	RestoreQuestionSet(ctx context.Context, id string) error
+}
+
+type QuestionSetServer struct {
+	pb.UnimplementedQuestionSetServiceServer
+	questionSetService QuestionSetService
+}
+
+func NewQuestionSetServer(s QuestionSetService) *QuestionSetServer {
+	return &QuestionSetServer{
+		questionSetService: s,
+	}
+}
+
+func (s *QuestionSetServer) GetQuestionSet(ctx context.Context, req *pb.GetQuestionSetRequest) (*pb.QuestionSet, error) {
+	qSet, err := s.questionSetService.GetQuestionSet(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbQuestionSet(qSet), nil
+}
+
+func (s *QuestionSetServer) GetQuestionSetByTechnologyName(ctx context.Context, req *pb.GetQuestionSetByTechnologyNameRequest) (*pb.QuestionSet, error) {
+	qSet, err := s.questionSetService.GetQuestionSetByTechName(ctx, req.GetTechnologyName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbQuestionSet(qSet), nil
+}
+
+func (s *QuestionSetServer) ListQuestionSets(ctx context.Context, req *pb.ListQuestionSetsRequest) (*pb.ListQuestionSetsResponse, error) {
+	page, pageSize, includeDeleted := paging(ctx, req.GetPage())
+
+	qSets, err := s.questionSetService.GetAllQuestionSets(ctx, page, pageSize, includeDeleted)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.ListQuestionSetsResponse{}
+	for _, qSet := range qSets {
+		response.QuestionSets = append(response.QuestionSets, toPbQuestionSet(qSet))
+	}
+
+	return response, nil
+}
+
+func (s *QuestionSetServer) CreateQuestionSet(ctx context.Context, req *pb.CreateQuestionSetRequest) (*pb.QuestionSet, error) {
+	qSet := fromPbQuestionSet(req.GetQuestionSet())
+
+	if err := validateEntity(&qSet); err != nil {
+		return nil, err
+	}
+
+	qSet, err := s.questionSetService.PostQuestionSet(ctx, qSet)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbQuestionSet(qSet), nil
+}
+
+// The version of the question set plays the part of If-Match on the REST API
+func (s *QuestionSetServer) UpdateQuestionSet(ctx context.Context, req *pb.UpdateQuestionSetRequest) (*pb.QuestionSet, error) {
+	qSet := fromPbQuestionSet(req.GetQuestionSet())
+
+	if qSet.Id == "" {
+		return nil, toStatus(errMissingId)
+	}
+
+	if err := validateEntity(&qSet); err != nil {
+		return nil, err
+	}
+
+	qSet, err := s.questionSetService.UpdateQuestionSet(ctx, qSet)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbQuestionSet(qSet), nil
+}
+
+func (s *QuestionSetServer) DeleteQuestionSet(ctx context.Context, req *pb.DeleteQuestionSetRequest) (*emptypb.Empty, error) {
+	if err := s.questionSetService.DeleteQuestionSet(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *QuestionSetServer) RestoreQuestionSet(ctx context.Context, req *pb.RestoreQuestionSetRequest) (*emptypb.Empty, error) {
+	if err := s.questionSetService.RestoreQuestionSet(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *QuestionSetServer) register(server *grpc.Server) {
+	log.Debug("Registering the question set service")
+	pb.RegisterQuestionSetServiceServer(server, s)
+}
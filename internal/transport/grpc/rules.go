@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+	pb "github.com/zzenonn/scoping-ai/pkg/pb/scopingai/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+var adminOnly = []string{scopingUser.RoleAdmin}
+
+// Mirrors the route guards of the REST API
+var rules = map[string]rule{
+	// Any user reads question sets and course outlines, only admins write them
+	pb.QuestionSetService_GetQuestionSet_FullMethodName:                 {resource: "question-sets"},
+	pb.QuestionSetService_GetQuestionSetByTechnologyName_FullMethodName: {resource: "question-sets"},
+	pb.QuestionSetService_ListQuestionSets_FullMethodName:               {resource: "question-sets"},
+	pb.QuestionSetService_CreateQuestionSet_FullMethodName:              {resource: "question-sets", write: true, roles: adminOnly},
+	pb.QuestionSetService_UpdateQuestionSet_FullMethodName:              {resource: "question-sets", write: true, roles: adminOnly},
+	pb.QuestionSetService_DeleteQuestionSet_FullMethodName:              {resource: "question-sets", write: true, roles: adminOnly},
+	pb.QuestionSetService_RestoreQuestionSet_FullMethodName:             {resource: "question-sets", write: true, roles: adminOnly},
+
+	pb.CourseOutlineService_GetCourseOutline_FullMethodName:     {resource: "course-outlines"},
+	pb.CourseOutlineService_ListCourseOutlines_FullMethodName:   {resource: "course-outlines"},
+	pb.CourseOutlineService_CreateCourseOutline_FullMethodName:  {resource: "course-outlines", write: true, roles: adminOnly},
+	pb.CourseOutlineService_UpdateCourseOutline_FullMethodName:  {resource: "course-outlines", write: true, roles: adminOnly},
+	pb.CourseOutlineService_DeleteCourseOutline_FullMethodName:  {resource: "course-outlines", write: true, roles: adminOnly},
+	pb.CourseOutlineService_RestoreCourseOutline_FullMethodName: {resource: "course-outlines", write: true, roles: adminOnly},
+
+	// Corporate managers read the users of their organization, users manage themselves
+	pb.UserService_GetUser_FullMethodName: {resource: "users", roles: []string{scopingUser.RoleCorporateManager}, user: func(req interface{}) string {
+		return req.(*pb.GetUserRequest).GetId()
+	}},
+	pb.UserService_ListUsers_FullMethodName:  {resource: "users", roles: []string{scopingUser.RoleAdmin, scopingUser.RoleCorporateManager}},
+	pb.UserService_CreateUser_FullMethodName: {resource: "users", write: true},
+	pb.UserService_UpdateUser_FullMethodName: {resource: "users", write: true, user: func(req interface{}) string {
+		return req.(*pb.UpdateUserRequest).GetUser().GetId()
+	}},
+	pb.UserService_DeleteUser_FullMethodName: {resource: "users", write: true, user: func(req interface{}) string {
+		return req.(*pb.DeleteUserRequest).GetId()
+	}},
+	pb.UserService_RestoreUser_FullMethodName: {resource: "users", write: true, roles: adminOnly},
+
+	// Trainers and corporate managers read recommendations, only the user writes them
+	pb.MessageService_GetMessage_FullMethodName:     {resource: "messages", roles: messageReaders, user: messageUser},
+	pb.MessageService_ListMessages_FullMethodName:   {resource: "messages", roles: messageReaders, user: messageUser},
+	pb.MessageService_CreateMessage_FullMethodName:  {resource: "messages", write: true, user: messageUser},
+	pb.MessageService_UpdateMessage_FullMethodName:  {resource: "messages", write: true, user: messageUser},
+	pb.MessageService_DeleteMessage_FullMethodName:  {resource: "messages", write: true, user: messageUser},
+	pb.MessageService_RestoreMessage_FullMethodName: {resource: "messages", write: true, user: messageUser},
+	pb.MessageService_SubmitAnswers_FullMethodName:  {resource: "messages", write: true, user: messageUser},
+}
+
+var messageReaders = []string{scopingUser.RoleTrainer, scopingUser.RoleCorporateManager}
+
+// Every message request names the user it belongs to
+func messageUser(req interface{}) string {
+	return req.(interface{ GetUserId() string }).GetUserId()
+}
+
+// Methods that send answers to the LLM and count against its own budget
+var answerMethods = map[string]bool{
+	pb.MessageService_SubmitAnswers_FullMethodName: true,
+}
+
+// Request types of the server-streaming methods, read by the stream interceptor
+var streamRequests = map[string]func() proto.Message{
+	pb.MessageService_SubmitAnswers_FullMethodName: func() proto.Message { return &pb.SubmitAnswersRequest{} },
+}
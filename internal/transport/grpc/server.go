@@ -0,0 +1,122 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"net"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+// Each gRPC service registers itself on the server
+type Service interface {
+	register(server *grpc.Server)
+}
+
+// Serves the gRPC API next to the REST API. Callers authenticate and are
+// authorized and rate limited the same way as on the REST API.
+type Server struct {
+	Addr     string
+	Server   *grpc.Server
+	Services []Service
+
+	tls bool
+}
+
+func NewServer(addr string, verifier TokenVerifier, apiKeys APIKeyAuthenticator, policy AccessPolicy, limits Limits, options Options) *Server {
+	a := &authenticator{
+		verifier: verifier,
+		apiKeys:  apiKeys,
+		policy:   policy,
+		limits:   limits,
+	}
+
+	serverOptions := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(a.unaryInterceptor),
+		grpc.ChainStreamInterceptor(a.streamInterceptor),
+	}
+
+	if options.TLS != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(options.TLS)))
+	}
+
+	s := &Server{
+		Addr:   addr,
+		Server: grpc.NewServer(serverOptions...),
+		tls:    options.TLS != nil,
+	}
+
+	// Lets grpcurl and similar tools discover the services
+	if options.Reflection {
+		reflection.Register(s.Server)
+	}
+
+	return s
+}
+
+// How the server is exposed. Without TLS, bearer tokens and API keys cross the
+// network in plaintext, so the server should only listen on loopback or
+// behind a proxy that terminates TLS.
+type Options struct {
+	TLS        *tls.Config
+	Reflection bool
+}
+
+// Request limits shared with the REST API. Store may be nil to disable them.
+type Limits struct {
+	Store     ratelimit.Store
+	Addresses ratelimit.Limit
+	Requests  ratelimit.Limit
+	Answers   ratelimit.Limit
+}
+
+func (s *Server) AddService(service Service) {
+	s.Services = append(s.Services, service)
+	service.register(s.Server)
+}
+
+// Blocks until the server is stopped
+func (s *Server) Serve() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+
+	if addr, ok := listener.Addr().(*net.TCPAddr); ok && !s.tls && !addr.IP.IsLoopback() {
+		log.Warnf("serving gRPC without TLS on %s, credentials are sent in plaintext unless a proxy terminates TLS", s.Addr)
+	}
+
+	log.Infof("serving gRPC on %s", s.Addr)
+
+	return s.Server.Serve(listener)
+}
+
+// Waits for open calls, streams included, to finish
+func (s *Server) Stop() {
+	s.Server.GracefulStop()
+
+	log.Warn("gRPC server stopped gracefully")
+}
@@ -0,0 +1,152 @@
+package grpc
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+	pb "github.com/zzenonn/scoping-ai/pkg/pb/scopingai/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type UserService interface {
+	GetUser(ctx context.Context, id string) (scopingUser.User, error)
+	GetAllUsers(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]scopingUser.User, error)
+	GetUsersByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]scopingUser.User, error)
+	CreateUser(ctx context.Context, user scopingUser.User) (scopingUser.User, error)
+	UpdateUser(ctx context.Context, user scopingUser.User) (scopingUser.User, error)
+	DeleteUser(ctx context.Context, id string) error
+	RestoreUser(ctx context.Context, id string) error
+}
+
+type UserServer struct {
+	pb.UnimplementedUserServiceServer
+	userService UserService
+}
+
+func NewUserServer(s UserService) *UserServer {
+	return &UserServer{
+		userService: s,
+	}
+}
+
+func (s *UserServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	if req.GetId() == "" {
+		return nil, toStatus(errMissingUserId)
+	}
+
+	user, err := s.userService.GetUser(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbUser(user), nil
+}
+
+// Corporate managers only list the users of their own organization
+func (s *UserServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	page, pageSize, includeDeleted := paging(ctx, req.GetPage())
+
+	var users []scopingUser.User
+	var err error
+
+	ident, _ := identity.FromContext(ctx)
+	if ident.HasRole(scopingUser.RoleAdmin) || ident.IsAPIKey() {
+		users, err = s.userService.GetAllUsers(ctx, page, pageSize, includeDeleted)
+	} else {
+		var manager scopingUser.User
+		manager, err = s.userService.GetUser(ctx, ident.UID)
+		if err == nil && manager.OrganizationId == nil {
+			return nil, errForbidden
+		}
+		if err == nil {
+			users, err = s.userService.GetUsersByFilter(ctx, page, pageSize, "organization_id", *manager.OrganizationId, includeDeleted)
+		}
+	}
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.ListUsersResponse{}
+	for _, user := range users {
+		response.Users = append(response.Users, toPbUser(user))
+	}
+
+	return response, nil
+}
+
+func (s *UserServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.User, error) {
+	user := fromPbUser(req.GetUser())
+
+	if err := validateEntity(&user); err != nil {
+		return nil, err
+	}
+
+	// Only admins assign roles
+	if ident, _ := identity.FromContext(ctx); !ident.HasRole(scopingUser.RoleAdmin) {
+		user.Roles = nil
+	}
+
+	user, err := s.userService.CreateUser(ctx, user)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbUser(user), nil
+}
+
+// Replaces the profile of the user. Roles are kept unless an admin sends them.
+func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	user := fromPbUser(req.GetUser())
+
+	if user.ID == "" {
+		return nil, toStatus(errMissingUserId)
+	}
+
+	if err := validateEntity(&user); err != nil {
+		return nil, err
+	}
+
+	if ident, _ := identity.FromContext(ctx); !ident.HasRole(scopingUser.RoleAdmin) {
+		user.Roles = nil
+	}
+
+	user, err := s.userService.UpdateUser(ctx, user)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPbUser(user), nil
+}
+
+func (s *UserServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*emptypb.Empty, error) {
+	if req.GetId() == "" {
+		return nil, toStatus(errMissingUserId)
+	}
+
+	if err := s.userService.DeleteUser(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *UserServer) RestoreUser(ctx context.Context, req *pb.RestoreUserRequest) (*emptypb.Empty, error) {
+	if req.GetId() == "" {
+		return nil, toStatus(errMissingUserId)
+	}
+
+	if err := s.userService.RestoreUser(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *UserServer) register(server *grpc.Server) {
+	log.Debug("Registering the user service")
+	pb.RegisterUserServiceServer(server, s)
+}
@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Requests are checked against the same validate tags as REST request
+// bodies. Fields are reported by their JSON names, which match the proto
+// field names.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	return v
+}
+
+// Validates the entity converted from a request and lists the invalid fields
+// as bad request details of the status
+func validateEntity(v interface{}) error {
+	var err error
+	if value := reflect.Indirect(reflect.ValueOf(v)); value.Kind() == reflect.Slice {
+		err = validate.Var(value.Interface(), "dive")
+	} else {
+		err = validate.Struct(v)
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	if len(validationErrs) == 0 {
+		return nil
+	}
+
+	badRequest := &errdetails.BadRequest{}
+
+	for _, fieldErr := range validationErrs {
+		namespace := fieldErr.Namespace()
+		if index := strings.IndexAny(namespace, ".["); index >= 0 {
+			namespace = strings.TrimPrefix(namespace[index:], ".")
+		}
+
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       namespace,
+			Description: "failed the " + fieldErr.Tag() + " rule",
+		})
+	}
+
+	st, err := status.New(codes.InvalidArgument, "the request has invalid fields").WithDetails(badRequest)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "the request has invalid fields")
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,295 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	organization "github.com/zzenonn/scoping-ai/internal/organization"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+)
+
+// Looks up the user records of callers and of the users they access
+type UserDirectory interface {
+	GetUser(ctx context.Context, id string) (scopingUser.User, error)
+	ProvisionUser(ctx context.Context, ident identity.Identity) (scopingUser.User, error)
+}
+
+// Resolves the roles of callers and decides which users they may access.
+// Roles come from the "roles" token claim and fall back to the roles field
+// of the caller's user record. The corporate manager role is only held by
+// the managers of an organization, whatever the token or record says.
+type AccessPolicy struct {
+	users UserDirectory
+}
+
+type policyContextKey struct{}
+
+func newPolicyContext(ctx context.Context, policy *AccessPolicy) context.Context {
+	return context.WithValue(ctx, policyContextKey{}, policy)
+}
+
+func policyFromContext(ctx context.Context) *AccessPolicy {
+	policy, _ := ctx.Value(policyContextKey{}).(*AccessPolicy)
+	return policy
+}
+
+func NewAccessPolicy(users UserDirectory) *AccessPolicy {
+	return &AccessPolicy{
+		users: users,
+	}
+}
+
+func rolesFromClaims(claims map[string]interface{}) []string {
+	var roles []string
+
+	switch claim := claims["roles"].(type) {
+	case []interface{}:
+		for _, role := range claim {
+			if role, ok := role.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	case string:
+		roles = append(roles, claim)
+	}
+
+	return roles
+}
+
+// Corporate managers manage their own organization, so the role follows the
+// membership of the user instead of being granted globally
+func membershipRoles(roles []string, user scopingUser.User) []string {
+	resolved := make([]string, 0, len(roles)+1)
+	for _, role := range roles {
+		if role != scopingUser.RoleCorporateManager {
+			resolved = append(resolved, role)
+		}
+	}
+
+	if user.OrganizationId != nil && user.OrganizationRole == organization.MemberRoleManager {
+		resolved = append(resolved, scopingUser.RoleCorporateManager)
+	}
+
+	if len(resolved) == 0 {
+		resolved = append(resolved, scopingUser.RoleLearner)
+	}
+
+	return resolved
+}
+
+// Returns the roles of the caller, provisioning its user on the first request.
+// Tokens without an email address can't provision a user, those callers must
+// already have one.
+func (p *AccessPolicy) ResolveRoles(ctx context.Context, ident identity.Identity) ([]string, error) {
+	user, err := p.users.GetUser(ctx, ident.UID)
+	if errors.Is(err, scopingUser.ErrNotFound) {
+		user, err = p.users.ProvisionUser(ctx, ident)
+		if errors.Is(err, scopingUser.ErrMissingClaims) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if roles := rolesFromClaims(ident.Claims); len(roles) > 0 {
+		return membershipRoles(roles, user), nil
+	}
+
+	return membershipRoles(user.Roles, user), nil
+}
+
+// Corporate managers may access users of the organization they belong to
+func (p *AccessPolicy) sameOrganization(ctx context.Context, managerId string, userId string) bool {
+	if p == nil {
+		return false
+	}
+
+	manager, err := p.users.GetUser(ctx, managerId)
+	if err != nil || manager.OrganizationId == nil || manager.OrganizationRole != organization.MemberRoleManager {
+		return false
+	}
+
+	user, err := p.users.GetUser(ctx, userId)
+	if err != nil || user.OrganizationId == nil {
+		return false
+	}
+
+	return *manager.OrganizationId == *user.OrganizationId
+}
+
+// Whether the caller may access the user. Admins and API keys may access
+// every user, the given roles are also let through, with corporate managers
+// limited to users of their own organization.
+func (p *AccessPolicy) CanAccessUser(ctx context.Context, ident identity.Identity, userId string, roles ...string) bool {
+	if userId == ident.UID || ident.HasRole(scopingUser.RoleAdmin) || ident.IsAPIKey() {
+		return true
+	}
+
+	for _, role := range roles {
+		if !ident.HasRole(role) {
+			continue
+		}
+
+		if role != scopingUser.RoleCorporateManager || p.sameOrganization(ctx, ident.UID, userId) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rejects callers outside of the organization in the path. Admins and API keys
+// may access every organization. Must run after AuthMiddleware.
+func RequireOrganizationAccess(param string, roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ident, ok := identity.FromContext(r.Context())
+			if !ok {
+				log.Error("no identity in request context")
+				writeError(w, r, errNotAuthorized)
+				return
+			}
+
+			if ident.HasRole(scopingUser.RoleAdmin) || ident.IsAPIKey() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			policy := policyFromContext(r.Context())
+			if policy != nil && ident.HasRole(roles...) {
+				user, err := policy.users.GetUser(r.Context(), ident.UID)
+				if err == nil && user.OrganizationId != nil && *user.OrganizationId == chi.URLParam(r, param) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			log.Errorf("user %s is not allowed to access organization %s", ident.UID, chi.URLParam(r, param))
+			writeError(w, r, errForbidden)
+		})
+	}
+}
+
+// Rejects callers that hold none of the given roles. API keys are not checked
+// against roles, their scopes are checked by ScopeMiddleware instead. Must run
+// after AuthMiddleware.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ident, ok := identity.FromContext(r.Context())
+			if !ok {
+				log.Error("no identity in request context")
+				writeError(w, r, errNotAuthorized)
+				return
+			}
+
+			if !ident.IsAPIKey() && !ident.HasRole(roles...) {
+				log.Errorf("user %s lacks any of the roles %v", ident.UID, roles)
+				writeError(w, r, errForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Whether soft deleted records should be listed. Only admins and API keys,
+// which may also restore them, can ask for them, everyone else gets the
+// active records.
+func wantsDeleted(r *http.Request) bool {
+	includeDeleted, _ := strconv.ParseBool(r.URL.Query().Get("includeDeleted"))
+	if !includeDeleted {
+		return false
+	}
+
+	ident, _ := identity.FromContext(r.Context())
+	return ident.IsAPIKey() || ident.HasRole(scopingUser.RoleAdmin)
+}
+
+// Rejects requests for a user the caller may not access, see CanAccessUser.
+// Must run after AuthMiddleware.
+func RequireUserAccess(param string, roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ident, ok := identity.FromContext(r.Context())
+			if !ok {
+				log.Error("no identity in request context")
+				writeError(w, r, errNotAuthorized)
+				return
+			}
+
+			userId := chi.URLParam(r, param)
+			if policyFromContext(r.Context()).CanAccessUser(r.Context(), ident, userId, roles...) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log.Errorf("user %s is not allowed to access user %s", ident.UID, userId)
+			writeError(w, r, errForbidden)
+		})
+	}
+}
+
+// Rejects API keys without the read or write scope of the resource, depending
+// on the request method. Callers with an ID token are let through.
+func ScopeMiddleware(resource string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ident, ok := identity.FromContext(r.Context())
+			if !ok || !ident.IsAPIKey() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scope := resource + ":write"
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				scope = resource + ":read"
+			}
+
+			if !ident.HasScope(scope) {
+				log.Errorf("api key %s lacks the %s scope", ident.APIKeyId, scope)
+				writeError(w, r, errForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Builds the identity of a user as seen by the admin acting as them. Admins
+// can't be impersonated, so impersonation never grants more than the caller holds.
+func (p *AccessPolicy) impersonate(ctx context.Context, admin identity.Identity, userId string) (identity.Identity, error) {
+	user, err := p.users.GetUser(ctx, userId)
+	if err != nil {
+		return identity.Identity{}, err
+	}
+
+	if user.DeletedAt != nil {
+		return identity.Identity{}, scopingUser.ErrNotFound
+	}
+
+	ident := identity.Identity{
+		UID:            user.ID,
+		TenantId:       admin.TenantId,
+		Roles:          membershipRoles(user.Roles, user),
+		ImpersonatorId: admin.UID,
+	}
+
+	if user.EmailAddress != nil {
+		ident.Email = *user.EmailAddress
+	}
+
+	if ident.HasRole(scopingUser.RoleAdmin) {
+		return identity.Identity{}, errImpersonateAdmin
+	}
+
+	return ident, nil
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	organization "github.com/zzenonn/scoping-ai/internal/organization"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+)
+
+func TestWantsDeleted(t *testing.T) {
+	admin := identity.Identity{UID: "admin", Roles: []string{scopingUser.RoleAdmin}}
+	learner := identity.Identity{UID: "learner", Roles: []string{scopingUser.RoleLearner}}
+	integration := identity.Identity{UID: "crm", APIKeyId: "key-1"}
+
+	tests := []struct {
+		name   string
+		query  string
+		caller identity.Identity
+		want   bool
+	}{
+		{"active records by default", "", admin, false},
+		{"deleted records for admins", "?includeDeleted=true", admin, true},
+		{"deleted records for API keys", "?includeDeleted=true", integration, true},
+		{"no deleted records for others", "?includeDeleted=true", learner, false},
+		{"explicitly excluded", "?includeDeleted=false", admin, false},
+		{"invalid value", "?includeDeleted=maybe", admin, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/question-sets"+tt.query, nil)
+			r = r.WithContext(identity.NewContext(r.Context(), tt.caller))
+
+			if got := wantsDeleted(r); got != tt.want {
+				t.Errorf("wantsDeleted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Serves users from memory and provisions no one
+type memoryDirectory map[string]scopingUser.User
+
+func (users memoryDirectory) GetUser(ctx context.Context, id string) (scopingUser.User, error) {
+	user, ok := users[id]
+	if !ok {
+		return scopingUser.User{}, scopingUser.ErrNotFound
+	}
+	return user, nil
+}
+
+func (users memoryDirectory) ProvisionUser(ctx context.Context, ident identity.Identity) (scopingUser.User, error) {
+	return scopingUser.User{}, scopingUser.ErrMissingClaims
+}
+
+func TestMembershipRoles(t *testing.T) {
+	acme := "org-acme"
+
+	tests := []struct {
+		name  string
+		roles []string
+		user  scopingUser.User
+		want  []string
+	}{
+		{"no roles", nil, scopingUser.User{}, []string{scopingUser.RoleLearner}},
+		{"granted roles", []string{scopingUser.RoleTrainer}, scopingUser.User{}, []string{scopingUser.RoleTrainer}},
+		{"manager without organization", []string{scopingUser.RoleCorporateManager}, scopingUser.User{}, []string{scopingUser.RoleLearner}},
+		{"manager of organization", nil, scopingUser.User{OrganizationId: &acme, OrganizationRole: organization.MemberRoleManager}, []string{scopingUser.RoleCorporateManager}},
+		{"learner of organization", []string{scopingUser.RoleCorporateManager}, scopingUser.User{OrganizationId: &acme, OrganizationRole: organization.MemberRoleLearner}, []string{scopingUser.RoleLearner}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := membershipRoles(tt.roles, tt.user); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("membershipRoles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanAccessUser(t *testing.T) {
+	acme, globex := "org-acme", "org-globex"
+
+	policy := NewAccessPolicy(memoryDirectory{
+		"manager":   {ID: "manager", OrganizationId: &acme, OrganizationRole: organization.MemberRoleManager},
+		"colleague": {ID: "colleague", OrganizationId: &acme, OrganizationRole: organization.MemberRoleLearner},
+		"outsider":  {ID: "outsider", OrganizationId: &globex, OrganizationRole: organization.MemberRoleLearner},
+		"loner":     {ID: "loner"},
+	})
+
+	manager := identity.Identity{UID: "manager", Roles: []string{scopingUser.RoleCorporateManager}}
+	learner := identity.Identity{UID: "colleague", Roles: []string{scopingUser.RoleLearner}}
+	admin := identity.Identity{UID: "admin", Roles: []string{scopingUser.RoleAdmin}}
+	integration := identity.Identity{UID: "crm", APIKeyId: "key-1"}
+
+	tests := []struct {
+		name   string
+		caller identity.Identity
+		userId string
+		want   bool
+	}{
+		{"own user", learner, "colleague", true},
+		{"other user", learner, "manager", false},
+		{"admin", admin, "outsider", true},
+		{"API key", integration, "outsider", true},
+		{"manager of the same organization", manager, "colleague", true},
+		{"manager of another organization", manager, "outsider", false},
+		{"user outside of any organization", manager, "loner", false},
+		{"missing user", manager, "missing", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.CanAccessUser(context.Background(), tt.caller, tt.userId, scopingUser.RoleCorporateManager)
+			if got != tt.want {
+				t.Errorf("CanAccessUser(%s, %s) = %v, want %v", tt.caller.UID, tt.userId, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	log "github.com/sirupsen/logrus"
+	apikeys "github.com/zzenonn/scoping-ai/internal/apikey"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+// Resolves the API key sent in the X-API-Key header
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (apikeys.APIKey, error)
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, key apikeys.APIKey) (apikeys.IssuedAPIKey, error)
+	GetAPIKey(ctx context.Context, id string) (apikeys.APIKey, error)
+	GetAllAPIKeys(ctx context.Context, page int, pageSize int) ([]apikeys.APIKey, error)
+	RotateAPIKey(ctx context.Context, id string) (apikeys.IssuedAPIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+}
+
+type APIKeyHandler struct {
+	apiKeyService APIKeyService
+}
+
+func NewAPIKeyHandler(s APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: s,
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var key apikeys.APIKey
+
+	if err := decodeAndValidate(w, r, &key); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	issued, err := h.apiKeyService.CreateAPIKey(r.Context(), key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(issued); err != nil {
+		log.Error(err)
+		return
+	}
+}
+
+func (h *APIKeyHandler) GetAPIKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.apiKeyService.GetAPIKey(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(key); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *APIKeyHandler) GetAllAPIKeys(w http.ResponseWriter, r *http.Request) {
+	// Get page and pageSize from query parameters
+	pageStr := r.URL.Query().Get("page")
+	pageSizeStr := r.URL.Query().Get("pageSize")
+
+	// Convert them to integers with some default values
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	keys, err := h.apiKeyService.GetAllAPIKeys(r.Context(), page, pageSize)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(emptyIfNil(keys)); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	issued, err := h.apiKeyService.RotateAPIKey(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(issued); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if err := h.apiKeyService.RevokeAPIKey(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+}
+
+func (h *APIKeyHandler) mapRoutes(router chi.Router) {
+	router.Route("/api/v1/api-keys", func(r chi.Router) {
+		// No scope grants access to keys, so integrations can't manage them
+		r.Use(ScopeMiddleware("api-keys"))
+		r.Use(RequireRole(scopingUser.RoleAdmin))
+
+		r.Post("/", h.CreateAPIKey)
+		r.Get("/", h.GetAllAPIKeys)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.GetAPIKey)
+			r.Post("/rotate", h.RotateAPIKey)
+			r.Delete("/", h.RevokeAPIKey)
+		})
+	})
+}
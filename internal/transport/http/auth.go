@@ -5,8 +5,9 @@ import (
 	"os"
 	"strings"
 
-	firebase "firebase.google.com/go"
 	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/internal/ticket"
 )
 
 func init() {
@@ -27,20 +28,15 @@ func init() {
 
 }
 
-var firebaseApp *firebase.App
-
-func VerifyFirebaseToken(ctx context.Context, idToken string) bool {
-	client, err := firebaseApp.Auth(ctx)
-	if err != nil {
-		log.Errorf("error getting Auth client: %v\n", err)
-		return false
-	}
-
-	_, err = client.VerifyIDToken(ctx, idToken)
-	if err != nil {
-		log.Errorf("error verifying ID token: %v\n", err)
-		return false
-	}
+// Verifies bearer tokens and returns the identity of the caller. Implementations
+// for Firebase, OpenID Connect and shared secrets live in the auth package.
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, rawToken string) (identity.Identity, error)
+}
 
-	return true
+// Issues and redeems the tickets browsers open WebSockets with instead of
+// sending their ID token
+type TicketService interface {
+	IssueTicket(ctx context.Context, ident identity.Identity, path string) (ticket.IssuedTicket, error)
+	RedeemTicket(ctx context.Context, secret string, path string) (ticket.Ticket, error)
 }
@@ -0,0 +1,527 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+	"gopkg.in/yaml.v3"
+)
+
+// Imports are read whole, so they get a larger limit than other bodies
+const maxImportBytes = 10 << 20
+
+// Rows a single import may hold
+const maxImportRows = 1000
+
+const (
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+	formatYAML   = "yaml"
+)
+
+// Media types of each format. Imports accept every one of them, exports are
+// sent as the first.
+var formatMediaTypes = map[string][]string{
+	formatNDJSON: {"application/x-ndjson"},
+	formatCSV:    {"text/csv"},
+	formatYAML:   {"application/yaml", "application/x-yaml", "text/yaml"},
+}
+
+var (
+	errImportTooLarge = common.NewError(common.KindTooLarge, fmt.Sprintf("the import is larger than %d bytes", maxImportBytes))
+	errTooManyRows    = common.NewError(common.KindTooLarge, fmt.Sprintf("an import holds at most %d rows", maxImportRows))
+	errEmptyImport    = common.NewError(common.KindValidation, "the import has no rows")
+)
+
+// A row of an import as JSON, or the reason it couldn't be read. Rows are
+// numbered by the line of the file they start on.
+type importRecord struct {
+	line int
+	body []byte
+	err  error
+}
+
+// What became of a row of an import
+type ImportRow struct {
+	Row    int                 `json:"row"`
+	Id     string              `json:"id,omitempty"`
+	Key    string              `json:"key,omitempty"`
+	Action common.ImportAction `json:"action"`
+	Error  *ErrorResponse      `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun   bool        `json:"dry_run"`
+	Created  int         `json:"created"`
+	Updated  int         `json:"updated"`
+	Restored int         `json:"restored"`
+	Failed   int         `json:"failed"`
+	Rows     []ImportRow `json:"rows"`
+}
+
+func unsupportedImport(formats []string) error {
+	var mediaTypes []string
+	for _, format := range formats {
+		mediaTypes = append(mediaTypes, formatMediaTypes[format]...)
+	}
+
+	return common.NewError(common.KindValidation, "imports must be sent as one of: "+strings.Join(mediaTypes, ", "))
+}
+
+// Picks the format of an import from its Content-Type
+func importFormat(r *http.Request, formats []string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", unsupportedImport(formats)
+	}
+
+	for _, format := range formats {
+		for _, accepted := range formatMediaTypes[format] {
+			if mediaType == accepted {
+				return format, nil
+			}
+		}
+	}
+
+	return "", unsupportedImport(formats)
+}
+
+// Picks the format of an export from the format query parameter, NDJSON by
+// default
+func exportFormat(r *http.Request, formats ...string) (string, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		return formatNDJSON, nil
+	}
+
+	for _, supported := range formats {
+		if format == supported {
+			return format, nil
+		}
+	}
+
+	return "", common.NewError(common.KindValidation, "format must be one of: "+strings.Join(formats, ", "))
+}
+
+func invalidRow(err error) error {
+	return common.Wrap(common.KindValidation, "invalid row: "+err.Error(), err)
+}
+
+// Reads the rows of an import in one of the formats. CSV files name their
+// columns in a header, which may only hold the given columns.
+func readImport(w http.ResponseWriter, r *http.Request, columns []string, formats ...string) ([]importRecord, error) {
+	format, err := importFormat(r, formats)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, errImportTooLarge
+		}
+		return nil, invalidBody(err)
+	}
+
+	var records []importRecord
+
+	switch format {
+	case formatCSV:
+		records, err = readCSV(body, columns)
+	case formatYAML:
+		records, err = readYAML(body)
+	default:
+		records = readNDJSON(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, errEmptyImport
+	}
+	if len(records) > maxImportRows {
+		return nil, errTooManyRows
+	}
+
+	return records, nil
+}
+
+// Every line holds a JSON object, blank lines are skipped
+func readNDJSON(body []byte) []importRecord {
+	var records []importRecord
+
+	for i, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		records = append(records, importRecord{line: i + 1, body: line})
+	}
+
+	return records
+}
+
+// Cells become string fields named by their column. Empty cells are left out,
+// so required columns may not be blank.
+func readCSV(body []byte, columns []string) ([]importRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, invalidBody(err)
+	}
+
+	// Spreadsheets may start the file with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	seen := map[string]bool{}
+	for _, name := range header {
+		known := false
+		for _, column := range columns {
+			known = known || name == column
+		}
+
+		if !known || seen[name] {
+			return nil, common.NewError(common.KindValidation,
+				fmt.Sprintf("the CSV header may only hold the columns %s, each once", strings.Join(columns, ", ")))
+		}
+		seen[name] = true
+	}
+
+	var records []importRecord
+
+	for {
+		cells, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+
+		// A row with the wrong number of cells can be skipped, but the rest
+		// of the file can't be read past a malformed quote
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount) {
+			records = append(records, importRecord{line: parseErr.StartLine, err: invalidRow(parseErr.Err)})
+			continue
+		}
+		if err != nil {
+			return nil, invalidBody(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		row := map[string]string{}
+		for i, cell := range cells {
+			if cell != "" {
+				row[header[i]] = cell
+			}
+		}
+
+		rowJSON, err := json.Marshal(row)
+		records = append(records, importRecord{line: line, body: rowJSON, err: err})
+	}
+}
+
+// The document is a list with an item per row
+func readYAML(body []byte) ([]importRecord, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(body, &document); err != nil {
+		return nil, invalidBody(err)
+	}
+
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	list := document.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, common.NewError(common.KindValidation, "the YAML document must be a list")
+	}
+
+	var records []importRecord
+
+	for _, item := range list.Content {
+		record := importRecord{line: item.Line}
+
+		var value interface{}
+		if err := item.Decode(&value); err != nil {
+			record.err = invalidRow(err)
+		} else if record.body, err = json.Marshal(value); err != nil {
+			record.err = invalidRow(errors.New("keys must be strings"))
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// Decodes a row like a request body, rejecting unknown fields
+func decodeRow(body []byte, dst interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return invalidRow(err)
+	}
+
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return invalidRow(errors.New("a row must hold a single JSON value"))
+	}
+
+	return validateBody(dst)
+}
+
+// Decodes and validates the rows of an import. Returns the rows that passed
+// along with their lines, and reports the rest as failed.
+func decodeImportRows[T any](records []importRecord, key func(T) *string) ([]T, []int, []ImportRow) {
+	var items []T
+	var lines []int
+	var failed []ImportRow
+
+	for _, record := range records {
+		var item T
+
+		err := record.err
+		if err == nil {
+			err = decodeRow(record.body, &item)
+		}
+
+		if err != nil {
+			row := importRow(record.line, common.ImportFailure("", err))
+			if value := key(item); value != nil {
+				row.Key = *value
+			}
+
+			failed = append(failed, row)
+			continue
+		}
+
+		items = append(items, item)
+		lines = append(lines, record.line)
+	}
+
+	return items, lines, failed
+}
+
+func importRow(line int, result common.ImportResult) ImportRow {
+	row := ImportRow{
+		Row:    line,
+		Id:     result.Id,
+		Key:    result.Key,
+		Action: result.Action,
+	}
+
+	if result.Err != nil {
+		// Like in writeError, only errors with a kind are shown to the caller
+		if common.KindOf(result.Err) == common.KindInternal {
+			log.Error(result.Err)
+		}
+
+		response := errorResponse(result.Err)
+		row.Error = &response
+	}
+
+	return row
+}
+
+// Reports every row of the import in the order of the file. Results are in
+// the order of the rows that were imported, whose lines are given.
+func newImportReport(dryRun bool, lines []int, results []common.ImportResult, failed []ImportRow) ImportReport {
+	report := ImportReport{DryRun: dryRun, Rows: failed}
+
+	for i, result := range results {
+		report.Rows = append(report.Rows, importRow(lines[i], result))
+	}
+
+	sort.Slice(report.Rows, func(i, j int) bool {
+		return report.Rows[i].Row < report.Rows[j].Row
+	})
+
+	for _, row := range report.Rows {
+		switch row.Action {
+		case common.ImportCreated:
+			report.Created++
+		case common.ImportUpdated:
+			report.Updated++
+		case common.ImportRestored:
+			report.Restored++
+		default:
+			report.Failed++
+		}
+	}
+
+	return report
+}
+
+// Writes the rows of an export as they are read. Headers go out with the
+// first row, so an export that fails before then still gets an error response.
+type exportWriter struct {
+	w        http.ResponseWriter
+	format   string
+	filename string
+	columns  []string
+	csv      *csv.Writer
+	rows     int
+}
+
+// CSV exports hold the given columns
+func newExportWriter(w http.ResponseWriter, format string, name string, columns []string) *exportWriter {
+	return &exportWriter{
+		w:        w,
+		format:   format,
+		filename: name + "." + format,
+		columns:  columns,
+	}
+}
+
+func (export *exportWriter) start() error {
+	export.w.Header().Set("Content-Type", formatMediaTypes[export.format][0])
+	export.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.filename))
+
+	if export.format == formatCSV {
+		export.csv = csv.NewWriter(export.w)
+		return export.csv.Write(export.columns)
+	}
+
+	return nil
+}
+
+// Writes a resource as a row, with the fields of its JSON representation
+func (export *exportWriter) write(resource interface{}) error {
+	if export.rows == 0 {
+		if err := export.start(); err != nil {
+			return err
+		}
+	}
+	export.rows++
+
+	switch export.format {
+	case formatCSV:
+		return export.writeCSV(resource)
+	case formatYAML:
+		return export.writeYAML(resource)
+	default:
+		return json.NewEncoder(export.w).Encode(resource)
+	}
+}
+
+func (export *exportWriter) writeCSV(resource interface{}) error {
+	resourceJSON, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(resourceJSON, &fields); err != nil {
+		return err
+	}
+
+	cells := make([]string, len(export.columns))
+	for i, column := range export.columns {
+		if value, ok := fields[column]; ok && value != nil {
+			cells[i] = fmt.Sprint(value)
+		}
+	}
+
+	return export.csv.Write(cells)
+}
+
+// Every row is written as an item of a list. YAML reads JSON, so the
+// resource goes through its JSON form to keep the names and order of its
+// fields, then is written in block style.
+func (export *exportWriter) writeYAML(resource interface{}) error {
+	resourceJSON, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(resourceJSON, &document); err != nil {
+		return err
+	}
+
+	item := document.Content[0]
+	clearYAMLStyle(item)
+
+	out, err := yaml.Marshal(&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{item}})
+	if err != nil {
+		return err
+	}
+
+	_, err = export.w.Write(out)
+	return err
+}
+
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+
+	// Strings that older parsers read as another type, like "yes", are quoted
+	// when marshaled as values but not as nodes
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		if out, err := yaml.Marshal(node.Value); err == nil && (out[0] == '"' || out[0] == '\'') {
+			node.Style = yaml.DoubleQuotedStyle
+		}
+	}
+
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+// Ends the export, or reports why it failed
+func (export *exportWriter) finish(r *http.Request, err error) {
+	if err != nil && export.rows == 0 {
+		writeError(export.w, r, err)
+		return
+	}
+
+	if err != nil {
+		// The status went out with the first row, so the export is cut short
+		log.Error(err)
+		return
+	}
+
+	if export.rows == 0 {
+		if err := export.start(); err != nil {
+			log.Error(err)
+			return
+		}
+
+		if export.format == formatYAML {
+			if _, err := io.WriteString(export.w, "[]\n"); err != nil {
+				log.Error(err)
+			}
+		}
+	}
+
+	if export.csv != nil {
+		export.csv.Flush()
+		if err := export.csv.Error(); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+func writeImportReport(w http.ResponseWriter, report ImportReport) {
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
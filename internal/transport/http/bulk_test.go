@@ -0,0 +1,138 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+// What a test expects of a record: its line, its JSON body when it was read
+// and whether it failed
+type wantRecord struct {
+	line   int
+	body   string
+	failed bool
+}
+
+func recordsOf(records []importRecord) []wantRecord {
+	var got []wantRecord
+	for _, record := range records {
+		got = append(got, wantRecord{line: record.line, body: string(record.body), failed: record.err != nil})
+	}
+	return got
+}
+
+func TestReadNDJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []wantRecord
+	}{
+		{"empty", "", nil},
+		{"blank lines only", "\n  \n\n", nil},
+		{"one line per object", "{\"a\":1}\n{\"a\":2}\n", []wantRecord{{1, `{"a":1}`, false}, {2, `{"a":2}`, false}}},
+		{"blank lines keep line numbers", "\n{\"a\":1}\n\n{\"a\":2}", []wantRecord{{2, `{"a":1}`, false}, {4, `{"a":2}`, false}}},
+		{"surrounding space is trimmed", "  {\"a\":1}\r\n", []wantRecord{{1, `{"a":1}`, false}}},
+		{"invalid lines are left to decoding", "not json\n", []wantRecord{{1, "not json", false}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recordsOf(readNDJSON([]byte(tt.body))); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	columns := []string{"name", "email_address"}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []wantRecord
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"header only", "name,email_address\n", nil, false},
+		{
+			"rows",
+			"name,email_address\nAda,ada@example.com\nGrace,grace@example.com\n",
+			[]wantRecord{{2, `{"email_address":"ada@example.com","name":"Ada"}`, false}, {3, `{"email_address":"grace@example.com","name":"Grace"}`, false}},
+			false,
+		},
+		{"columns in any order", "email_address,name\nada@example.com,Ada\n", []wantRecord{{2, `{"email_address":"ada@example.com","name":"Ada"}`, false}}, false},
+		{"subset of columns", "name\nAda\n", []wantRecord{{2, `{"name":"Ada"}`, false}}, false},
+		{"empty cells are left out", "name,email_address\nAda,\n", []wantRecord{{2, `{"name":"Ada"}`, false}}, false},
+		{"byte order mark", "\ufeffname,email_address\nAda,ada@example.com\n", []wantRecord{{2, `{"email_address":"ada@example.com","name":"Ada"}`, false}}, false},
+		{"quoted cells", "name,email_address\n\"Lovelace, Ada\",ada@example.com\n", []wantRecord{{2, `{"email_address":"ada@example.com","name":"Lovelace, Ada"}`, false}}, false},
+		{"multiline cell keeps its starting line", "name,email_address\n\"Ada\nLovelace\",ada@example.com\nGrace,grace@example.com\n", []wantRecord{{2, `{"email_address":"ada@example.com","name":"Ada\nLovelace"}`, false}, {4, `{"email_address":"grace@example.com","name":"Grace"}`, false}}, false},
+		{"wrong cell count fails the row", "name,email_address\nAda\nGrace,grace@example.com\n", []wantRecord{{2, "", true}, {3, `{"email_address":"grace@example.com","name":"Grace"}`, false}}, false},
+		{"unknown column", "name,phone\nAda,555\n", nil, true},
+		{"repeated column", "name,name\nAda,Ada\n", nil, true},
+		{"malformed quote", "name,email_address\n\"Ada,ada@example.com\n", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := readCSV([]byte(tt.body), columns)
+			if tt.wantErr {
+				if common.KindOf(err) != common.KindValidation {
+					t.Fatalf("got error %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := recordsOf(records); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []wantRecord
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"empty list", "[]\n", nil, false},
+		{
+			"list of mappings",
+			"- name: Ada\n  email_address: ada@example.com\n- name: Grace\n",
+			[]wantRecord{{1, `{"email_address":"ada@example.com","name":"Ada"}`, false}, {3, `{"name":"Grace"}`, false}},
+			false,
+		},
+		{"nested values", "- name: Ada\n  roles: [admin, trainer]\n", []wantRecord{{1, `{"name":"Ada","roles":["admin","trainer"]}`, false}}, false},
+		{"scalars are left to decoding", "- 1\n- text\n", []wantRecord{{1, `1`, false}, {2, `"text"`, false}}, false},
+		{"non string keys fail the row", "- ? [a, b]\n  : Ada\n- name: Grace\n", []wantRecord{{1, "", true}, {3, `{"name":"Grace"}`, false}}, false},
+		{"not a list", "name: Ada\n", nil, true},
+		{"malformed", "- name: [Ada\n", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := readYAML([]byte(tt.body))
+			if tt.wantErr {
+				if common.KindOf(err) != common.KindValidation {
+					t.Fatalf("got error %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := recordsOf(records); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
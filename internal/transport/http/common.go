@@ -3,3 +3,11 @@ package http
 type Response struct {
 	Message string
 }
+
+// Lists are encoded as an empty array rather than null when nothing matched
+func emptyIfNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Cross-origin policy of the API. Origins are either exact, such as
+// https://app.example.com, or match any subdomain, such as https://*.example.com.
+type CorsConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+var errWildcardWithCredentials = errors.New("the * origin can't be combined with credentials")
+
+func DefaultCorsConfig() CorsConfig {
+	return CorsConfig{
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+		AllowedHeaders: []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "X-API-Key", "X-Impersonate-User", "If-Match", "Idempotency-Key"},
+		ExposedHeaders: []string{"ETag", "X-Request-Id", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", "Idempotent-Replayed"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Loads the policy from CORS_ALLOWED_ORIGINS, CORS_ALLOWED_METHODS,
+// CORS_ALLOWED_HEADERS, CORS_EXPOSED_HEADERS, CORS_ALLOW_CREDENTIALS and
+// CORS_MAX_AGE. Lists are comma separated, unset variables keep the defaults.
+// Without allowed origins no cross-origin request is allowed.
+func CorsConfigFromEnv() (CorsConfig, error) {
+	config := DefaultCorsConfig()
+
+	config.AllowedOrigins = splitList(os.Getenv("CORS_ALLOWED_ORIGINS"))
+
+	if methods := splitList(os.Getenv("CORS_ALLOWED_METHODS")); methods != nil {
+		config.AllowedMethods = methods
+	}
+	if headers := splitList(os.Getenv("CORS_ALLOWED_HEADERS")); headers != nil {
+		config.AllowedHeaders = headers
+	}
+	if headers := splitList(os.Getenv("CORS_EXPOSED_HEADERS")); headers != nil {
+		config.ExposedHeaders = headers
+	}
+
+	if value := os.Getenv("CORS_ALLOW_CREDENTIALS"); value != "" {
+		allowCredentials, err := strconv.ParseBool(value)
+		if err != nil {
+			return CorsConfig{}, err
+		}
+		config.AllowCredentials = allowCredentials
+	}
+
+	if value := os.Getenv("CORS_MAX_AGE"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil {
+			return CorsConfig{}, err
+		}
+		config.MaxAge = maxAge
+	}
+
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" && config.AllowCredentials {
+			return CorsConfig{}, errWildcardWithCredentials
+		}
+	}
+
+	if len(config.AllowedOrigins) == 0 {
+		log.Warn("CORS_ALLOWED_ORIGINS is not set, cross-origin requests are rejected")
+	}
+
+	return config, nil
+}
+
+func (config CorsConfig) originAllowed(origin string) bool {
+	for _, allowed := range config.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+
+		// https://*.example.com matches https://app.example.com but not https://example.com
+		prefix, suffix, ok := strings.Cut(strings.ToLower(allowed), "*.")
+		if !ok {
+			continue
+		}
+
+		lowerOrigin := strings.ToLower(origin)
+		if strings.HasPrefix(lowerOrigin, prefix) && strings.HasSuffix(lowerOrigin, "."+suffix) {
+			subdomain := strings.TrimSuffix(strings.TrimPrefix(lowerOrigin, prefix), "."+suffix)
+			if subdomain != "" && !strings.ContainsAny(subdomain, "/:") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func containsFold(items []string, value string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func CorsMiddleware(config CorsConfig) func(http.Handler) http.Handler {
+	allowedMethods := strings.Join(config.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(config.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(config.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Responses differ per origin, so caches must not share them
+			w.Header().Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if !config.originAllowed(origin) {
+				if preflight {
+					log.Errorf("cors preflight from disallowed origin %s", origin)
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+
+				// The browser withholds the response without the allow headers
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if containsFold(config.AllowedOrigins, "*") && !config.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+
+			if config.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposedHeaders != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+				}
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !containsFold(config.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) {
+				log.Errorf("cors preflight for disallowed method %s", r.Header.Get("Access-Control-Request-Method"))
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+			w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+
+			if config.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package http
+
+import "testing"
+
+func TestOriginAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{"no allowed origins", nil, "https://app.example.com", false},
+		{"exact match", []string{"https://app.example.com"}, "https://app.example.com", true},
+		{"case insensitive", []string{"https://App.Example.com"}, "https://app.EXAMPLE.com", true},
+		{"other origin", []string{"https://app.example.com"}, "https://evil.example.com", false},
+		{"other scheme", []string{"https://app.example.com"}, "http://app.example.com", false},
+		{"other port", []string{"https://app.example.com"}, "https://app.example.com:8443", false},
+		{"any origin", []string{"*"}, "https://evil.example.net", true},
+		{"wildcard subdomain", []string{"https://*.example.com"}, "https://app.example.com", true},
+		{"wildcard nested subdomain", []string{"https://*.example.com"}, "https://a.b.example.com", true},
+		{"wildcard case insensitive", []string{"https://*.Example.com"}, "https://APP.example.com", true},
+		{"wildcard skips the bare domain", []string{"https://*.example.com"}, "https://example.com", false},
+		{"wildcard other scheme", []string{"https://*.example.com"}, "http://app.example.com", false},
+		{"wildcard lookalike domain", []string{"https://*.example.com"}, "https://app.evilexample.com", false},
+		{"wildcard suffix attack", []string{"https://*.example.com"}, "https://app.example.com.evil.net", false},
+		{"wildcard with port", []string{"https://*.example.com"}, "https://app.example.com:8443", false},
+		{"wildcard with path", []string{"https://*.example.com"}, "https://evil.net/.example.com", false},
+		{"second entry matches", []string{"https://admin.example.com", "https://*.example.org"}, "https://app.example.org", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := CorsConfig{AllowedOrigins: tt.allowed}
+
+			if got := config.originAllowed(tt.origin); got != tt.want {
+				t.Errorf("originAllowed(%q) with %v = %v, want %v", tt.origin, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
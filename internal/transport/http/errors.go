@@ -0,0 +1,111 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+// Body of every error response
+type ErrorResponse struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestId string      `json:"request_id,omitempty"`
+}
+
+var (
+	errNotAuthorized   = common.NewError(common.KindUnauthorized, "not authorized")
+	errForbidden       = common.NewError(common.KindForbidden, "forbidden")
+	errTooManyRequests = common.NewError(common.KindRateLimited, "too many requests")
+	errMissingUserId   = common.NewError(common.KindValidation, "User ID is required")
+	errMissingIds      = common.NewError(common.KindValidation, "User ID and Message ID are required")
+	errRouteNotFound   = common.NewError(common.KindNotFound, "route not found")
+)
+
+func statusForKind(kind common.Kind) int {
+	switch kind {
+	case common.KindNotFound:
+		return http.StatusNotFound
+	case common.KindConflict:
+		return http.StatusConflict
+	case common.KindValidation:
+		return http.StatusBadRequest
+	case common.KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case common.KindUnauthorized:
+		return http.StatusUnauthorized
+	case common.KindForbidden:
+		return http.StatusForbidden
+	case common.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case common.KindRateLimited:
+		return http.StatusTooManyRequests
+	case common.KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case common.KindUpstream:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Builds the error envelope. Errors without a kind are reported as internal
+// errors without their message, it only goes to the log.
+func errorResponse(err error) ErrorResponse {
+	response := ErrorResponse{
+		Code:    string(common.KindOf(err)),
+		Message: "internal server error",
+	}
+
+	var kindErr *common.Error
+	if errors.As(err, &kindErr) {
+		response.Message = kindErr.Error()
+		response.Details = kindErr.Details
+	}
+
+	return response
+}
+
+// Writes the error envelope with the status of the error's kind
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Error(err)
+
+	response := errorResponse(err)
+	response.RequestId = middleware.GetReqID(r.Context())
+
+	renderError(w, statusForKind(common.KindOf(err)), response)
+}
+
+func renderError(w http.ResponseWriter, status int, response ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(err)
+	}
+}
+
+// No error kind stands for a method the route doesn't serve
+func writeMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	renderError(w, http.StatusMethodNotAllowed, ErrorResponse{
+		Code:      "method_not_allowed",
+		Message:   "method not allowed",
+		RequestId: middleware.GetReqID(r.Context()),
+	})
+}
+
+// Echoes the id chi assigned to the request so clients can quote it
+func RequestIdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requestId := middleware.GetReqID(r.Context()); requestId != "" {
+			w.Header().Set(middleware.RequestIDHeader, requestId)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
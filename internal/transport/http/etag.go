@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+// Versions are compared byte for byte, which weak tags don't promise
+var errWeakETag = common.NewError(common.KindPreconditionFailed, "If-Match requires a strong entity tag")
+
+// Writes the document version of a resource as a strong ETag
+func setETag(w http.ResponseWriter, version string) {
+	if version == "" {
+		return
+	}
+	w.Header().Set("ETag", `"`+version+`"`)
+}
+
+// Returns the version the client expects to update, taken from If-Match.
+// A missing header or a wildcard only requires the resource to exist. Weak
+// tags never match, as RFC 9110 requires strong comparison for If-Match.
+func ifMatchVersion(r *http.Request) (string, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return "", nil
+	}
+
+	// Only a single entity tag is supported
+	if strings.HasPrefix(ifMatch, "W/") {
+		return "", errWeakETag
+	}
+	return strings.Trim(ifMatch, `"`), nil
+}
+
+// Fails unless the version the client expects, if any, is the current one.
+// mismatch is the error of the resource for a stale version.
+func checkIfMatch(r *http.Request, version string, mismatch error) error {
+	expected, err := ifMatchVersion(r)
+	if err != nil {
+		return err
+	}
+	if expected != "" && expected != version {
+		return mismatch
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    string
+		wantErr error
+	}{
+		{"no header", "", "", nil},
+		{"wildcard", "*", "", nil},
+		{"strong tag", `"1700000000"`, "1700000000", nil},
+		{"surrounding spaces", ` "1700000000" `, "1700000000", nil},
+		{"weak tag", `W/"1700000000"`, "", errWeakETag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			got, err := ifMatchVersion(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got version %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		wantErr error
+	}{
+		{"no header", "", nil},
+		{"wildcard", "*", nil},
+		{"current version", `"2"`, nil},
+		{"stale version", `"1"`, scopingUser.ErrVersionMismatch},
+		{"weak current version", `W/"2"`, errWeakETag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			err := checkIfMatch(r, "2", scopingUser.ErrVersionMismatch)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	"github.com/zzenonn/scoping-ai/internal/tenant"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+var errNotWebSocket = common.NewError(common.KindValidation, "this route only accepts WebSocket connections")
+
+// Keeps connections through proxies that close idle ones and notices clients
+// that went away without closing
+const (
+	eventWriteWait  = 10 * time.Second
+	eventPongWait   = 60 * time.Second
+	eventPingPeriod = eventPongWait * 9 / 10
+)
+
+type MessageEventSubscriber interface {
+	Subscribe(tenantId string, userId string) (<-chan scopingMessage.Event, func())
+}
+
+// Browsers can't set headers on WebSocket connections. They get a ticket
+// first and offer it as a subprotocol after "ticket", which is the
+// subprotocol accepted. Browsers always send their origin, which must be
+// allowed by the CORS policy or be the API itself. Other clients send no
+// origin and authenticate with a header instead.
+func (h *MessageHandler) upgrader() websocket.Upgrader {
+	return websocket.Upgrader{
+		Subprotocols: []string{"ticket"},
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
+
+			if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+				return true
+			}
+
+			return h.cors.originAllowed(origin)
+		},
+	}
+}
+
+// Returns the ticket of a WebSocket connection offered as "ticket, <ticket>"
+func webSocketTicket(r *http.Request) (string, bool) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		return "", false
+	}
+
+	protocols := websocket.Subprotocols(r)
+	if len(protocols) != 2 || protocols[0] != "ticket" {
+		return "", false
+	}
+
+	return protocols[1], true
+}
+
+// Issues the ticket a browser opens the events of the user with. It is only
+// good for the events route it was issued for.
+func (h *MessageHandler) PostEventsTicket(w http.ResponseWriter, r *http.Request) {
+	ident, ok := identity.FromContext(r.Context())
+	if !ok {
+		writeError(w, r, errNotAuthorized)
+		return
+	}
+
+	issued, err := h.tickets.IssueTicket(r.Context(), ident, strings.TrimSuffix(r.URL.Path, "/tickets"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(issued); err != nil {
+		log.Error(err)
+	}
+}
+
+// Sends the messages of the user as they are created, updated and completed.
+// Clients read the current messages first, the connection only carries
+// changes. The connection is closed with "try again later" when the client
+// can't keep up, after which it reads the messages again and reconnects.
+func (h *MessageHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	userId := chi.URLParam(r, "userId")
+
+	if userId == "" {
+		writeError(w, r, errMissingUserId)
+		return
+	}
+
+	if !websocket.IsWebSocketUpgrade(r) {
+		writeError(w, r, errNotWebSocket)
+		return
+	}
+
+	tenantId, _ := tenant.FromContext(r.Context())
+
+	// The upgrade writes its own headers
+	w.Header().Del("Content-Type")
+
+	upgrader := h.upgrader()
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Errorf("Failed to upgrade the connection for the events of user %s. Error: %v", userId, err)
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := h.events.Subscribe(tenantId, userId)
+	defer unsubscribe()
+
+	// Clients send nothing but control frames, which are handled while reading
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(eventPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(eventPongWait))
+		})
+
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(eventPingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+
+		case event, ok := <-events:
+			conn.SetWriteDeadline(time.Now().Add(eventWriteWait))
+
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "fell behind"))
+				return
+			}
+
+			if err := conn.WriteJSON(event); err != nil {
+				log.Errorf("Failed to send a message event to user %s. Error: %v", userId, err)
+				return
+			}
+
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Mounts the GraphQL endpoint behind the same authentication and rate limits
+// as the REST routes. Resolvers check access to each resource themselves.
+type GraphQLHandler struct {
+	graphql http.Handler
+}
+
+func NewGraphQLHandler(graphql http.Handler) *GraphQLHandler {
+	return &GraphQLHandler{
+		graphql: graphql,
+	}
+}
+
+func (h *GraphQLHandler) mapRoutes(router chi.Router) {
+	router.Method("POST", "/api/v1/graphql", h.graphql)
+}
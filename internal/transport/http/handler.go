@@ -9,9 +9,9 @@ import (
 	"strings"
 	"time"
 
-	firebase "firebase.google.com/go"
 	logger "github.com/chi-middleware/logrus-logger"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -20,9 +20,18 @@ type Handler interface {
 }
 
 type MainHandler struct {
-	Router   chi.Router
-	Handlers []Handler
-	Server   *http.Server
+	Router       chi.Router
+	Handlers     []Handler
+	Server       *http.Server
+	Verifier     TokenVerifier
+	APIKeys      APIKeyAuthenticator
+	AccessPolicy *AccessPolicy
+	Cors         CorsConfig
+	RateLimiter  *RateLimiter
+	Addresses    *RateLimiter
+	Audit        AuditService
+	Idempotency  IdempotencyService
+	Tickets      TicketService
 }
 
 func init() {
@@ -43,19 +52,32 @@ func init() {
 
 }
 
-func NewMainHandler(app *firebase.App) *MainHandler {
+func NewMainHandler(verifier TokenVerifier, apiKeys APIKeyAuthenticator, policy *AccessPolicy, cors CorsConfig, limiter *RateLimiter, addressLimiter *RateLimiter, auditService AuditService, idempotencyService IdempotencyService, tickets TicketService) *MainHandler {
 	h := &MainHandler{
-		Handlers: []Handler{},
+		Handlers:     []Handler{},
+		Verifier:     verifier,
+		APIKeys:      apiKeys,
+		AccessPolicy: policy,
+		Cors:         cors,
+		RateLimiter:  limiter,
+		Addresses:    addressLimiter,
+		Audit:        auditService,
+		Idempotency:  idempotencyService,
+		Tickets:      tickets,
 	}
 
 	h.Router = chi.NewRouter()
 
 	h.Router.Use()
 
+	// Error responses quote the request id so failures can be found in the logs
+	h.Router.Use(middleware.RequestID)
+	h.Router.Use(RequestIdMiddleware)
+
 	// h.Router.Use(CorsMiddleware)
 
 	h.Router.Use(logger.Logger("router", log.New()))
-	// h.Router.Use(JSONMiddleware)
+	h.Router.Use(JSONMiddleware)
 	// h.Router.Use(TimeoutMiddleware)
 
 	h.Server = &http.Server{
@@ -63,8 +85,6 @@ func NewMainHandler(app *firebase.App) *MainHandler {
 		Handler: h.Router,
 	}
 
-	firebaseApp = app
-
 	return h
 }
 
@@ -91,18 +111,34 @@ func (h *MainHandler) MapRoutes() {
 	// 	AllowCredentials: true,
 	// })
 
-	h.Router.Use(CorsMiddleware)
+	h.Router.Use(CorsMiddleware(h.Cors))
 	h.Router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		fmt.Fprintf(w, "The API is up")
 	})
 
-	for _, handler := range h.Handlers {
-		handler.mapRoutes(h.Router)
-		chi.Walk(h.Router, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
-			log.Debugf("[%s]: '%s' has %d middlewares\n", method, route, len(middlewares))
-			return nil
-		})
-	}
+	h.Router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, errRouteNotFound)
+	})
+	h.Router.MethodNotAllowed(writeMethodNotAllowed)
+
+	// Every API route requires an authenticated caller
+	h.Router.Group(func(r chi.Router) {
+		r.Use(h.Addresses.Middleware)
+		r.Use(AuthMiddleware(h.Verifier, h.APIKeys, h.AccessPolicy, h.Tickets))
+		r.Use(h.RateLimiter.Middleware)
+		r.Use(ImpersonationMiddleware(h.Audit))
+		r.Use(IdempotencyMiddleware(h.Idempotency))
+
+		for _, handler := range h.Handlers {
+			handler.mapRoutes(r)
+		}
+	})
+
+	chi.Walk(h.Router, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		log.Debugf("[%s]: '%s' has %d middlewares\n", method, route, len(middlewares))
+		return nil
+	})
 
 }
 
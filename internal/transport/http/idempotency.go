@@ -0,0 +1,194 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/idempotency"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	replayedHeader       = "Idempotent-Replayed"
+)
+
+// Response headers kept along with the body of the response
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+type IdempotencyService interface {
+	Begin(ctx context.Context, scope string, key string, fingerprint string) (idempotency.Record, bool, error)
+	Hold(ctx context.Context, record idempotency.Record) func()
+	Complete(ctx context.Context, record idempotency.Record, status int, header map[string]string, body []byte) error
+	Release(ctx context.Context, record idempotency.Record) error
+}
+
+// Keeps what a handler writes so it can be replayed
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(p []byte) (int, error) {
+	rec.body.Write(p)
+	return rec.ResponseWriter.Write(p)
+}
+
+func (rec *responseRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Identifies the request a key was used for. JSON bodies are hashed in a
+// canonical form, so retries that only differ in formatting or in the order
+// of object members still match.
+func requestFingerprint(r *http.Request, body []byte) string {
+	if canonical, ok := canonicalJSON(body); ok {
+		body = canonical
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(r.URL.RawQuery + "\x00"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Encodes the JSON values of the body, one per line as in NDJSON imports,
+// with the members of their objects sorted. Numbers are kept as they were
+// written.
+func canonicalJSON(body []byte) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var canonical bytes.Buffer
+
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			return canonical.Bytes(), canonical.Len() > 0
+		}
+		if err != nil {
+			return nil, false
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, false
+		}
+
+		canonical.Write(encoded)
+		canonical.WriteByte('\n')
+	}
+}
+
+// Whether a retry of the same request may get another response, because the
+// request was refused for the moment rather than for what it holds
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout,
+		http.StatusConflict, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	}
+
+	return status >= http.StatusInternalServerError
+}
+
+// Serves a POST with an Idempotency-Key header once. Retries with the same
+// key get the first response again, marked with Idempotent-Replayed, and the
+// same key with another body is refused. Keys belong to the caller and the
+// route. Responses a retry may not get, such as server errors and throttled
+// requests, aren't kept, so the request can be retried. Must run after
+// AuthMiddleware.
+func IdempotencyMiddleware(service IdempotencyService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if service == nil || key == "" || r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Imports are the largest bodies. Handlers still apply their own limit.
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBytes))
+			if err != nil {
+				writeError(w, r, invalidBody(err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			ident, _ := identity.FromContext(r.Context())
+			scope := ident.ImpersonatorId + "/" + ident.UID + " " + r.Method + " " + r.URL.Path
+
+			record, replay, err := service.Begin(r.Context(), scope, key, requestFingerprint(r, body))
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			if replay {
+				for name, value := range record.Header {
+					w.Header().Set(name, value)
+				}
+				w.Header().Set(replayedHeader, "true")
+				w.WriteHeader(record.Status)
+
+				if _, err := w.Write(record.Body); err != nil {
+					log.Error(err)
+				}
+				return
+			}
+
+			// The key is settled even when the client has already gone away
+			ctx := context.WithoutCancel(r.Context())
+
+			// Long requests, such as those calling the LLM, keep the key
+			// until they are done. A panicking handler stops holding it too.
+			stopHolding := service.Hold(ctx, record)
+			defer stopHolding()
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			stopHolding()
+
+			if retryableStatus(rec.status) {
+				if err := service.Release(ctx, record); err != nil {
+					log.Error(err)
+				}
+				return
+			}
+
+			header := map[string]string{}
+			for _, name := range replayedHeaders {
+				if value := w.Header().Get(name); value != "" {
+					header[name] = value
+				}
+			}
+
+			if err := service.Complete(ctx, record, rec.status, header, rec.body.Bytes()); err != nil {
+				log.Error(err)
+
+				// A key that was neither completed nor released would refuse
+				// every retry until it times out
+				if err := service.Release(ctx, record); err != nil {
+					log.Error(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	fingerprint := func(target string, body string) string {
+		return requestFingerprint(httptest.NewRequest(http.MethodPost, target, nil), []byte(body))
+	}
+
+	base := fingerprint("/api/v1/users?dryRun=true", `{"name":"Ada","email_address":"ada@example.com"}`)
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		same   bool
+	}{
+		{"same request", "/api/v1/users?dryRun=true", `{"name":"Ada","email_address":"ada@example.com"}`, true},
+		{"reformatted JSON", "/api/v1/users?dryRun=true", "{\n  \"name\": \"Ada\",\n  \"email_address\": \"ada@example.com\"\n}\n", true},
+		{"other body", "/api/v1/users?dryRun=true", `{"name":"Grace","email_address":"ada@example.com"}`, false},
+		{"reordered members", "/api/v1/users?dryRun=true", `{"email_address":"ada@example.com","name":"Ada"}`, true},
+		{"escaped characters", "/api/v1/users?dryRun=true", `{"name":"\u0041da","email_address":"ada@example.com"}`, true},
+		{"other query", "/api/v1/users?dryRun=false", `{"name":"Ada","email_address":"ada@example.com"}`, false},
+		{"no query", "/api/v1/users", `{"name":"Ada","email_address":"ada@example.com"}`, false},
+		{"query moved into body", "/api/v1/users", `dryRun=true{"name":"Ada","email_address":"ada@example.com"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprint(tt.target, tt.body) == base; got != tt.same {
+				t.Errorf("fingerprint matches = %v, want %v", got, tt.same)
+			}
+		})
+	}
+
+	// Bodies that aren't JSON, such as CSV imports, are hashed as sent
+	if fingerprint("/import", "a,b\n1,2\n") == fingerprint("/import", "a,b\n1, 2\n") {
+		t.Error("fingerprints of different CSV bodies match")
+	}
+
+	// Numbers keep their precision and arrays their order
+	if fingerprint("/answers", `{"n":10000000000000001}`) == fingerprint("/answers", `{"n":10000000000000000}`) {
+		t.Error("fingerprints of different large numbers match")
+	}
+	if fingerprint("/answers", `[1,2]`) == fingerprint("/answers", `[2,1]`) {
+		t.Error("fingerprints of reordered arrays match")
+	}
+
+	// Every value of an NDJSON import is canonicalized, but not their order
+	if fingerprint("/import", "{\"b\":1,\"a\":2}\n{}") != fingerprint("/import", "{\"a\":2, \"b\":1}\n\n{}\n") {
+		t.Error("fingerprints of the same NDJSON values differ")
+	}
+	if fingerprint("/import", "{\"a\":1}\n{\"a\":2}") == fingerprint("/import", "{\"a\":2}\n{\"a\":1}") {
+		t.Error("fingerprints of reordered NDJSON lines match")
+	}
+}
+
+func TestRetryableStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusCreated, false},
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, true},
+		{http.StatusForbidden, true},
+		{http.StatusNotFound, false},
+		{http.StatusConflict, true},
+		{http.StatusPreconditionFailed, false},
+		{http.StatusUnprocessableEntity, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			if got := retryableStatus(tt.status); got != tt.want {
+				t.Errorf("retryableStatus(%d) = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/audit"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+// Admins act as another user by naming them in this header
+const impersonationHeader = "X-Impersonate-User"
+
+var (
+	errImpersonateAdmin = common.NewError(common.KindForbidden, "admins can't be impersonated")
+	errAuditFailed      = common.NewError(common.KindInternal, "the request could not be audited")
+)
+
+type AuditService interface {
+	RecordAction(ctx context.Context, record audit.Record) (audit.Record, error)
+	GetRecords(ctx context.Context, page int, pageSize int) ([]audit.Record, error)
+}
+
+// Swaps the identity of an admin for the user named in the X-Impersonate-User
+// header. The admin stays in the identity as the impersonator and every
+// impersonated request is written to the audit trail before it is served, so
+// none is served unaudited. Refused attempts are written to it as well.
+// Must run after AuthMiddleware.
+func ImpersonationMiddleware(auditService AuditService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userId := r.Header.Get(impersonationHeader)
+			if userId == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			admin, ok := identity.FromContext(r.Context())
+			if !ok {
+				log.Error("no identity in request context")
+				writeError(w, r, errNotAuthorized)
+				return
+			}
+
+			// Refused attempts are audited too, with the status they get
+			refuse := func(err error) {
+				status := statusForKind(common.KindOf(err))
+				if auditErr := recordImpersonation(r, auditService, audit.ActionImpersonateDenied, admin.UID, userId, status); auditErr != nil {
+					err = auditErr
+				}
+				writeError(w, r, err)
+			}
+
+			policy := policyFromContext(r.Context())
+			if admin.IsAPIKey() || !admin.HasRole(scopingUser.RoleAdmin) || policy == nil {
+				log.Errorf("%s is not allowed to impersonate users", admin.UID)
+				refuse(errForbidden)
+				return
+			}
+
+			ident, err := policy.impersonate(r.Context(), admin, userId)
+			if err != nil {
+				log.Errorf("%s was refused impersonating %s: %v", admin.UID, userId, err)
+				refuse(err)
+				return
+			}
+
+			if err := recordImpersonation(r, auditService, audit.ActionImpersonate, admin.UID, ident.UID, 0); err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			log.Infof("%s is impersonating %s", admin.UID, ident.UID)
+
+			next.ServeHTTP(w, r.WithContext(identity.NewContext(r.Context(), ident)))
+		})
+	}
+}
+
+// Requests are only served or refused once they are in the audit trail.
+// Impersonated requests are recorded before they are served, without a status.
+func recordImpersonation(r *http.Request, auditService AuditService, action string, actorId string, subjectId string, status int) error {
+	record := audit.Record{
+		Action:    action,
+		ActorId:   actorId,
+		SubjectId: subjectId,
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Status:    status,
+	}
+
+	// The record is kept even when the client has already gone away
+	if _, err := auditService.RecordAction(context.WithoutCancel(r.Context()), record); err != nil {
+		log.Errorf("failed to audit %s of %s by %s: %v", action, subjectId, actorId, err)
+		return errAuditFailed
+	}
+
+	return nil
+}
+
+type AuditHandler struct {
+	auditService AuditService
+}
+
+func NewAuditHandler(s AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: s,
+	}
+}
+
+func (h *AuditHandler) GetRecords(w http.ResponseWriter, r *http.Request) {
+	// Get page and pageSize from query parameters
+	pageStr := r.URL.Query().Get("page")
+	pageSizeStr := r.URL.Query().Get("pageSize")
+
+	// Convert them to integers with some default values
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	records, err := h.auditService.GetRecords(r.Context(), page, pageSize)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(emptyIfNil(records)); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *AuditHandler) mapRoutes(router chi.Router) {
+	router.Route("/api/v1/audit-records", func(r chi.Router) {
+		// No scope grants access to the audit trail and impersonated
+		// requests never carry the admin role
+		r.Use(ScopeMiddleware("audit"))
+		r.Use(RequireRole(scopingUser.RoleAdmin))
+
+		r.Get("/", h.GetRecords)
+	})
+}
@@ -7,12 +7,24 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	log "github.com/sirupsen/logrus"
 	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+	"github.com/zzenonn/scoping-ai/pkg/common"
 )
 
+// Longest a request may wait for a pending message, short enough for the
+// proxies in front of the API, and how soon to ask again after it
+const (
+	maxMessageWait    = 60 * time.Second
+	messageRetryAfter = 2 * time.Second
+)
+
+var errInvalidWait = common.NewError(common.KindValidation, "wait must be a duration of at most 60s, such as 30s")
+
 func init() {
 
 	// Set log level based on environment variables
@@ -35,18 +47,31 @@ type MessageServiceInterface interface {
 	PostMessage(ctx context.Context, message scopingMessage.Message) (scopingMessage.Message, error)
 	PostAnswers(ctx context.Context, messages []scopingMessage.Message) (scopingMessage.Message, error)
 	GetMessage(ctx context.Context, messageId string, userId string) (scopingMessage.Message, error)
-	GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int) ([]scopingMessage.Message, error)
+	WaitForMessage(ctx context.Context, messageId string, userId string, wait time.Duration) (scopingMessage.Message, error)
+	GetAllUserMessages(ctx context.Context, userId string, page int, pageSize int, includeDeleted bool) ([]scopingMessage.Message, error)
 	UpdateMessage(ctx context.Context, message scopingMessage.Message) (scopingMessage.Message, error)
 	DeleteMessage(ctx context.Context, messageId string, userId string) error
+	RestoreMessage(ctx context.Context, messageId string, userId string) error
 }
 
 type MessageHandler struct {
 	messageService MessageServiceInterface
+	answersLimiter *RateLimiter
+	events         MessageEventSubscriber
+	tickets        TicketService
+	cors           CorsConfig
 }
 
-func NewMessageHandler(s MessageServiceInterface) *MessageHandler {
+// Answers are throttled with their own budget since each one is sent to the
+// LLM. Browsers connect to the events with a ticket, from the origins the
+// CORS policy allows.
+func NewMessageHandler(s MessageServiceInterface, answersLimiter *RateLimiter, events MessageEventSubscriber, tickets TicketService, cors CorsConfig) *MessageHandler {
 	return &MessageHandler{
 		messageService: s,
+		answersLimiter: answersLimiter,
+		events:         events,
+		tickets:        tickets,
+		cors:           cors,
 	}
 }
 
@@ -55,19 +80,18 @@ func (h *MessageHandler) PostMessage(w http.ResponseWriter, r *http.Request) {
 
 	var message scopingMessage.Message
 
-	message.UserId = &userId
-
-	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := decodeAndValidate(w, r, &message); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
+	// The user in the path wins over one sent in the body
+	message.UserId = &userId
+
 	message, err := h.messageService.PostMessage(r.Context(), message)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -85,9 +109,8 @@ func (h *MessageHandler) PostAnswers(w http.ResponseWriter, r *http.Request) {
 	var messages []scopingMessage.Message
 
 	// Decode the request body into the messages slice
-	if err := json.NewDecoder(r.Body).Decode(&messages); err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := decodeAndValidate(w, r, &messages); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
@@ -99,8 +122,7 @@ func (h *MessageHandler) PostAnswers(w http.ResponseWriter, r *http.Request) {
 	responseMessage, err := h.messageService.PostAnswers(r.Context(), messages)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -117,18 +139,31 @@ func (h *MessageHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
 	messageId := chi.URLParam(r, "messageId")
 
 	if userId == "" || messageId == "" {
-		http.Error(w, "User ID and Message ID are required", http.StatusBadRequest)
+		writeError(w, r, errMissingIds)
+		return
+	}
+
+	wait, err := waitFromQuery(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	message, err := h.messageService.GetMessage(r.Context(), messageId, userId)
+	message, err := h.messageService.WaitForMessage(r.Context(), messageId, userId, wait)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, message.Version)
+
+	// Clients that waited and still got a pending message ask again
+	if wait > 0 && message.IsPending() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(messageRetryAfter.Seconds())))
+		w.WriteHeader(http.StatusAccepted)
+	}
+
 	if err := json.NewEncoder(w).Encode(message); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -136,11 +171,31 @@ func (h *MessageHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Reads how long to wait for a pending message, such as 30s. Plain numbers
+// are seconds.
+func waitFromQuery(r *http.Request) (time.Duration, error) {
+	value := r.URL.Query().Get("wait")
+	if value == "" {
+		return 0, nil
+	}
+
+	wait, err := time.ParseDuration(value)
+	if seconds, convErr := strconv.Atoi(value); convErr == nil {
+		wait, err = time.Duration(seconds)*time.Second, nil
+	}
+
+	if err != nil || wait < 0 || wait > maxMessageWait {
+		return 0, errInvalidWait
+	}
+
+	return wait, nil
+}
+
 func (h *MessageHandler) GetAllUserMessages(w http.ResponseWriter, r *http.Request) {
 	userId := chi.URLParam(r, "userId")
 
 	if userId == "" {
-		http.Error(w, "User ID is required", http.StatusBadRequest)
+		writeError(w, r, errMissingUserId)
 		return
 	}
 
@@ -159,15 +214,17 @@ func (h *MessageHandler) GetAllUserMessages(w http.ResponseWriter, r *http.Reque
 		pageSize = 10
 	}
 
-	messages, err := h.messageService.GetAllUserMessages(r.Context(), userId, page, pageSize)
+	// Soft deleted messages are only listed on request, and only to admins
+	includeDeleted := wantsDeleted(r)
+
+	messages, err := h.messageService.GetAllUserMessages(r.Context(), userId, page, pageSize, includeDeleted)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(messages); err != nil {
+	if err := json.NewEncoder(w).Encode(emptyIfNil(messages)); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -181,27 +238,84 @@ func (h *MessageHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
 	messageId := chi.URLParam(r, "messageId")
 
 	if userId == "" || messageId == "" {
-		http.Error(w, "User ID and Message ID are required", http.StatusBadRequest)
+		writeError(w, r, errMissingIds)
 		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := decodeAndValidate(w, r, &message); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	// Setting userId and messageId from the URL parameters
 	message.UserId = &userId
 	message.Id = messageId
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	message.Version = version
+
+	updatedMessage, err := h.messageService.UpdateMessage(r.Context(), message)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, updatedMessage.Version)
+
+	if err := json.NewEncoder(w).Encode(updatedMessage); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// Applies a merge patch to the message
+func (h *MessageHandler) PatchMessage(w http.ResponseWriter, r *http.Request) {
+	userId := chi.URLParam(r, "userId")
+	messageId := chi.URLParam(r, "messageId")
+
+	if userId == "" || messageId == "" {
+		writeError(w, r, errMissingIds)
+		return
+	}
+
+	current, err := h.messageService.GetMessage(r.Context(), messageId, userId)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := checkIfMatch(r, current.Version, scopingMessage.ErrVersionMismatch); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var message scopingMessage.Message
+
+	if err := decodeMergePatch(w, r, current, &message); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// The update fails if the message changed after it was read for patching
+	message.UserId = &userId
+	message.Id = messageId
+	message.Version = current.Version
 
 	updatedMessage, err := h.messageService.UpdateMessage(r.Context(), message)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, updatedMessage.Version)
+
 	if err := json.NewEncoder(w).Encode(updatedMessage); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 }
@@ -211,12 +325,31 @@ func (h *MessageHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
 	messageId := chi.URLParam(r, "messageId")
 
 	if userId == "" || messageId == "" {
-		http.Error(w, "User ID and Message ID are required", http.StatusBadRequest)
+		writeError(w, r, errMissingIds)
+		return
+	}
+
+	err := h.messageService.DeleteMessage(r.Context(), messageId, userId)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *MessageHandler) RestoreMessage(w http.ResponseWriter, r *http.Request) {
+	userId := chi.URLParam(r, "userId")
+	messageId := chi.URLParam(r, "messageId")
+
+	if userId == "" || messageId == "" {
+		writeError(w, r, errMissingIds)
 		return
 	}
 
-	if err := h.messageService.DeleteMessage(r.Context(), messageId, userId); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	err := h.messageService.RestoreMessage(r.Context(), messageId, userId)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
@@ -226,16 +359,24 @@ func (h *MessageHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
 func (h *MessageHandler) mapRoutes(router chi.Router) {
 	router.Route("/api/v1/users/{userId}/messages", func(r chi.Router) {
 
-		// r.Use(JwtMiddleware)
+		r.Use(ScopeMiddleware("messages"))
+
+		// Trainers and corporate managers read recommendations, only the user writes them
+		readers := RequireUserAccess("userId", scopingUser.RoleTrainer, scopingUser.RoleCorporateManager)
+		writers := RequireUserAccess("userId")
 
-		r.Post("/", h.PostMessage)
-		r.Post("/answers", h.PostAnswers)
-		r.Get("/", h.GetAllUserMessages)
+		r.With(writers).Post("/", h.PostMessage)
+		r.With(writers, h.answersLimiter.Middleware).Post("/answers", h.PostAnswers)
+		r.With(readers).Get("/", h.GetAllUserMessages)
+		r.With(readers).Get("/events", h.StreamEvents)
+		r.With(readers).Post("/events/tickets", h.PostEventsTicket)
 
 		r.Route("/{messageId}", func(r chi.Router) {
-			r.Get("/", h.GetMessage)
-			r.Put("/", h.UpdateMessage)
-			r.Delete("/", h.DeleteMessage)
+			r.With(readers).Get("/", h.GetMessage)
+			r.With(writers).Put("/", h.UpdateMessage)
+			r.With(writers).Patch("/", h.PatchMessage)
+			r.With(writers).Delete("/", h.DeleteMessage)
+			r.With(writers).Post("/restore", h.RestoreMessage)
 		})
 	})
 }
@@ -1,10 +1,14 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/internal/tenant"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
 )
 
 func (h *QuestionSetHandler) qSetQueryParamMiddleware(next http.Handler) http.Handler {
@@ -30,46 +34,114 @@ func (h *CourseOutlineHandler) outlineQueryParamMiddleware(next http.Handler) ht
 	})
 }
 
-func CorsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Allow from any origin
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-
-		// Handle OPTIONS request
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+// Authenticates requests with a bearer token and attaches the identity of the
+// caller, including its resolved roles, to the request context
+func JwtMiddleware(verifier TokenVerifier, policy *AccessPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header["Authorization"]
 
-		next.ServeHTTP(w, r)
-	})
+			if authHeader == nil {
+				log.Error("invalid authorization header")
+				writeError(w, r, errNotAuthorized)
+				return
+			}
+
+			authHeaderParts := strings.Split(authHeader[0], " ")
+
+			if len(authHeaderParts) != 2 || strings.ToLower(authHeaderParts[0]) != "bearer" {
+				log.Error("invalid authorization header")
+				writeError(w, r, errNotAuthorized)
+				return
+			}
+
+			ident, err := verifier.VerifyToken(r.Context(), authHeaderParts[1])
+			if err != nil {
+				log.Errorf("unauthorized authorization header: %v", err)
+				writeError(w, r, errNotAuthorized)
+				return
+			}
+
+			// Repositories scope every read and write to the tenant of the caller
+			ctx := tenant.NewContext(r.Context(), ident.TenantId)
+			if policy != nil {
+				ident.Roles, err = policy.ResolveRoles(ctx, ident)
+				if errors.Is(err, scopingUser.ErrNotFound) {
+					log.Errorf("user %s was deleted", ident.UID)
+					writeError(w, r, errForbidden)
+					return
+				}
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+
+				ctx = newPolicyContext(ctx, policy)
+			}
+
+			ctx = identity.NewContext(ctx, ident)
+			next.ServeHTTP(w, r.WithContext(ctx)) // this will call the next handler in the chain
+		})
+	}
 }
 
-func JwtMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header["Authorization"]
-		if authHeader == nil {
-			log.Error("invalid authorization header")
-			http.Error(w, "not authorized", http.StatusUnauthorized)
-			return
-		}
+// Authenticates integrations by API key, WebSocket connections of browsers by
+// ticket and everyone else by bearer token
+func AuthMiddleware(verifier TokenVerifier, keys APIKeyAuthenticator, policy *AccessPolicy, tickets TicketService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		jwt := JwtMiddleware(verifier, policy)(next)
 
-		authHeaderParts := strings.Split(authHeader[0], " ")
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if secret, ok := webSocketTicket(r); ok && tickets != nil && r.Header.Get("Authorization") == "" {
+				issued, err := tickets.RedeemTicket(r.Context(), secret, r.URL.Path)
+				if err != nil {
+					log.Errorf("unauthorized ticket: %v", err)
+					writeError(w, r, errNotAuthorized)
+					return
+				}
 
-		if len(authHeaderParts) != 2 || strings.ToLower(authHeaderParts[0]) != "bearer" {
-			log.Error("invalid authorization header")
-			http.Error(w, "not authorized", http.StatusUnauthorized)
-			return
-		}
+				// Roles were resolved when the ticket was issued a moment ago
+				ident := issued.Identity()
 
-		token := authHeaderParts[1]
-		if VerifyFirebaseToken(r.Context(), token) {
-			next.ServeHTTP(w, r) // this will call the next handler in the chain
-		} else {
-			log.Error("unauthorized authorization header")
-			http.Error(w, "not authorized", http.StatusUnauthorized)
-		}
+				ctx := tenant.NewContext(r.Context(), ident.TenantId)
+				ctx = newPolicyContext(ctx, policy)
+				ctx = identity.NewContext(ctx, ident)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			rawKey := r.Header.Get("X-API-Key")
+			if rawKey == "" || keys == nil {
+				jwt.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := keys.Authenticate(r.Context(), rawKey)
+			if err != nil {
+				log.Errorf("unauthorized api key: %v", err)
+				writeError(w, r, errNotAuthorized)
+				return
+			}
+
+			ident := identity.Identity{
+				UID:      "apikey:" + key.Id,
+				TenantId: key.TenantId,
+				APIKeyId: key.Id,
+				Scopes:   key.Scopes,
+			}
+
+			ctx := tenant.NewContext(r.Context(), ident.TenantId)
+			ctx = newPolicyContext(ctx, policy)
+			ctx = identity.NewContext(ctx, ident)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Responses are JSON unless a handler sets another content type
+func JSONMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/internal/tenant"
+)
+
+// Accepts every token as the caller of the given tenant
+type tenantVerifier string
+
+func (tenantId tenantVerifier) VerifyToken(ctx context.Context, rawToken string) (identity.Identity, error) {
+	return identity.Identity{UID: "user-1", TenantId: string(tenantId)}, nil
+}
+
+func TestJwtMiddlewareTenant(t *testing.T) {
+	tests := []struct {
+		name       string
+		tenantId   string
+		wantTenant string
+		wantScoped bool
+	}{
+		{"tenant of the token", "acme", "acme", true},
+		{"default tenant", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotTenant string
+			var gotScoped bool
+
+			handler := JwtMiddleware(tenantVerifier(tt.tenantId), nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotTenant, gotScoped = tenant.FromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/question-sets", nil)
+			r.Header.Set("Authorization", "Bearer token")
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if gotTenant != tt.wantTenant || gotScoped != tt.wantScoped {
+				t.Errorf("got tenant %q (scoped %v), want %q (scoped %v)", gotTenant, gotScoped, tt.wantTenant, tt.wantScoped)
+			}
+		})
+	}
+}
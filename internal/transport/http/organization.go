@@ -0,0 +1,238 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	log "github.com/sirupsen/logrus"
+	organization "github.com/zzenonn/scoping-ai/internal/organization"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+)
+
+func init() {
+
+	// Set log level based on environment variables
+	switch logLevel := strings.ToLower(os.Getenv("LOG_LEVEL")); logLevel {
+	case "trace":
+		log.SetLevel(log.TraceLevel)
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "info":
+		log.SetLevel(log.InfoLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	default:
+		log.SetLevel(log.ErrorLevel)
+	}
+
+}
+
+type OrganizationService interface {
+	PostOrganization(ctx context.Context, org organization.Organization) (organization.Organization, error)
+	GetOrganization(ctx context.Context, id string) (organization.Organization, error)
+	GetAllOrganizations(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]organization.Organization, error)
+	UpdateOrganization(ctx context.Context, org organization.Organization) (organization.Organization, error)
+	DeleteOrganization(ctx context.Context, id string) error
+	RestoreOrganization(ctx context.Context, id string) error
+	GetMembers(ctx context.Context, orgId string) ([]organization.Member, error)
+	PutMember(ctx context.Context, orgId string, member organization.Member) (organization.Member, error)
+	RemoveMember(ctx context.Context, orgId string, userId string) error
+}
+
+type OrganizationHandler struct {
+	organizationService OrganizationService
+}
+
+func NewOrganizationHandler(s OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: s,
+	}
+}
+
+func (h *OrganizationHandler) PostOrganization(w http.ResponseWriter, r *http.Request) {
+	var org organization.Organization
+
+	if err := decodeAndValidate(w, r, &org); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	org, err := h.organizationService.PostOrganization(r.Context(), org)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(org); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	org, err := h.organizationService.GetOrganization(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, org.Version)
+
+	if err := json.NewEncoder(w).Encode(org); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *OrganizationHandler) GetAllOrganizations(w http.ResponseWriter, r *http.Request) {
+	// Get page and pageSize from query parameters
+	pageStr := r.URL.Query().Get("page")
+	pageSizeStr := r.URL.Query().Get("pageSize")
+
+	// Convert them to integers with some default values
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	// Soft deleted organizations are only listed on request, and only to admins
+	includeDeleted := wantsDeleted(r)
+
+	orgs, err := h.organizationService.GetAllOrganizations(r.Context(), page, pageSize, includeDeleted)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(emptyIfNil(orgs)); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *OrganizationHandler) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	var org organization.Organization
+
+	if err := decodeAndValidate(w, r, &org); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	org.Id = chi.URLParam(r, "id")
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	org.Version = version
+
+	org, err = h.organizationService.UpdateOrganization(r.Context(), org)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, org.Version)
+
+	if err := json.NewEncoder(w).Encode(org); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *OrganizationHandler) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	if err := h.organizationService.DeleteOrganization(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+}
+
+func (h *OrganizationHandler) RestoreOrganization(w http.ResponseWriter, r *http.Request) {
+	if err := h.organizationService.RestoreOrganization(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+}
+
+func (h *OrganizationHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	members, err := h.organizationService.GetMembers(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(emptyIfNil(members)); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *OrganizationHandler) PutMember(w http.ResponseWriter, r *http.Request) {
+	var member organization.Member
+
+	if err := decodeAndValidate(w, r, &member); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	member.UserId = chi.URLParam(r, "userId")
+
+	member, err := h.organizationService.PutMember(r.Context(), chi.URLParam(r, "id"), member)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(member); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if err := h.organizationService.RemoveMember(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "userId")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+}
+
+func (h *OrganizationHandler) mapRoutes(router chi.Router) {
+	router.Route("/api/v1/organizations", func(r chi.Router) {
+		r.Use(ScopeMiddleware("organizations"))
+
+		admins := RequireRole(scopingUser.RoleAdmin)
+
+		r.With(admins).Post("/", h.PostOrganization)
+		r.With(admins).Get("/", h.GetAllOrganizations)
+
+		r.Route("/{id}", func(r chi.Router) {
+			// Members read their organization, its corporate managers also see who is in it
+			r.With(RequireOrganizationAccess("id", scopingUser.RoleCorporateManager, scopingUser.RoleLearner, scopingUser.RoleTrainer)).Get("/", h.GetOrganization)
+			r.With(admins).Put("/", h.UpdateOrganization)
+			r.With(admins).Delete("/", h.DeleteOrganization)
+			r.With(admins).Post("/restore", h.RestoreOrganization)
+
+			r.With(RequireOrganizationAccess("id", scopingUser.RoleCorporateManager)).Get("/members", h.GetMembers)
+			r.With(admins).Put("/members/{userId}", h.PutMember)
+			r.With(admins).Delete("/members/{userId}", h.RemoveMember)
+		})
+	})
+}
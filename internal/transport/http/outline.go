@@ -11,6 +11,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	log "github.com/sirupsen/logrus"
 	outline "github.com/zzenonn/scoping-ai/internal/outline"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+	"github.com/zzenonn/scoping-ai/pkg/common"
 )
 
 func init() {
@@ -34,12 +36,19 @@ func init() {
 type CourseOutlineService interface {
 	PostCourseOutline(ctx context.Context, courseOutline outline.CourseOutline) (outline.CourseOutline, error)
 	GetCourseOutline(ctx context.Context, id string) (outline.CourseOutline, error)
-	GetCourseOutlinesByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string) ([]outline.CourseOutline, error)
-	GetAllCourseOutlines(ctx context.Context, page int, pageSize int) ([]outline.CourseOutline, error)
+	GetCourseOutlinesByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]outline.CourseOutline, error)
+	GetAllCourseOutlines(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]outline.CourseOutline, error)
 	UpdateCourseOutline(ctx context.Context, courseOutline outline.CourseOutline) (outline.CourseOutline, error)
 	DeleteCourseOutline(ctx context.Context, id string) error
+	RestoreCourseOutline(ctx context.Context, id string) error
+	ImportCourseOutlines(ctx context.Context, courseOutlines []outline.CourseOutline, dryRun bool) ([]common.ImportResult, error)
+	ExportCourseOutlines(ctx context.Context, each func(outline.CourseOutline) error) error
 }
 
+// Columns of course outlines in CSV files. The id is exported, but ignored by
+// imports, which go by course code.
+var outlineColumns = []string{"id", "technology_name", "course_code", "course_name", "outline"}
+
 type CourseOutlineHandler struct {
 	courseOutlineService CourseOutlineService
 }
@@ -53,17 +62,15 @@ func NewCourseOutlineHandler(s CourseOutlineService) *CourseOutlineHandler {
 func (h *CourseOutlineHandler) PostCourseOutline(w http.ResponseWriter, r *http.Request) {
 	var courseOutline outline.CourseOutline
 
-	if err := json.NewDecoder(r.Body).Decode(&courseOutline); err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := decodeAndValidate(w, r, &courseOutline); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	courseOutline, err := h.courseOutlineService.PostCourseOutline(r.Context(), courseOutline)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -80,11 +87,12 @@ func (h *CourseOutlineHandler) GetCourseOutline(w http.ResponseWriter, r *http.R
 	courseOutline, err := h.courseOutlineService.GetCourseOutline(r.Context(), id)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, courseOutline.Version)
+
 	if err := json.NewEncoder(w).Encode(courseOutline); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -108,15 +116,17 @@ func (h *CourseOutlineHandler) GetCourseOutlinesByFilter(w http.ResponseWriter,
 		pageSize = 10
 	}
 
-	courseOutlines, err := h.courseOutlineService.GetCourseOutlinesByFilter(r.Context(), page, pageSize, filterName, filterValue)
+	// Soft deleted course outlines are only listed on request, and only to admins
+	includeDeleted := wantsDeleted(r)
+
+	courseOutlines, err := h.courseOutlineService.GetCourseOutlinesByFilter(r.Context(), page, pageSize, filterName, filterValue, includeDeleted)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(courseOutlines); err != nil {
+	if err := json.NewEncoder(w).Encode(emptyIfNil(courseOutlines)); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -139,15 +149,17 @@ func (h *CourseOutlineHandler) GetAllCourseOutlines(w http.ResponseWriter, r *ht
 		pageSize = 10
 	}
 
-	courseOutlines, err := h.courseOutlineService.GetAllCourseOutlines(r.Context(), page, pageSize)
+	// Soft deleted course outlines are only listed on request, and only to admins
+	includeDeleted := wantsDeleted(r)
+
+	courseOutlines, err := h.courseOutlineService.GetAllCourseOutlines(r.Context(), page, pageSize, includeDeleted)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(courseOutlines); err != nil {
+	if err := json.NewEncoder(w).Encode(emptyIfNil(courseOutlines)); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -159,21 +171,69 @@ func (h *CourseOutlineHandler) UpdateCourseOutline(w http.ResponseWriter, r *htt
 
 	var courseOutline outline.CourseOutline
 
-	if err := json.NewDecoder(r.Body).Decode(&courseOutline); err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := decodeAndValidate(w, r, &courseOutline); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	courseOutline.Id = courseOutlineId
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	courseOutline.Version = version
 
-	courseOutline, err := h.courseOutlineService.UpdateCourseOutline(r.Context(), courseOutline)
+	courseOutline, err = h.courseOutlineService.UpdateCourseOutline(r.Context(), courseOutline)
 
 	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, courseOutline.Version)
+
+	if err := json.NewEncoder(w).Encode(courseOutline); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+}
+
+// Applies a merge patch to the course outline
+func (h *CourseOutlineHandler) PatchCourseOutline(w http.ResponseWriter, r *http.Request) {
+	courseOutlineId := chi.URLParam(r, "id")
+
+	current, err := h.courseOutlineService.GetCourseOutline(r.Context(), courseOutlineId)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := checkIfMatch(r, current.Version, outline.ErrVersionMismatch); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var courseOutline outline.CourseOutline
+
+	if err := decodeMergePatch(w, r, current, &courseOutline); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// The update fails if the outline changed after it was read for patching
+	courseOutline.Id = courseOutlineId
+	courseOutline.Version = current.Version
+
+	courseOutline, err = h.courseOutlineService.UpdateCourseOutline(r.Context(), courseOutline)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, courseOutline.Version)
 
 	if err := json.NewEncoder(w).Encode(courseOutline); err != nil {
 		log.Error(err)
@@ -188,25 +248,82 @@ func (h *CourseOutlineHandler) DeleteCourseOutline(w http.ResponseWriter, r *htt
 	err := h.courseOutlineService.DeleteCourseOutline(r.Context(), id)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+}
+
+func (h *CourseOutlineHandler) RestoreCourseOutline(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	err := h.courseOutlineService.RestoreCourseOutline(r.Context(), id)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+}
+
+// Creates or updates course outlines from NDJSON or CSV, by course code. Rows
+// that fail don't stop the rest, they are reported along with what became of
+// every other row. Nothing is written in a dry run.
+func (h *CourseOutlineHandler) ImportCourseOutlines(w http.ResponseWriter, r *http.Request) {
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+	records, err := readImport(w, r, outlineColumns, formatNDJSON, formatCSV)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	courseOutlines, lines, failed := decodeImportRows(records, func(courseOutline outline.CourseOutline) *string {
+		return courseOutline.CourseCode
+	})
+
+	results, err := h.courseOutlineService.ImportCourseOutlines(r.Context(), courseOutlines, dryRun)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeImportReport(w, newImportReport(dryRun, lines, results, failed))
+}
+
+// Streams every course outline that isn't deleted as NDJSON or CSV
+func (h *CourseOutlineHandler) ExportCourseOutlines(w http.ResponseWriter, r *http.Request) {
+	format, err := exportFormat(r, formatNDJSON, formatCSV)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
+
+	export := newExportWriter(w, format, "course-outlines", outlineColumns)
+
+	err = h.courseOutlineService.ExportCourseOutlines(r.Context(), func(courseOutline outline.CourseOutline) error {
+		return export.write(courseOutline)
+	})
+
+	export.finish(r, err)
 }
 
 func (h *CourseOutlineHandler) mapRoutes(router chi.Router) {
 	router.Route("/api/v1/course-outlines", func(r chi.Router) {
+		r.Use(ScopeMiddleware("course-outlines"))
 
-		// r.Use(JwtMiddleware)
-
-		r.Post("/", h.PostCourseOutline)
+		r.With(RequireRole(scopingUser.RoleAdmin)).Post("/", h.PostCourseOutline)
 
 		r.With(h.outlineQueryParamMiddleware).Get("/", h.GetAllCourseOutlines)
 
+		r.With(RequireRole(scopingUser.RoleAdmin)).Post("/import", h.ImportCourseOutlines)
+		r.Get("/export", h.ExportCourseOutlines)
+
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", h.GetCourseOutline)
-			r.Put("/", h.UpdateCourseOutline)
-			r.Delete("/", h.DeleteCourseOutline)
+			r.With(RequireRole(scopingUser.RoleAdmin)).Put("/", h.UpdateCourseOutline)
+			r.With(RequireRole(scopingUser.RoleAdmin)).Patch("/", h.PatchCourseOutline)
+			r.With(RequireRole(scopingUser.RoleAdmin)).Delete("/", h.DeleteCourseOutline)
+			r.With(RequireRole(scopingUser.RoleAdmin)).Post("/restore", h.RestoreCourseOutline)
 		})
 
 	})
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+// PATCH bodies are JSON merge patches (RFC 7396). Plain JSON is accepted too
+// since the two only differ in their media type.
+const mergePatchContentType = "application/merge-patch+json"
+
+var errUnsupportedPatch = common.NewError(common.KindValidation, "PATCH bodies must be sent as "+mergePatchContentType)
+
+// Applies a merge patch to a decoded JSON document. Members set to null are
+// removed, objects are merged recursively and every other value, arrays
+// included, replaces the one in the target.
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+
+		targetObject[name] = mergePatch(targetObject[name], value)
+	}
+
+	return targetObject
+}
+
+// Patches the JSON representation of current with the merge patch in the
+// request body and decodes the result into dst. The patched resource is
+// validated as a whole, as it replaces the stored one.
+func decodeMergePatch(w http.ResponseWriter, r *http.Request, current interface{}, dst interface{}) error {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+			return errUnsupportedPatch
+		}
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	var patch interface{}
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return invalidBody(err)
+	}
+
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(currentJSON, &document); err != nil {
+		return err
+	}
+
+	patched, err := json.Marshal(mergePatch(document, patch))
+	if err != nil {
+		return err
+	}
+
+	// Patches may only name fields the resource has
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return invalidBody(err)
+	}
+
+	return validateBody(dst)
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/privacy"
+)
+
+type PrivacyServiceInterface interface {
+	EraseUser(ctx context.Context, userId string) (privacy.ErasureRecord, error)
+	ExportUser(ctx context.Context, userId string) (privacy.UserExport, error)
+}
+
+type PrivacyHandler struct {
+	privacyService PrivacyServiceInterface
+}
+
+func NewPrivacyHandler(s PrivacyServiceInterface) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: s,
+	}
+}
+
+func (h *PrivacyHandler) EraseUser(w http.ResponseWriter, r *http.Request) {
+	userId := chi.URLParam(r, "userId")
+
+	if userId == "" {
+		writeError(w, r, errMissingUserId)
+		return
+	}
+
+	record, err := h.privacyService.EraseUser(r.Context(), userId)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(record); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// Returns a zip archive with a manifest and one JSON file per stored document
+func (h *PrivacyHandler) ExportUser(w http.ResponseWriter, r *http.Request) {
+	userId := chi.URLParam(r, "userId")
+
+	if userId == "" {
+		writeError(w, r, errMissingUserId)
+		return
+	}
+
+	export, err := h.privacyService.ExportUser(r.Context(), userId)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	archive, err := createExportArchive(export)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%s-export.zip"`, userId))
+
+	if _, err := w.Write(archive); err != nil {
+		log.Error(err)
+	}
+}
+
+func createExportArchive(export privacy.UserExport) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	archive := zip.NewWriter(&buffer)
+
+	manifest := map[string]interface{}{
+		"user_id":     export.UserId,
+		"exported_at": export.ExportedAt.Format(time.RFC3339),
+		"documents":   len(export.Documents),
+	}
+
+	if err := writeArchiveJSON(archive, "manifest.json", manifest, export.ExportedAt); err != nil {
+		return nil, err
+	}
+
+	for _, document := range export.Documents {
+		if err := writeArchiveJSON(archive, document.Path+".json", document.Data, export.ExportedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func writeArchiveJSON(archive *zip.Writer, name string, value interface{}, modified time.Time) error {
+	file, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+func (h *PrivacyHandler) mapRoutes(router chi.Router) {
+	router.Route("/api/v1/users/{userId}/erasure", func(r chi.Router) {
+
+		r.Use(ScopeMiddleware("users"))
+		r.Use(RequireUserAccess("userId"))
+
+		r.Post("/", h.EraseUser)
+	})
+
+	router.Route("/api/v1/users/{userId}/export", func(r chi.Router) {
+
+		r.Use(ScopeMiddleware("users"))
+		r.Use(RequireUserAccess("userId"))
+
+		r.Get("/", h.ExportUser)
+	})
+}
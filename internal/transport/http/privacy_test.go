@@ -0,0 +1,65 @@
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/zzenonn/scoping-ai/internal/privacy"
+)
+
+func TestCreateExportArchive(t *testing.T) {
+	export := privacy.UserExport{
+		UserId:     "user-1",
+		ExportedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Documents: []privacy.Document{
+			{Path: "users/user-1", Data: map[string]interface{}{"name": "Lee"}},
+			{Path: "users/user-1/messages/message-1", Data: map[string]interface{}{"message_text": "Hi"}},
+		},
+	}
+
+	data, err := createExportArchive(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]map[string]interface{}{}
+	for _, file := range archive.File {
+		content, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var value map[string]interface{}
+		if err := json.NewDecoder(content).Decode(&value); err != nil {
+			t.Fatalf("%s is not JSON: %v", file.Name, err)
+		}
+		content.Close()
+
+		files[file.Name] = value
+	}
+
+	want := map[string]map[string]interface{}{
+		"manifest.json":                        {"user_id": "user-1", "exported_at": "2024-05-01T12:00:00Z", "documents": float64(2)},
+		"users/user-1.json":                    {"name": "Lee"},
+		"users/user-1/messages/message-1.json": {"message_text": "Hi"},
+	}
+
+	if len(files) != len(want) {
+		t.Fatalf("got files %v, want %d files", files, len(want))
+	}
+	for name, wantValue := range want {
+		for key, value := range wantValue {
+			if files[name][key] != value {
+				t.Errorf("%s: got %s = %v, want %v", name, key, files[name][key], value)
+			}
+		}
+	}
+}
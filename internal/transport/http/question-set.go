@@ -11,6 +11,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	log "github.com/sirupsen/logrus"
 	questionSet "github.com/zzenonn/scoping-ai/internal/question-set"
+	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
+	"github.com/zzenonn/scoping-ai/pkg/common"
 )
 
 func init() {
@@ -34,10 +36,13 @@ func init() {
 type QuestionSetService interface {
 	GetQuestionSet(ctx context.Context, technologyName string) (questionSet.QuestionSet, error)
 	GetQuestionSetByTechName(ctx context.Context, technologyName string) (questionSet.QuestionSet, error)
-	GetAllQuestionSets(ctx context.Context, page int, pageSize int) ([]questionSet.QuestionSet, error)
+	GetAllQuestionSets(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]questionSet.QuestionSet, error)
 	PostQuestionSet(ctx context.Context, questionSet questionSet.QuestionSet) (questionSet.QuestionSet, error)
 	UpdateQuestionSet(ctx context.Context, questionSet questionSet.QuestionSet) (questionSet.QuestionSet, error)
 	DeleteQuestionSet(ctx context.Context, id string) error
+	RestoreQuestionSet(ctx context.Context, id string) error
+	ImportQuestionSets(ctx context.Context, questionSets []questionSet.QuestionSet, dryRun bool) ([]common.ImportResult, error)
+	ExportQuestionSets(ctx context.Context, each func(questionSet.QuestionSet) error) error
 }
 
 type QuestionSetHandler struct {
@@ -54,17 +59,15 @@ func NewQuestionSetHandler(s QuestionSetService) *QuestionSetHandler {
 func (h *QuestionSetHandler) PostQuestionSet(w http.ResponseWriter, r *http.Request) {
 	var qSet questionSet.QuestionSet
 
-	if err := json.NewDecoder(r.Body).Decode(&qSet); err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := decodeAndValidate(w, r, &qSet); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	qSet, err := h.questionSetService.PostQuestionSet(r.Context(), qSet)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -81,11 +84,12 @@ func (h *QuestionSetHandler) GetQuestionSet(w http.ResponseWriter, r *http.Reque
 	qSet, err := h.questionSetService.GetQuestionSet(r.Context(), qSetId)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, qSet.Version)
+
 	if err := json.NewEncoder(w).Encode(qSet); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -98,8 +102,7 @@ func (h *QuestionSetHandler) GetQuestionSetByTechName(w http.ResponseWriter, r *
 	qSet, err := h.questionSetService.GetQuestionSetByTechName(r.Context(), technologyName)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -126,16 +129,18 @@ func (h *QuestionSetHandler) GetAllQuestionSets(w http.ResponseWriter, r *http.R
 		pageSize = 10
 	}
 
+	// Soft deleted question sets are only listed on request, and only to admins
+	includeDeleted := wantsDeleted(r)
+
 	// Now call GetAllQuestionSets with page and pageSize as parameters
-	qSets, err := h.questionSetService.GetAllQuestionSets(r.Context(), page, pageSize)
+	qSets, err := h.questionSetService.GetAllQuestionSets(r.Context(), page, pageSize, includeDeleted)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(qSets); err != nil {
+	if err := json.NewEncoder(w).Encode(emptyIfNil(qSets)); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -147,28 +152,77 @@ func (h *QuestionSetHandler) UpdateQuestionSet(w http.ResponseWriter, r *http.Re
 
 	var qSet questionSet.QuestionSet
 
-	if err := json.NewDecoder(r.Body).Decode(&qSet); err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := decodeAndValidate(w, r, &qSet); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	qSet.Id = qSetId
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	qSet.Version = version
 
-	qSet, err := h.questionSetService.UpdateQuestionSet(r.Context(), qSet)
+	qSet, err = h.questionSetService.UpdateQuestionSet(r.Context(), qSet)
 
 	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, qSet.Version)
+
+	if err := json.NewEncoder(w).Encode(qSet); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+}
+
+// Applies a merge patch to the question set. Questions are an array, so a
+// patch replaces all of them.
+func (h *QuestionSetHandler) PatchQuestionSet(w http.ResponseWriter, r *http.Request) {
+	qSetId := chi.URLParam(r, "id")
+
+	current, err := h.questionSetService.GetQuestionSet(r.Context(), qSetId)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := checkIfMatch(r, current.Version, questionSet.ErrVersionMismatch); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var qSet questionSet.QuestionSet
+
+	if err := decodeMergePatch(w, r, current, &qSet); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// The update fails if the question set changed after it was read for patching
+	qSet.Id = qSetId
+	qSet.Version = current.Version
+
+	qSet, err = h.questionSetService.UpdateQuestionSet(r.Context(), qSet)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, qSet.Version)
+
 	if err := json.NewEncoder(w).Encode(qSet); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-
 }
 
 func (h *QuestionSetHandler) DeleteQuestionSet(w http.ResponseWriter, r *http.Request) {
@@ -177,27 +231,86 @@ func (h *QuestionSetHandler) DeleteQuestionSet(w http.ResponseWriter, r *http.Re
 	err := h.questionSetService.DeleteQuestionSet(r.Context(), qSetId)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h *QuestionSetHandler) RestoreQuestionSet(w http.ResponseWriter, r *http.Request) {
+	qSetId := chi.URLParam(r, "id")
+
+	err := h.questionSetService.RestoreQuestionSet(r.Context(), qSetId)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Creates or updates question sets from NDJSON or YAML, by technology name.
+// Rows that fail don't stop the rest, they are reported along with what
+// became of every other row. Nothing is written in a dry run.
+func (h *QuestionSetHandler) ImportQuestionSets(w http.ResponseWriter, r *http.Request) {
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+	records, err := readImport(w, r, nil, formatNDJSON, formatYAML)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	qSets, lines, failed := decodeImportRows(records, func(qSet questionSet.QuestionSet) *string {
+		return qSet.TechnologyName
+	})
+
+	results, err := h.questionSetService.ImportQuestionSets(r.Context(), qSets, dryRun)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeImportReport(w, newImportReport(dryRun, lines, results, failed))
+}
+
+// Streams every question set that isn't deleted as NDJSON or YAML
+func (h *QuestionSetHandler) ExportQuestionSets(w http.ResponseWriter, r *http.Request) {
+	format, err := exportFormat(r, formatNDJSON, formatYAML)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	export := newExportWriter(w, format, "question-sets", nil)
+
+	err = h.questionSetService.ExportQuestionSets(r.Context(), func(qSet questionSet.QuestionSet) error {
+		return export.write(qSet)
+	})
+
+	export.finish(r, err)
+}
+
 func (h *QuestionSetHandler) mapRoutes(router chi.Router) {
 	router.Route("/api/v1/question-sets", func(r chi.Router) {
+		r.Use(ScopeMiddleware("question-sets"))
 
-		// r.Use(JwtMiddleware)
-
-		r.Post("/", h.PostQuestionSet)
+		r.With(RequireRole(scopingUser.RoleAdmin)).Post("/", h.PostQuestionSet)
 
 		r.With(h.qSetQueryParamMiddleware).Get("/", h.GetAllQuestionSets)
 
+		r.With(RequireRole(scopingUser.RoleAdmin)).Post("/import", h.ImportQuestionSets)
+		r.Get("/export", h.ExportQuestionSets)
+
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", h.GetQuestionSet)
-			r.Put("/", h.UpdateQuestionSet)
-			r.Delete("/", h.DeleteQuestionSet)
+			r.With(RequireRole(scopingUser.RoleAdmin)).Put("/", h.UpdateQuestionSet)
+			r.With(RequireRole(scopingUser.RoleAdmin)).Patch("/", h.PatchQuestionSet)
+			r.With(RequireRole(scopingUser.RoleAdmin)).Delete("/", h.DeleteQuestionSet)
+			r.With(RequireRole(scopingUser.RoleAdmin)).Post("/restore", h.RestoreQuestionSet)
 		})
 	})
 }
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/internal/ratelimit"
+)
+
+// Throttles callers with a named budget. Budgets with different names are
+// counted separately, so routes that call the LLM can be limited on top of
+// the general budget.
+type RateLimiter struct {
+	name  string
+	store ratelimit.Store
+	limit ratelimit.Limit
+	key   func(r *http.Request) string
+}
+
+func NewRateLimiter(name string, store ratelimit.Store, limit ratelimit.Limit) *RateLimiter {
+	return &RateLimiter{
+		name:  name,
+		store: store,
+		limit: limit,
+		key:   rateLimitKey,
+	}
+}
+
+// Counts every request by address. It runs before authentication, so callers
+// trying keys or tokens are throttled too. Addresses are only taken from
+// X-Forwarded-For when the request comes through one of the proxies.
+func NewAddressRateLimiter(name string, store ratelimit.Store, limit ratelimit.Limit, proxies TrustedProxies) *RateLimiter {
+	return &RateLimiter{
+		name:  name,
+		store: store,
+		limit: limit,
+		key:   proxies.addressKey,
+	}
+}
+
+// Callers are counted by API key, by user and otherwise by address
+func rateLimitKey(r *http.Request) string {
+	if ident, ok := identity.FromContext(r.Context()); ok {
+		if ident.IsAPIKey() {
+			return "apikey:" + ident.APIKeyId
+		}
+		return "user:" + ident.UID
+	}
+
+	return TrustedProxies(nil).addressKey(r)
+}
+
+// The networks of the load balancers and proxies in front of the server
+type TrustedProxies []*net.IPNet
+
+// Parses a comma separated list of addresses and CIDR ranges
+func ParseTrustedProxies(value string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+
+	for _, item := range splitList(value) {
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy address: %s", item)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy range: %s", item)
+		}
+		proxies = append(proxies, network)
+	}
+
+	return proxies, nil
+}
+
+func (proxies TrustedProxies) trusted(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (proxies TrustedProxies) addressKey(r *http.Request) string {
+	return "ip:" + proxies.clientIP(r)
+}
+
+// Every proxy appends the address it saw to X-Forwarded-For. Walking back from
+// the peer, the first address that isn't a trusted proxy is the client, the
+// entries before it come from the client and can't be trusted. Without
+// trusted proxies the header is ignored, since anyone can send it.
+func (proxies TrustedProxies) clientIP(r *http.Request) string {
+	address, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		address = r.RemoteAddr
+	}
+
+	if !proxies.trusted(address) {
+		return address
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+
+		address = hop
+		if !proxies.trusted(hop) {
+			break
+		}
+	}
+
+	return address
+}
+
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := l.key(r)
+		result, err := l.store.Take(r.Context(), l.name+"|"+key, l.limit)
+
+		// An unavailable store shouldn't take the API down with it
+		if err != nil {
+			log.Errorf("rate limit store failed: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(int(result.Reset.Seconds())))
+		w.Header().Set("RateLimit-Policy", strconv.Itoa(l.limit.Burst)+";w="+strconv.Itoa(int(l.limit.Period.Seconds())))
+
+		if !result.Allowed {
+			log.Errorf("%s rate limit exceeded by %s", l.name, key)
+			w.Header().Set("Retry-After", strconv.Itoa(int(result.RetryAfter.Seconds())))
+			writeError(w, r, errTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/internal/ratelimit"
+)
+
+// Refuses every token, like an attacker guessing them would see
+type rejectingVerifier struct{}
+
+func (rejectingVerifier) VerifyToken(ctx context.Context, rawToken string) (identity.Identity, error) {
+	return identity.Identity{}, errors.New("bad token")
+}
+
+// A route behind the authenticated group
+type okHandler struct{}
+
+func (okHandler) mapRoutes(router chi.Router) {
+	router.Get("/api/v1/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestAddressRateLimitBeforeAuthentication(t *testing.T) {
+	limit := ratelimit.Limit{Burst: 2, Period: time.Minute}
+
+	h := NewMainHandler(rejectingVerifier{}, nil, nil, DefaultCorsConfig(),
+		NewRateLimiter("requests", ratelimit.NewMemoryStore(), limit),
+		NewAddressRateLimiter("addresses", ratelimit.NewMemoryStore(), limit, nil),
+		nil, nil, nil)
+	h.AddHandler(okHandler{})
+	h.MapRoutes()
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     string
+		want       int
+	}{
+		{"first bad token", "192.0.2.1:1000", "Bearer guess-1", http.StatusUnauthorized},
+		{"second bad token", "192.0.2.1:1001", "Bearer guess-2", http.StatusUnauthorized},
+		{"third bad token is throttled", "192.0.2.1:1002", "Bearer guess-3", http.StatusTooManyRequests},
+		{"missing token is throttled", "192.0.2.1:1003", "", http.StatusTooManyRequests},
+		{"other address", "192.0.2.2:1000", "Bearer guess-4", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			h.Router.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("got status %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+				t.Error("throttled response has no Retry-After header")
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8, 192.0.2.10, 2001:db8::/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		proxies    TrustedProxies
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{"no proxies", nil, "198.51.100.7:1000", nil, "198.51.100.7"},
+		{"forwarded header ignored without proxies", nil, "198.51.100.7:1000", []string{"203.0.113.1"}, "198.51.100.7"},
+		{"forwarded header ignored from untrusted peer", proxies, "198.51.100.7:1000", []string{"203.0.113.1"}, "198.51.100.7"},
+		{"trusted proxy", proxies, "10.1.2.3:1000", []string{"203.0.113.1"}, "203.0.113.1"},
+		{"trusted single address", proxies, "192.0.2.10:1000", []string{"203.0.113.1"}, "203.0.113.1"},
+		{"spoofed entries before the client", proxies, "10.1.2.3:1000", []string{"1.1.1.1, 203.0.113.1"}, "203.0.113.1"},
+		{"chain of trusted proxies", proxies, "10.1.2.3:1000", []string{"203.0.113.1, 10.9.9.9"}, "203.0.113.1"},
+		{"repeated headers", proxies, "10.1.2.3:1000", []string{"1.1.1.1", "203.0.113.1"}, "203.0.113.1"},
+		{"only proxies forwarded", proxies, "10.1.2.3:1000", []string{"10.9.9.9"}, "10.9.9.9"},
+		{"trusted proxy without header", proxies, "10.1.2.3:1000", nil, "10.1.2.3"},
+		{"IPv6 proxy", proxies, "[2001:db8::1]:1000", []string{"2001:db8:ffff::1, 2606:4700::1"}, "2606:4700::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			if got := tt.proxies.clientIP(r); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"10.0.0.0/8", 1, false},
+		{"10.0.0.1, ::1, 2001:db8::/32", 3, false},
+		{"10.0.0.0/33", 0, true},
+		{"proxy.internal", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			proxies, err := ParseTrustedProxies(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if len(proxies) != tt.want {
+				t.Errorf("got %d proxies, want %d", len(proxies), tt.want)
+			}
+		})
+	}
+}
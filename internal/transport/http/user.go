@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
 	scopingUser "github.com/zzenonn/scoping-ai/internal/user"
 )
 
@@ -33,10 +34,12 @@ func init() {
 
 type UserServiceInterface interface {
 	GetUser(ctx context.Context, id string) (scopingUser.User, error)
-	GetAllUsers(ctx context.Context, page int, pageSize int) ([]scopingUser.User, error)
+	GetAllUsers(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]scopingUser.User, error)
+	GetUsersByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]scopingUser.User, error)
 	CreateUser(ctx context.Context, user scopingUser.User) (scopingUser.User, error)
 	UpdateUser(ctx context.Context, user scopingUser.User) (scopingUser.User, error)
 	DeleteUser(ctx context.Context, id string) error
+	RestoreUser(ctx context.Context, id string) error
 }
 
 type UserHandler struct {
@@ -52,17 +55,20 @@ func NewUserHandler(s UserServiceInterface) *UserHandler {
 func (h *UserHandler) PostUser(w http.ResponseWriter, r *http.Request) {
 	var user scopingUser.User
 
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := decodeAndValidate(w, r, &user); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
+	// Only admins assign roles
+	if ident, _ := identity.FromContext(r.Context()); !ident.HasRole(scopingUser.RoleAdmin) {
+		user.Roles = nil
+	}
+
 	user, err := h.userService.CreateUser(r.Context(), user)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -76,16 +82,40 @@ func (h *UserHandler) PostUser(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	uid := chi.URLParam(r, "id")
 	if uid == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, r, errMissingUserId)
 		return
 	}
 
 	user, err := h.userService.GetUser(r.Context(), uid)
 	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
+
+	if err := json.NewEncoder(w).Encode(user); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+}
+
+// Returns the user of the caller, provisioned on its first request
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	ident, ok := identity.FromContext(r.Context())
+	if !ok {
+		writeError(w, r, errNotAuthorized)
+		return
+	}
+
+	user, err := h.userService.GetUser(r.Context(), ident.UID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
 
 	if err := json.NewEncoder(w).Encode(user); err != nil {
 		log.Error(err)
@@ -110,27 +140,46 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 		pageSize = 10
 	}
 
-	// Now call GetAllUsers with page and pageSize as parameters
-	qSets, err := h.userService.GetAllUsers(r.Context(), page, pageSize)
+	// Soft deleted users are only listed on request, and only to admins
+	includeDeleted := wantsDeleted(r)
+
+	var qSets []scopingUser.User
+
+	// Corporate managers only list the users of their own organization
+	ident, _ := identity.FromContext(r.Context())
+	if ident.HasRole(scopingUser.RoleAdmin) || ident.IsAPIKey() {
+		qSets, err = h.userService.GetAllUsers(r.Context(), page, pageSize, includeDeleted)
+	} else {
+		var manager scopingUser.User
+		manager, err = h.userService.GetUser(r.Context(), ident.UID)
+		if err == nil && manager.OrganizationId == nil {
+			writeError(w, r, errForbidden)
+			return
+		}
+		if err == nil {
+			qSets, err = h.userService.GetUsersByFilter(r.Context(), page, pageSize, "organization_id", *manager.OrganizationId, includeDeleted)
+		}
+	}
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(qSets); err != nil {
+	if err := json.NewEncoder(w).Encode(emptyIfNil(qSets)); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 }
 
+// Replaces the profile of the user. Roles are managed separately and are kept
+// unless an admin sends them.
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	uid := chi.URLParam(r, "id")
 
 	if uid == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, r, errMissingUserId)
 		return
 	}
 
@@ -138,19 +187,84 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	user.ID = uid
 
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := decodeAndValidate(w, r, &user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	user.ID = uid
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
+	user.Version = version
 
-	user, err := h.userService.UpdateUser(r.Context(), user)
+	// Only admins assign roles
+	if ident, _ := identity.FromContext(r.Context()); !ident.HasRole(scopingUser.RoleAdmin) {
+		user.Roles = nil
+	}
+
+	user, err = h.userService.UpdateUser(r.Context(), user)
 
 	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
+
+	if err := json.NewEncoder(w).Encode(user); err != nil {
 		log.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+}
+
+// Applies a merge patch to the user
+func (h *UserHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	uid := chi.URLParam(r, "id")
+
+	if uid == "" {
+		writeError(w, r, errMissingUserId)
+		return
+	}
+
+	current, err := h.userService.GetUser(r.Context(), uid)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := checkIfMatch(r, current.Version, scopingUser.ErrVersionMismatch); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var user scopingUser.User
+
+	if err := decodeMergePatch(w, r, current, &user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// The update fails if the user changed after it was read for patching
+	user.ID = uid
+	user.Version = current.Version
+
+	// Only admins assign roles
+	if ident, _ := identity.FromContext(r.Context()); !ident.HasRole(scopingUser.RoleAdmin) {
+		user.Roles = nil
+	}
+
+	user, err = h.userService.UpdateUser(r.Context(), user)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
 
 	if err := json.NewEncoder(w).Encode(user); err != nil {
 		log.Error(err)
@@ -163,15 +277,32 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	uid := chi.URLParam(r, "id")
 
 	if uid == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, r, errMissingUserId)
 		return
 	}
 
 	err := h.userService.DeleteUser(r.Context(), uid)
 
 	if err != nil {
-		log.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *UserHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	uid := chi.URLParam(r, "id")
+
+	if uid == "" {
+		writeError(w, r, errMissingUserId)
+		return
+	}
+
+	err := h.userService.RestoreUser(r.Context(), uid)
+
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
@@ -179,16 +310,20 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserHandler) mapRoutes(router chi.Router) {
+	router.With(ScopeMiddleware("users")).Get("/api/v1/me", h.GetMe)
+
 	router.Route("/api/v1/users", func(r chi.Router) {
-		// r.Use(JwtMiddleware)
+		r.Use(ScopeMiddleware("users"))
 
 		r.Method("POST", "/", http.HandlerFunc(h.PostUser))
-		r.Method("GET", "/", http.HandlerFunc(h.GetAllUsers)) // JwtMiddleware(http.HandlerFunc(h.GetAllUsers)))
+		r.With(RequireRole(scopingUser.RoleAdmin, scopingUser.RoleCorporateManager)).Method("GET", "/", http.HandlerFunc(h.GetAllUsers))
 
 		r.Route("/{id}", func(r chi.Router) {
-			r.Method("GET", "/", http.HandlerFunc(h.GetUser))
-			r.Method("PUT", "/", http.HandlerFunc(h.UpdateUser))
-			r.Method("DELETE", "/", http.HandlerFunc(h.DeleteUser))
+			r.With(RequireUserAccess("id", scopingUser.RoleCorporateManager)).Method("GET", "/", http.HandlerFunc(h.GetUser))
+			r.With(RequireUserAccess("id")).Method("PUT", "/", http.HandlerFunc(h.UpdateUser))
+			r.With(RequireUserAccess("id")).Method("PATCH", "/", http.HandlerFunc(h.PatchUser))
+			r.With(RequireUserAccess("id")).Method("DELETE", "/", http.HandlerFunc(h.DeleteUser))
+			r.With(RequireRole(scopingUser.RoleAdmin)).Method("POST", "/restore", http.HandlerFunc(h.RestoreUser))
 		})
 	})
 }
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	scopingMessage "github.com/zzenonn/scoping-ai/internal/message"
+	"github.com/zzenonn/scoping-ai/pkg/common"
+)
+
+// Request bodies larger than this are rejected before they are decoded
+const maxBodyBytes = 1 << 20
+
+var (
+	errBodyTooLarge  = common.NewError(common.KindTooLarge, fmt.Sprintf("the request body is larger than %d bytes", maxBodyBytes))
+	errInvalidFields = common.NewError(common.KindValidation, "the request body has invalid fields")
+)
+
+// A field of the request body that failed validation, named by its JSON path
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Input DTOs declare their rules with validate tags. Fields are reported by
+// their JSON names.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.RegisterStructValidation(validateAnswer, scopingMessage.Answer{})
+
+	return v
+}
+
+// Questions of question sets may leave out their text, but the model is
+// prompted with the text of every answered question
+func validateAnswer(sl validator.StructLevel) {
+	answer := sl.Current().Interface().(scopingMessage.Answer)
+
+	if answer.Question == nil || answer.Question.Text == nil || *answer.Question.Text == "" {
+		sl.ReportError(answer.Question, "question.text", "Text", "required", "")
+	}
+}
+
+// Rejects a request body that couldn't be decoded
+func invalidBody(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errBodyTooLarge
+	}
+
+	return common.Wrap(common.KindValidation, "invalid request body: "+err.Error(), err)
+}
+
+// Decodes a JSON request body into dst. Bodies over the size limit, unknown
+// fields and anything following the JSON value are rejected.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return invalidBody(err)
+	}
+
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return invalidBody(errors.New("the body must hold a single JSON value"))
+	}
+
+	return nil
+}
+
+// Decodes and validates a JSON request body
+func decodeAndValidate(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	if err := decodeJSON(w, r, dst); err != nil {
+		return err
+	}
+
+	return validateBody(dst)
+}
+
+func validateBody(v interface{}) error {
+	var err error
+	if value := reflect.Indirect(reflect.ValueOf(v)); value.Kind() == reflect.Slice {
+		err = validate.Var(value.Interface(), "dive")
+	} else {
+		err = validate.Struct(v)
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	var fields []FieldError
+
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fieldErr),
+			Rule:    fieldErr.Tag(),
+			Message: fieldMessage(fieldErr),
+		})
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return common.WithDetails(errInvalidFields, fields)
+}
+
+// Strips the name of the validated type from the path of the field
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+
+	if index := strings.IndexAny(namespace, ".["); index >= 0 {
+		namespace = strings.TrimPrefix(namespace[index:], ".")
+	}
+
+	return namespace
+}
+
+// Rules name other fields by their Go names, such as MessageText for message_text
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, c := range name {
+		if 'A' <= c && c <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			c += 'a' - 'A'
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func fieldMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required when " + snakeCase(fieldErr.Param()) + " is missing"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fieldErr.Param()), ", ")
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return "must be at most " + fieldErr.Param() + " characters long"
+		}
+		return "must hold at most " + fieldErr.Param() + " items"
+	case "min":
+		if fieldErr.Kind() == reflect.String {
+			return "must be at least " + fieldErr.Param() + " characters long"
+		}
+		return "must hold at least " + fieldErr.Param() + " items"
+	default:
+		return "failed the " + fieldErr.Tag() + " rule"
+	}
+}
@@ -2,32 +2,59 @@ package TrainingNeedsUsers
 
 import (
 	"context"
-	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+	"github.com/zzenonn/scoping-ai/internal/identity"
+	"github.com/zzenonn/scoping-ai/pkg/common"
 )
 
 var (
-	ErrNotImplemented = errors.New("this function is not yet implemented")
+	ErrNotImplemented  = common.NewError(common.KindInternal, "this function is not yet implemented")
+	ErrVersionMismatch = common.NewError(common.KindPreconditionFailed, "user was modified since it was last read")
+	ErrNotFound        = common.NewError(common.KindNotFound, "user not found")
+	ErrDuplicateEmail  = common.NewError(common.KindConflict, "a user with this email address already exists")
+	ErrUserExists      = common.NewError(common.KindConflict, "user already exists")
+	ErrMissingClaims   = common.NewError(common.KindUnauthorized, "the identity token has no email address to provision a user with")
+)
+
+// Roles a user can hold. Users without any role are treated as learners.
+// Corporate managers are the members holding the manager role of their
+// organization, the role isn't granted any other way.
+const (
+	RoleAdmin            = "admin"
+	RoleTrainer          = "trainer"
+	RoleCorporateManager = "corporate_manager"
+	RoleLearner          = "learner"
 )
 
 // User representation
 type User struct {
-	ID           string  `json:"id" firestore:"id"`
-	Name         *string `json:"name,omitempty" firestore:"name,omitempty"`
-	EmailAddress *string `json:"email_address,omitempty" firestore:"email_address,omitempty"`
-	Corporate    bool    `json:"corporate,omitempty" firestore:"corporate,omitempty"`
-	Company      *string `json:"company,omitempty" firestore:"company,omitempty"`
+	ID               string     `json:"id" firestore:"id"`
+	Name             *string    `json:"name,omitempty" firestore:"name,omitempty" validate:"required,max=200"`
+	EmailAddress     *string    `json:"email_address,omitempty" firestore:"email_address,omitempty" validate:"required,email,max=254"`
+	Corporate        bool       `json:"corporate,omitempty" firestore:"corporate,omitempty"`
+	OrganizationId   *string    `json:"organization_id,omitempty" firestore:"organization_id,omitempty"`
+	OrganizationRole string     `json:"-" firestore:"organization_role,omitempty"`
+	Roles            []string   `json:"roles,omitempty" firestore:"roles,omitempty" validate:"omitempty,dive,oneof=admin trainer corporate_manager learner"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty" firestore:"deleted_at,omitempty"`
+	DeletedBy        *string    `json:"deleted_by,omitempty" firestore:"deleted_by,omitempty"`
+	Version          string     `json:"-" firestore:"-"`
 }
 
 // Implements the user repository interface design pattern
 type UserRepository interface {
 	GetUser(ctx context.Context, id string) (User, error)
-	GetAllUsers(ctx context.Context, page int, pageSize int) ([]User, error)
+	GetAllUsers(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]User, error)
+	GetUsersByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]User, error)
+	GetUsersByIds(ctx context.Context, ids []string) ([]User, error)
 	CreateUser(ctx context.Context, user User) (User, error)
+	ProvisionUser(ctx context.Context, user User, linkExisting bool) (User, error)
 	UpdateUser(ctx context.Context, user User) (User, error)
 	DeleteUser(ctx context.Context, id string) error
+	RestoreUser(ctx context.Context, id string) error
+	PurgeDeletedUsers(ctx context.Context, before time.Time) (int, error)
 }
 
 type UserService struct {
@@ -53,10 +80,10 @@ func (u *UserService) GetUser(ctx context.Context, id string) (User, error) {
 	return user, nil
 }
 
-func (u *UserService) GetAllUsers(ctx context.Context, page int, pageSize int) ([]User, error) {
+func (u *UserService) GetAllUsers(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]User, error) {
 	log.Debug("Retrieving all users . . .")
 
-	users, err := u.userRepository.GetAllUsers(ctx, page, pageSize)
+	users, err := u.userRepository.GetAllUsers(ctx, page, pageSize, includeDeleted)
 
 	if err != nil {
 		log.Error("Failed to retrieve all users")
@@ -66,9 +93,42 @@ func (u *UserService) GetAllUsers(ctx context.Context, page int, pageSize int) (
 	return users, nil
 }
 
+func (u *UserService) GetUsersByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]User, error) {
+	log.Debug("Retrieving filtered users . . .")
+
+	users, err := u.userRepository.GetUsersByFilter(ctx, page, pageSize, filterName, filterValue, includeDeleted)
+
+	if err != nil {
+		log.Error("Failed to retrieve filtered users")
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Returns the users that exist out of the ids, in no particular order
+func (u *UserService) GetUsersByIds(ctx context.Context, ids []string) ([]User, error) {
+	log.Debug("Retrieving users by ids . . .")
+
+	users, err := u.userRepository.GetUsersByIds(ctx, ids)
+
+	if err != nil {
+		log.Error("Failed to retrieve users by ids")
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (u *UserService) CreateUser(ctx context.Context, user User) (User, error) {
 	log.Debug("Creating new user . . .")
-	user.ID = uuid.New().String()
+	// Users signing up for themselves are keyed by their authenticated uid,
+	// admins and integrations create users on behalf of others
+	if ident, ok := identity.FromContext(ctx); ok && !ident.HasRole(RoleAdmin) && !ident.IsAPIKey() {
+		user.ID = ident.UID
+	} else {
+		user.ID = uuid.New().String()
+	}
 
 	createdUser, err := u.userRepository.CreateUser(ctx, user)
 
@@ -80,6 +140,56 @@ func (u *UserService) CreateUser(ctx context.Context, user User) (User, error) {
 	return createdUser, nil
 }
 
+// Some identity providers send the email_verified claim as a string
+func emailVerified(claims map[string]interface{}) bool {
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		return verified
+	case string:
+		return verified == "true"
+	default:
+		return false
+	}
+}
+
+// Users created by admins and integrations are keyed by a UUID. Any other id
+// is the subject of a sign in, which is never linked to another one.
+func IsLinkableId(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// Returns the user of an authenticated caller, creating or linking it from the
+// token claims on the first request
+func (u *UserService) ProvisionUser(ctx context.Context, ident identity.Identity) (User, error) {
+	log.Debug("Provisioning user . . .")
+
+	if ident.Email == "" {
+		return User{}, ErrMissingClaims
+	}
+
+	email := ident.Email
+	name := email
+	if claimedName, ok := ident.Claims["name"].(string); ok && claimedName != "" {
+		name = claimedName
+	}
+
+	// Only the owner of a verified address may take over the user created
+	// for it, anyone can sign up with an address they don't own
+	user, err := u.userRepository.ProvisionUser(ctx, User{
+		ID:           ident.UID,
+		Name:         &name,
+		EmailAddress: &email,
+	}, emailVerified(ident.Claims))
+
+	if err != nil {
+		log.Error("Failed to provision user")
+		return User{}, err
+	}
+
+	return user, nil
+}
+
 func (u *UserService) UpdateUser(ctx context.Context, user User) (User, error) {
 	log.Debug("Updating user . . .")
 
@@ -93,6 +203,7 @@ func (u *UserService) UpdateUser(ctx context.Context, user User) (User, error) {
 	return updatedUser, nil
 }
 
+// Soft deletes the user. It can be restored until it is purged.
 func (u *UserService) DeleteUser(ctx context.Context, id string) error {
 	log.Debug("Deleting user . . .")
 
@@ -105,3 +216,30 @@ func (u *UserService) DeleteUser(ctx context.Context, id string) error {
 
 	return nil
 }
+
+func (u *UserService) RestoreUser(ctx context.Context, id string) error {
+	log.Debug("Restoring user . . .")
+
+	err := u.userRepository.RestoreUser(ctx, id)
+
+	if err != nil {
+		log.Error("Failed to restore user")
+		return err
+	}
+
+	return nil
+}
+
+// Permanently removes users that were deleted before the given time
+func (u *UserService) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	log.Debug("Purging deleted users . . .")
+
+	purged, err := u.userRepository.PurgeDeletedUsers(ctx, before)
+
+	if err != nil {
+		log.Error("Failed to purge deleted users")
+		return purged, err
+	}
+
+	return purged, nil
+}
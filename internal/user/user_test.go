@@ -0,0 +1,124 @@
+package TrainingNeedsUsers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zzenonn/scoping-ai/internal/identity"
+)
+
+// Records the users it is asked to provision
+type provisioningRepository struct {
+	provisioned  []User
+	linkExisting []bool
+}
+
+func (repo *provisioningRepository) GetUser(ctx context.Context, id string) (User, error) {
+	return User{}, ErrNotImplemented
+}
+
+func (repo *provisioningRepository) GetAllUsers(ctx context.Context, page int, pageSize int, includeDeleted bool) ([]User, error) {
+	return nil, ErrNotImplemented
+}
+
+func (repo *provisioningRepository) GetUsersByFilter(ctx context.Context, page int, pageSize int, filterName string, filterValue string, includeDeleted bool) ([]User, error) {
+	return nil, ErrNotImplemented
+}
+
+func (repo *provisioningRepository) GetUsersByIds(ctx context.Context, ids []string) ([]User, error) {
+	return nil, ErrNotImplemented
+}
+
+func (repo *provisioningRepository) CreateUser(ctx context.Context, user User) (User, error) {
+	return User{}, ErrNotImplemented
+}
+
+func (repo *provisioningRepository) ProvisionUser(ctx context.Context, user User, linkExisting bool) (User, error) {
+	repo.provisioned = append(repo.provisioned, user)
+	repo.linkExisting = append(repo.linkExisting, linkExisting)
+	return user, nil
+}
+
+func (repo *provisioningRepository) UpdateUser(ctx context.Context, user User) (User, error) {
+	return User{}, ErrNotImplemented
+}
+
+func (repo *provisioningRepository) DeleteUser(ctx context.Context, id string) error {
+	return ErrNotImplemented
+}
+
+func (repo *provisioningRepository) RestoreUser(ctx context.Context, id string) error {
+	return ErrNotImplemented
+}
+
+func (repo *provisioningRepository) PurgeDeletedUsers(ctx context.Context, before time.Time) (int, error) {
+	return 0, ErrNotImplemented
+}
+
+func TestProvisionUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		email      string
+		claims     map[string]interface{}
+		wantErr    error
+		wantName   string
+		wantLinked bool
+	}{
+		{"named user", "lee@example.com", map[string]interface{}{"name": "Lee Learner"}, nil, "Lee Learner", false},
+		{"unnamed user", "lee@example.com", map[string]interface{}{}, nil, "lee@example.com", false},
+		{"verified email", "lee@example.com", map[string]interface{}{"email_verified": true}, nil, "lee@example.com", true},
+		{"verified email as string", "lee@example.com", map[string]interface{}{"email_verified": "true"}, nil, "lee@example.com", true},
+		{"unverified email", "lee@example.com", map[string]interface{}{"email_verified": false}, nil, "lee@example.com", false},
+		{"no email", "", map[string]interface{}{"name": "Lee Learner"}, ErrMissingClaims, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &provisioningRepository{}
+			ident := identity.Identity{UID: "firebase-uid", Email: tt.email, Claims: tt.claims}
+
+			user, err := NewUserService(repo).ProvisionUser(context.Background(), ident)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(repo.provisioned) != 0 {
+					t.Error("provisioned a user without an email address")
+				}
+				return
+			}
+
+			// Users are keyed by the subject of the token
+			if user.ID != "firebase-uid" {
+				t.Errorf("got id %s, want firebase-uid", user.ID)
+			}
+			if *user.Name != tt.wantName || *user.EmailAddress != tt.email {
+				t.Errorf("got %s <%s>, want %s <%s>", *user.Name, *user.EmailAddress, tt.wantName, tt.email)
+			}
+			if repo.linkExisting[0] != tt.wantLinked {
+				t.Errorf("got linkExisting %v, want %v", repo.linkExisting[0], tt.wantLinked)
+			}
+		})
+	}
+}
+
+func TestIsLinkableId(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"6f1c2d9e-8a4b-4c3d-9e2f-1a2b3c4d5e6f", true},
+		{"firebase-uid", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if got := IsLinkableId(tt.id); got != tt.want {
+				t.Errorf("IsLinkableId(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
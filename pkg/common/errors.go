@@ -0,0 +1,77 @@
+package common
+
+import "errors"
+
+// Kinds of failure shared by every service package. The transport layers map
+// each kind to their own status codes.
+type Kind string
+
+const (
+	KindNotFound           Kind = "not_found"
+	KindConflict           Kind = "conflict"
+	KindValidation         Kind = "validation"
+	KindUnprocessable      Kind = "unprocessable"
+	KindUnauthorized       Kind = "unauthorized"
+	KindForbidden          Kind = "forbidden"
+	KindPreconditionFailed Kind = "precondition_failed"
+	KindRateLimited        Kind = "rate_limited"
+	KindTooLarge           Kind = "payload_too_large"
+	KindUpstream           Kind = "upstream_failure"
+	KindInternal           Kind = "internal"
+)
+
+// A domain error of a known kind. Packages declare their errors with NewError
+// so they can still be matched with errors.Is.
+type Error struct {
+	Kind    Kind
+	Message string
+	Details interface{}
+	Err     error
+}
+
+func NewError(kind Kind, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Message: message,
+	}
+}
+
+// Wraps an error that has no kind of its own, such as one returned by a client library
+func Wrap(kind Kind, message string, err error) *Error {
+	return &Error{
+		Kind:    kind,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// Returns a copy of the error with details, such as the fields that failed
+// validation, that still matches the original
+func WithDetails(err *Error, details interface{}) *Error {
+	return &Error{
+		Kind:    err.Kind,
+		Message: err.Message,
+		Details: details,
+		Err:     err,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil && e.Message == "" {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Errors without a kind are internal
+func KindOf(err error) Kind {
+	var kindErr *Error
+	if errors.As(err, &kindErr) {
+		return kindErr.Kind
+	}
+	return KindInternal
+}
@@ -0,0 +1,30 @@
+package common
+
+// What a bulk import did with a row, or would do in a dry run
+type ImportAction string
+
+const (
+	ImportCreated  ImportAction = "created"
+	ImportUpdated  ImportAction = "updated"
+	ImportRestored ImportAction = "restored"
+	ImportFailed   ImportAction = "failed"
+)
+
+// Outcome of a single row of a bulk import. Rows are upserted by a unique
+// field, which is reported as the key.
+type ImportResult struct {
+	Row    int
+	Id     string
+	Key    string
+	Action ImportAction
+	Err    error
+}
+
+// A row that couldn't be imported, and why
+func ImportFailure(key string, err error) ImportResult {
+	return ImportResult{Key: key, Action: ImportFailed, Err: err}
+}
+
+// Rows of one import are keyed like stored documents, so "Acme" and "ACME "
+// are the same key
+var ErrRepeatedImportKey = NewError(KindConflict, "an earlier row of the import has the same key")
@@ -3,12 +3,12 @@ package common
 // If a question is multiple choice, is it checkbox or radio button?
 type Options struct {
 	MultiAnswer     bool     `json:"multi_answer,omitempty" firestore:"multi_answer,omitempty"`
-	PossibleOptions []string `json:"possible_options,omitempty" firestore:"possible_options,omitempty"`
+	PossibleOptions []string `json:"possible_options,omitempty" firestore:"possible_options,omitempty" validate:"omitempty,dive,max=200"`
 }
 
 // Question representation
 type Question struct {
-	Category *string  `json:"category,omitempty" firestore:"category,omitempty"`
-	Text     *string  `json:"text,omitempty" firestore:"text,omitempty"`
+	Category *string  `json:"category,omitempty" firestore:"category,omitempty" validate:"omitempty,max=100"`
+	Text     *string  `json:"text,omitempty" firestore:"text,omitempty" validate:"omitempty,max=1000"`
 	Options  *Options `json:"options,omitempty" firestore:"options,omitempty"`
 }
@@ -0,0 +1,270 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: scopingai/v1/common.proto
+
+package scopingaiv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// How a question may be answered
+type Options struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	MultiAnswer     bool                   `protobuf:"varint,1,opt,name=multi_answer,json=multiAnswer,proto3" json:"multi_answer,omitempty"`
+	PossibleOptions []string               `protobuf:"bytes,2,rep,name=possible_options,json=possibleOptions,proto3" json:"possible_options,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Options) Reset() {
+	*x = Options{}
+	mi := &file_scopingai_v1_common_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Options) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Options) ProtoMessage() {}
+
+func (x *Options) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_common_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Options.ProtoReflect.Descriptor instead.
+func (*Options) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_common_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Options) GetMultiAnswer() bool {
+	if x != nil {
+		return x.MultiAnswer
+	}
+	return false
+}
+
+func (x *Options) GetPossibleOptions() []string {
+	if x != nil {
+		return x.PossibleOptions
+	}
+	return nil
+}
+
+// A scoping question asked of a learner
+type Question struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      *string                `protobuf:"bytes,1,opt,name=category,proto3,oneof" json:"category,omitempty"`
+	Text          *string                `protobuf:"bytes,2,opt,name=text,proto3,oneof" json:"text,omitempty"`
+	Options       *Options               `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Question) Reset() {
+	*x = Question{}
+	mi := &file_scopingai_v1_common_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Question) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Question) ProtoMessage() {}
+
+func (x *Question) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_common_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Question.ProtoReflect.Descriptor instead.
+func (*Question) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_common_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Question) GetCategory() string {
+	if x != nil && x.Category != nil {
+		return *x.Category
+	}
+	return ""
+}
+
+func (x *Question) GetText() string {
+	if x != nil && x.Text != nil {
+		return *x.Text
+	}
+	return ""
+}
+
+func (x *Question) GetOptions() *Options {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+// Pages through a list, starting at page 1. Unset values default to the
+// first page of 10 records.
+type PageRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Page     int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Also list soft deleted records
+	IncludeDeleted bool `protobuf:"varint,3,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PageRequest) Reset() {
+	*x = PageRequest{}
+	mi := &file_scopingai_v1_common_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PageRequest) ProtoMessage() {}
+
+func (x *PageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_common_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PageRequest.ProtoReflect.Descriptor instead.
+func (*PageRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_common_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PageRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *PageRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *PageRequest) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.IncludeDeleted
+	}
+	return false
+}
+
+var File_scopingai_v1_common_proto protoreflect.FileDescriptor
+
+const file_scopingai_v1_common_proto_rawDesc = "" +
+	"\n" +
+	"\x19scopingai/v1/common.proto\x12\fscopingai.v1\"W\n" +
+	"\aOptions\x12!\n" +
+	"\fmulti_answer\x18\x01 \x01(\bR\vmultiAnswer\x12)\n" +
+	"\x10possible_options\x18\x02 \x03(\tR\x0fpossibleOptions\"\x8b\x01\n" +
+	"\bQuestion\x12\x1f\n" +
+	"\bcategory\x18\x01 \x01(\tH\x00R\bcategory\x88\x01\x01\x12\x17\n" +
+	"\x04text\x18\x02 \x01(\tH\x01R\x04text\x88\x01\x01\x12/\n" +
+	"\aoptions\x18\x03 \x01(\v2\x15.scopingai.v1.OptionsR\aoptionsB\v\n" +
+	"\t_categoryB\a\n" +
+	"\x05_text\"g\n" +
+	"\vPageRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12'\n" +
+	"\x0finclude_deleted\x18\x03 \x01(\bR\x0eincludeDeletedB?Z=github.com/zzenonn/scoping-ai/pkg/pb/scopingai/v1;scopingaiv1b\x06proto3"
+
+var (
+	file_scopingai_v1_common_proto_rawDescOnce sync.Once
+	file_scopingai_v1_common_proto_rawDescData []byte
+)
+
+func file_scopingai_v1_common_proto_rawDescGZIP() []byte {
+	file_scopingai_v1_common_proto_rawDescOnce.Do(func() {
+		file_scopingai_v1_common_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_scopingai_v1_common_proto_rawDesc), len(file_scopingai_v1_common_proto_rawDesc)))
+	})
+	return file_scopingai_v1_common_proto_rawDescData
+}
+
+var file_scopingai_v1_common_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_scopingai_v1_common_proto_goTypes = []any{
+	(*Options)(nil),     // 0: scopingai.v1.Options
+	(*Question)(nil),    // 1: scopingai.v1.Question
+	(*PageRequest)(nil), // 2: scopingai.v1.PageRequest
+}
+var file_scopingai_v1_common_proto_depIdxs = []int32{
+	0, // 0: scopingai.v1.Question.options:type_name -> scopingai.v1.Options
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_scopingai_v1_common_proto_init() }
+func file_scopingai_v1_common_proto_init() {
+	if File_scopingai_v1_common_proto != nil {
+		return
+	}
+	file_scopingai_v1_common_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_scopingai_v1_common_proto_rawDesc), len(file_scopingai_v1_common_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_scopingai_v1_common_proto_goTypes,
+		DependencyIndexes: file_scopingai_v1_common_proto_depIdxs,
+		MessageInfos:      file_scopingai_v1_common_proto_msgTypes,
+	}.Build()
+	File_scopingai_v1_common_proto = out.File
+	file_scopingai_v1_common_proto_goTypes = nil
+	file_scopingai_v1_common_proto_depIdxs = nil
+}
@@ -0,0 +1,577 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: scopingai/v1/course_outline.proto
+
+package scopingaiv1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// A course offered for a technology
+type CourseOutline struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TechnologyName string                 `protobuf:"bytes,2,opt,name=technology_name,json=technologyName,proto3" json:"technology_name,omitempty"`
+	CourseCode     string                 `protobuf:"bytes,3,opt,name=course_code,json=courseCode,proto3" json:"course_code,omitempty"`
+	CourseName     string                 `protobuf:"bytes,4,opt,name=course_name,json=courseName,proto3" json:"course_name,omitempty"`
+	Outline        *string                `protobuf:"bytes,5,opt,name=outline,proto3,oneof" json:"outline,omitempty"`
+	DeletedAt      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	DeletedBy      *string                `protobuf:"bytes,7,opt,name=deleted_by,json=deletedBy,proto3,oneof" json:"deleted_by,omitempty"`
+	// Version of the stored record, the REST API returns it as the ETag
+	Version       string `protobuf:"bytes,8,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CourseOutline) Reset() {
+	*x = CourseOutline{}
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CourseOutline) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CourseOutline) ProtoMessage() {}
+
+func (x *CourseOutline) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CourseOutline.ProtoReflect.Descriptor instead.
+func (*CourseOutline) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_course_outline_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CourseOutline) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CourseOutline) GetTechnologyName() string {
+	if x != nil {
+		return x.TechnologyName
+	}
+	return ""
+}
+
+func (x *CourseOutline) GetCourseCode() string {
+	if x != nil {
+		return x.CourseCode
+	}
+	return ""
+}
+
+func (x *CourseOutline) GetCourseName() string {
+	if x != nil {
+		return x.CourseName
+	}
+	return ""
+}
+
+func (x *CourseOutline) GetOutline() string {
+	if x != nil && x.Outline != nil {
+		return *x.Outline
+	}
+	return ""
+}
+
+func (x *CourseOutline) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *CourseOutline) GetDeletedBy() string {
+	if x != nil && x.DeletedBy != nil {
+		return *x.DeletedBy
+	}
+	return ""
+}
+
+func (x *CourseOutline) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type GetCourseOutlineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCourseOutlineRequest) Reset() {
+	*x = GetCourseOutlineRequest{}
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCourseOutlineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCourseOutlineRequest) ProtoMessage() {}
+
+func (x *GetCourseOutlineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCourseOutlineRequest.ProtoReflect.Descriptor instead.
+func (*GetCourseOutlineRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_course_outline_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetCourseOutlineRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Lists every course outline, or only those whose filter_name field equals
+// filter_value when both are set
+type ListCourseOutlinesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          *PageRequest           `protobuf:"bytes,1,opt,name=page,proto3" json:"page,omitempty"`
+	FilterName    string                 `protobuf:"bytes,2,opt,name=filter_name,json=filterName,proto3" json:"filter_name,omitempty"`
+	FilterValue   string                 `protobuf:"bytes,3,opt,name=filter_value,json=filterValue,proto3" json:"filter_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCourseOutlinesRequest) Reset() {
+	*x = ListCourseOutlinesRequest{}
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCourseOutlinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCourseOutlinesRequest) ProtoMessage() {}
+
+func (x *ListCourseOutlinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCourseOutlinesRequest.ProtoReflect.Descriptor instead.
+func (*ListCourseOutlinesRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_course_outline_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListCourseOutlinesRequest) GetPage() *PageRequest {
+	if x != nil {
+		return x.Page
+	}
+	return nil
+}
+
+func (x *ListCourseOutlinesRequest) GetFilterName() string {
+	if x != nil {
+		return x.FilterName
+	}
+	return ""
+}
+
+func (x *ListCourseOutlinesRequest) GetFilterValue() string {
+	if x != nil {
+		return x.FilterValue
+	}
+	return ""
+}
+
+type ListCourseOutlinesResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CourseOutlines []*CourseOutline       `protobuf:"bytes,1,rep,name=course_outlines,json=courseOutlines,proto3" json:"course_outlines,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListCourseOutlinesResponse) Reset() {
+	*x = ListCourseOutlinesResponse{}
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCourseOutlinesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCourseOutlinesResponse) ProtoMessage() {}
+
+func (x *ListCourseOutlinesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCourseOutlinesResponse.ProtoReflect.Descriptor instead.
+func (*ListCourseOutlinesResponse) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_course_outline_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListCourseOutlinesResponse) GetCourseOutlines() []*CourseOutline {
+	if x != nil {
+		return x.CourseOutlines
+	}
+	return nil
+}
+
+type CreateCourseOutlineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CourseOutline *CourseOutline         `protobuf:"bytes,1,opt,name=course_outline,json=courseOutline,proto3" json:"course_outline,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCourseOutlineRequest) Reset() {
+	*x = CreateCourseOutlineRequest{}
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCourseOutlineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCourseOutlineRequest) ProtoMessage() {}
+
+func (x *CreateCourseOutlineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCourseOutlineRequest.ProtoReflect.Descriptor instead.
+func (*CreateCourseOutlineRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_course_outline_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateCourseOutlineRequest) GetCourseOutline() *CourseOutline {
+	if x != nil {
+		return x.CourseOutline
+	}
+	return nil
+}
+
+// Replaces the course outline. A version, when set, must match the stored one.
+type UpdateCourseOutlineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CourseOutline *CourseOutline         `protobuf:"bytes,1,opt,name=course_outline,json=courseOutline,proto3" json:"course_outline,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCourseOutlineRequest) Reset() {
+	*x = UpdateCourseOutlineRequest{}
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCourseOutlineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCourseOutlineRequest) ProtoMessage() {}
+
+func (x *UpdateCourseOutlineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCourseOutlineRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCourseOutlineRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_course_outline_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateCourseOutlineRequest) GetCourseOutline() *CourseOutline {
+	if x != nil {
+		return x.CourseOutline
+	}
+	return nil
+}
+
+type DeleteCourseOutlineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCourseOutlineRequest) Reset() {
+	*x = DeleteCourseOutlineRequest{}
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCourseOutlineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCourseOutlineRequest) ProtoMessage() {}
+
+func (x *DeleteCourseOutlineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCourseOutlineRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCourseOutlineRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_course_outline_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteCourseOutlineRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RestoreCourseOutlineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreCourseOutlineRequest) Reset() {
+	*x = RestoreCourseOutlineRequest{}
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreCourseOutlineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreCourseOutlineRequest) ProtoMessage() {}
+
+func (x *RestoreCourseOutlineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_course_outline_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreCourseOutlineRequest.ProtoReflect.Descriptor instead.
+func (*RestoreCourseOutlineRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_course_outline_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RestoreCourseOutlineRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+var File_scopingai_v1_course_outline_proto protoreflect.FileDescriptor
+
+const file_scopingai_v1_course_outline_proto_rawDesc = "" +
+	"\n" +
+	"!scopingai/v1/course_outline.proto\x12\fscopingai.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x19scopingai/v1/common.proto\"\xbd\x02\n" +
+	"\rCourseOutline\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x0ftechnology_name\x18\x02 \x01(\tR\x0etechnologyName\x12\x1f\n" +
+	"\vcourse_code\x18\x03 \x01(\tR\n" +
+	"courseCode\x12\x1f\n" +
+	"\vcourse_name\x18\x04 \x01(\tR\n" +
+	"courseName\x12\x1d\n" +
+	"\aoutline\x18\x05 \x01(\tH\x00R\aoutline\x88\x01\x01\x129\n" +
+	"\n" +
+	"deleted_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12\"\n" +
+	"\n" +
+	"deleted_by\x18\a \x01(\tH\x01R\tdeletedBy\x88\x01\x01\x12\x18\n" +
+	"\aversion\x18\b \x01(\tR\aversionB\n" +
+	"\n" +
+	"\b_outlineB\r\n" +
+	"\v_deleted_by\")\n" +
+	"\x17GetCourseOutlineRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x8e\x01\n" +
+	"\x19ListCourseOutlinesRequest\x12-\n" +
+	"\x04page\x18\x01 \x01(\v2\x19.scopingai.v1.PageRequestR\x04page\x12\x1f\n" +
+	"\vfilter_name\x18\x02 \x01(\tR\n" +
+	"filterName\x12!\n" +
+	"\ffilter_value\x18\x03 \x01(\tR\vfilterValue\"b\n" +
+	"\x1aListCourseOutlinesResponse\x12D\n" +
+	"\x0fcourse_outlines\x18\x01 \x03(\v2\x1b.scopingai.v1.CourseOutlineR\x0ecourseOutlines\"`\n" +
+	"\x1aCreateCourseOutlineRequest\x12B\n" +
+	"\x0ecourse_outline\x18\x01 \x01(\v2\x1b.scopingai.v1.CourseOutlineR\rcourseOutline\"`\n" +
+	"\x1aUpdateCourseOutlineRequest\x12B\n" +
+	"\x0ecourse_outline\x18\x01 \x01(\v2\x1b.scopingai.v1.CourseOutlineR\rcourseOutline\",\n" +
+	"\x1aDeleteCourseOutlineRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"-\n" +
+	"\x1bRestoreCourseOutlineRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id2\xdc\x06\n" +
+	"\x14CourseOutlineService\x12|\n" +
+	"\x10GetCourseOutline\x12%.scopingai.v1.GetCourseOutlineRequest\x1a\x1b.scopingai.v1.CourseOutline\"$\x82\xd3\xe4\x93\x02\x1e\x12\x1c/api/v1/course-outlines/{id}\x12\x88\x01\n" +
+	"\x12ListCourseOutlines\x12'.scopingai.v1.ListCourseOutlinesRequest\x1a(.scopingai.v1.ListCourseOutlinesResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/api/v1/course-outlines\x12\x8d\x01\n" +
+	"\x13CreateCourseOutline\x12(.scopingai.v1.CreateCourseOutlineRequest\x1a\x1b.scopingai.v1.CourseOutline\"/\x82\xd3\xe4\x93\x02):\x0ecourse_outline\"\x17/api/v1/course-outlines\x12\xa1\x01\n" +
+	"\x13UpdateCourseOutline\x12(.scopingai.v1.UpdateCourseOutlineRequest\x1a\x1b.scopingai.v1.CourseOutline\"C\x82\xd3\xe4\x93\x02=:\x0ecourse_outline\x1a+/api/v1/course-outlines/{course_outline.id}\x12}\n" +
+	"\x13DeleteCourseOutline\x12(.scopingai.v1.DeleteCourseOutlineRequest\x1a\x16.google.protobuf.Empty\"$\x82\xd3\xe4\x93\x02\x1e*\x1c/api/v1/course-outlines/{id}\x12\x87\x01\n" +
+	"\x14RestoreCourseOutline\x12).scopingai.v1.RestoreCourseOutlineRequest\x1a\x16.google.protobuf.Empty\",\x82\xd3\xe4\x93\x02&\"$/api/v1/course-outlines/{id}/restoreB?Z=github.com/zzenonn/scoping-ai/pkg/pb/scopingai/v1;scopingaiv1b\x06proto3"
+
+var (
+	file_scopingai_v1_course_outline_proto_rawDescOnce sync.Once
+	file_scopingai_v1_course_outline_proto_rawDescData []byte
+)
+
+func file_scopingai_v1_course_outline_proto_rawDescGZIP() []byte {
+	file_scopingai_v1_course_outline_proto_rawDescOnce.Do(func() {
+		file_scopingai_v1_course_outline_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_scopingai_v1_course_outline_proto_rawDesc), len(file_scopingai_v1_course_outline_proto_rawDesc)))
+	})
+	return file_scopingai_v1_course_outline_proto_rawDescData
+}
+
+var file_scopingai_v1_course_outline_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_scopingai_v1_course_outline_proto_goTypes = []any{
+	(*CourseOutline)(nil),               // 0: scopingai.v1.CourseOutline
+	(*GetCourseOutlineRequest)(nil),     // 1: scopingai.v1.GetCourseOutlineRequest
+	(*ListCourseOutlinesRequest)(nil),   // 2: scopingai.v1.ListCourseOutlinesRequest
+	(*ListCourseOutlinesResponse)(nil),  // 3: scopingai.v1.ListCourseOutlinesResponse
+	(*CreateCourseOutlineRequest)(nil),  // 4: scopingai.v1.CreateCourseOutlineRequest
+	(*UpdateCourseOutlineRequest)(nil),  // 5: scopingai.v1.UpdateCourseOutlineRequest
+	(*DeleteCourseOutlineRequest)(nil),  // 6: scopingai.v1.DeleteCourseOutlineRequest
+	(*RestoreCourseOutlineRequest)(nil), // 7: scopingai.v1.RestoreCourseOutlineRequest
+	(*timestamppb.Timestamp)(nil),       // 8: google.protobuf.Timestamp
+	(*PageRequest)(nil),                 // 9: scopingai.v1.PageRequest
+	(*emptypb.Empty)(nil),               // 10: google.protobuf.Empty
+}
+var file_scopingai_v1_course_outline_proto_depIdxs = []int32{
+	8,  // 0: scopingai.v1.CourseOutline.deleted_at:type_name -> google.protobuf.Timestamp
+	9,  // 1: scopingai.v1.ListCourseOutlinesRequest.page:type_name -> scopingai.v1.PageRequest
+	0,  // 2: scopingai.v1.ListCourseOutlinesResponse.course_outlines:type_name -> scopingai.v1.CourseOutline
+	0,  // 3: scopingai.v1.CreateCourseOutlineRequest.course_outline:type_name -> scopingai.v1.CourseOutline
+	0,  // 4: scopingai.v1.UpdateCourseOutlineRequest.course_outline:type_name -> scopingai.v1.CourseOutline
+	1,  // 5: scopingai.v1.CourseOutlineService.GetCourseOutline:input_type -> scopingai.v1.GetCourseOutlineRequest
+	2,  // 6: scopingai.v1.CourseOutlineService.ListCourseOutlines:input_type -> scopingai.v1.ListCourseOutlinesRequest
+	4,  // 7: scopingai.v1.CourseOutlineService.CreateCourseOutline:input_type -> scopingai.v1.CreateCourseOutlineRequest
+	5,  // 8: scopingai.v1.CourseOutlineService.UpdateCourseOutline:input_type -> scopingai.v1.UpdateCourseOutlineRequest
+	6,  // 9: scopingai.v1.CourseOutlineService.DeleteCourseOutline:input_type -> scopingai.v1.DeleteCourseOutlineRequest
+	7,  // 10: scopingai.v1.CourseOutlineService.RestoreCourseOutline:input_type -> scopingai.v1.RestoreCourseOutlineRequest
+	0,  // 11: scopingai.v1.CourseOutlineService.GetCourseOutline:output_type -> scopingai.v1.CourseOutline
+	3,  // 12: scopingai.v1.CourseOutlineService.ListCourseOutlines:output_type -> scopingai.v1.ListCourseOutlinesResponse
+	0,  // 13: scopingai.v1.CourseOutlineService.CreateCourseOutline:output_type -> scopingai.v1.CourseOutline
+	0,  // 14: scopingai.v1.CourseOutlineService.UpdateCourseOutline:output_type -> scopingai.v1.CourseOutline
+	10, // 15: scopingai.v1.CourseOutlineService.DeleteCourseOutline:output_type -> google.protobuf.Empty
+	10, // 16: scopingai.v1.CourseOutlineService.RestoreCourseOutline:output_type -> google.protobuf.Empty
+	11, // [11:17] is the sub-list for method output_type
+	5,  // [5:11] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_scopingai_v1_course_outline_proto_init() }
+func file_scopingai_v1_course_outline_proto_init() {
+	if File_scopingai_v1_course_outline_proto != nil {
+		return
+	}
+	file_scopingai_v1_common_proto_init()
+	file_scopingai_v1_course_outline_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_scopingai_v1_course_outline_proto_rawDesc), len(file_scopingai_v1_course_outline_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_scopingai_v1_course_outline_proto_goTypes,
+		DependencyIndexes: file_scopingai_v1_course_outline_proto_depIdxs,
+		MessageInfos:      file_scopingai_v1_course_outline_proto_msgTypes,
+	}.Build()
+	File_scopingai_v1_course_outline_proto = out.File
+	file_scopingai_v1_course_outline_proto_goTypes = nil
+	file_scopingai_v1_course_outline_proto_depIdxs = nil
+}
@@ -0,0 +1,312 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: scopingai/v1/course_outline.proto
+
+package scopingaiv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CourseOutlineService_GetCourseOutline_FullMethodName     = "/scopingai.v1.CourseOutlineService/GetCourseOutline"
+	CourseOutlineService_ListCourseOutlines_FullMethodName   = "/scopingai.v1.CourseOutlineService/ListCourseOutlines"
+	CourseOutlineService_CreateCourseOutline_FullMethodName  = "/scopingai.v1.CourseOutlineService/CreateCourseOutline"
+	CourseOutlineService_UpdateCourseOutline_FullMethodName  = "/scopingai.v1.CourseOutlineService/UpdateCourseOutline"
+	CourseOutlineService_DeleteCourseOutline_FullMethodName  = "/scopingai.v1.CourseOutlineService/DeleteCourseOutline"
+	CourseOutlineService_RestoreCourseOutline_FullMethodName = "/scopingai.v1.CourseOutlineService/RestoreCourseOutline"
+)
+
+// CourseOutlineServiceClient is the client API for CourseOutlineService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CourseOutlineServiceClient interface {
+	GetCourseOutline(ctx context.Context, in *GetCourseOutlineRequest, opts ...grpc.CallOption) (*CourseOutline, error)
+	ListCourseOutlines(ctx context.Context, in *ListCourseOutlinesRequest, opts ...grpc.CallOption) (*ListCourseOutlinesResponse, error)
+	CreateCourseOutline(ctx context.Context, in *CreateCourseOutlineRequest, opts ...grpc.CallOption) (*CourseOutline, error)
+	UpdateCourseOutline(ctx context.Context, in *UpdateCourseOutlineRequest, opts ...grpc.CallOption) (*CourseOutline, error)
+	DeleteCourseOutline(ctx context.Context, in *DeleteCourseOutlineRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RestoreCourseOutline(ctx context.Context, in *RestoreCourseOutlineRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type courseOutlineServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCourseOutlineServiceClient(cc grpc.ClientConnInterface) CourseOutlineServiceClient {
+	return &courseOutlineServiceClient{cc}
+}
+
+func (c *courseOutlineServiceClient) GetCourseOutline(ctx context.Context, in *GetCourseOutlineRequest, opts ...grpc.CallOption) (*CourseOutline, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CourseOutline)
+	err := c.cc.Invoke(ctx, CourseOutlineService_GetCourseOutline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *courseOutlineServiceClient) ListCourseOutlines(ctx context.Context, in *ListCourseOutlinesRequest, opts ...grpc.CallOption) (*ListCourseOutlinesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCourseOutlinesResponse)
+	err := c.cc.Invoke(ctx, CourseOutlineService_ListCourseOutlines_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *courseOutlineServiceClient) CreateCourseOutline(ctx context.Context, in *CreateCourseOutlineRequest, opts ...grpc.CallOption) (*CourseOutline, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CourseOutline)
+	err := c.cc.Invoke(ctx, CourseOutlineService_CreateCourseOutline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *courseOutlineServiceClient) UpdateCourseOutline(ctx context.Context, in *UpdateCourseOutlineRequest, opts ...grpc.CallOption) (*CourseOutline, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CourseOutline)
+	err := c.cc.Invoke(ctx, CourseOutlineService_UpdateCourseOutline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *courseOutlineServiceClient) DeleteCourseOutline(ctx context.Context, in *DeleteCourseOutlineRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, CourseOutlineService_DeleteCourseOutline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *courseOutlineServiceClient) RestoreCourseOutline(ctx context.Context, in *RestoreCourseOutlineRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, CourseOutlineService_RestoreCourseOutline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CourseOutlineServiceServer is the server API for CourseOutlineService service.
+// All implementations must embed UnimplementedCourseOutlineServiceServer
+// for forward compatibility.
+type CourseOutlineServiceServer interface {
+	GetCourseOutline(context.Context, *GetCourseOutlineRequest) (*CourseOutline, error)
+	ListCourseOutlines(context.Context, *ListCourseOutlinesRequest) (*ListCourseOutlinesResponse, error)
+	CreateCourseOutline(context.Context, *CreateCourseOutlineRequest) (*CourseOutline, error)
+	UpdateCourseOutline(context.Context, *UpdateCourseOutlineRequest) (*CourseOutline, error)
+	DeleteCourseOutline(context.Context, *DeleteCourseOutlineRequest) (*emptypb.Empty, error)
+	RestoreCourseOutline(context.Context, *RestoreCourseOutlineRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedCourseOutlineServiceServer()
+}
+
+// UnimplementedCourseOutlineServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCourseOutlineServiceServer struct{}
+
+func (UnimplementedCourseOutlineServiceServer) GetCourseOutline(context.Context, *GetCourseOutlineRequest) (*CourseOutline, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCourseOutline not implemented")
+}
+func (UnimplementedCourseOutlineServiceServer) ListCourseOutlines(context.Context, *ListCourseOutlinesRequest) (*ListCourseOutlinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCourseOutlines not implemented")
+}
+func (UnimplementedCourseOutlineServiceServer) CreateCourseOutline(context.Context, *CreateCourseOutlineRequest) (*CourseOutline, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCourseOutline not implemented")
+}
+func (UnimplementedCourseOutlineServiceServer) UpdateCourseOutline(context.Context, *UpdateCourseOutlineRequest) (*CourseOutline, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCourseOutline not implemented")
+}
+func (UnimplementedCourseOutlineServiceServer) DeleteCourseOutline(context.Context, *DeleteCourseOutlineRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCourseOutline not implemented")
+}
+func (UnimplementedCourseOutlineServiceServer) RestoreCourseOutline(context.Context, *RestoreCourseOutlineRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreCourseOutline not implemented")
+}
+func (UnimplementedCourseOutlineServiceServer) mustEmbedUnimplementedCourseOutlineServiceServer() {}
+func (UnimplementedCourseOutlineServiceServer) testEmbeddedByValue()                              {}
+
+// UnsafeCourseOutlineServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CourseOutlineServiceServer will
+// result in compilation errors.
+type UnsafeCourseOutlineServiceServer interface {
+	mustEmbedUnimplementedCourseOutlineServiceServer()
+}
+
+func RegisterCourseOutlineServiceServer(s grpc.ServiceRegistrar, srv CourseOutlineServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCourseOutlineServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CourseOutlineService_ServiceDesc, srv)
+}
+
+func _CourseOutlineService_GetCourseOutline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCourseOutlineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CourseOutlineServiceServer).GetCourseOutline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CourseOutlineService_GetCourseOutline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CourseOutlineServiceServer).GetCourseOutline(ctx, req.(*GetCourseOutlineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CourseOutlineService_ListCourseOutlines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCourseOutlinesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CourseOutlineServiceServer).ListCourseOutlines(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CourseOutlineService_ListCourseOutlines_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CourseOutlineServiceServer).ListCourseOutlines(ctx, req.(*ListCourseOutlinesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CourseOutlineService_CreateCourseOutline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCourseOutlineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CourseOutlineServiceServer).CreateCourseOutline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CourseOutlineService_CreateCourseOutline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CourseOutlineServiceServer).CreateCourseOutline(ctx, req.(*CreateCourseOutlineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CourseOutlineService_UpdateCourseOutline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCourseOutlineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CourseOutlineServiceServer).UpdateCourseOutline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CourseOutlineService_UpdateCourseOutline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CourseOutlineServiceServer).UpdateCourseOutline(ctx, req.(*UpdateCourseOutlineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CourseOutlineService_DeleteCourseOutline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCourseOutlineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CourseOutlineServiceServer).DeleteCourseOutline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CourseOutlineService_DeleteCourseOutline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CourseOutlineServiceServer).DeleteCourseOutline(ctx, req.(*DeleteCourseOutlineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CourseOutlineService_RestoreCourseOutline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreCourseOutlineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CourseOutlineServiceServer).RestoreCourseOutline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CourseOutlineService_RestoreCourseOutline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CourseOutlineServiceServer).RestoreCourseOutline(ctx, req.(*RestoreCourseOutlineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CourseOutlineService_ServiceDesc is the grpc.ServiceDesc for CourseOutlineService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CourseOutlineService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scopingai.v1.CourseOutlineService",
+	HandlerType: (*CourseOutlineServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCourseOutline",
+			Handler:    _CourseOutlineService_GetCourseOutline_Handler,
+		},
+		{
+			MethodName: "ListCourseOutlines",
+			Handler:    _CourseOutlineService_ListCourseOutlines_Handler,
+		},
+		{
+			MethodName: "CreateCourseOutline",
+			Handler:    _CourseOutlineService_CreateCourseOutline_Handler,
+		},
+		{
+			MethodName: "UpdateCourseOutline",
+			Handler:    _CourseOutlineService_UpdateCourseOutline_Handler,
+		},
+		{
+			MethodName: "DeleteCourseOutline",
+			Handler:    _CourseOutlineService_DeleteCourseOutline_Handler,
+		},
+		{
+			MethodName: "RestoreCourseOutline",
+			Handler:    _CourseOutlineService_RestoreCourseOutline_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "scopingai/v1/course_outline.proto",
+}
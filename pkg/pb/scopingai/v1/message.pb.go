@@ -0,0 +1,812 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: scopingai/v1/message.proto
+
+package scopingaiv1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// A learner's answer to a scoping question
+type Answer struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Question       *Question              `protobuf:"bytes,1,opt,name=question,proto3" json:"question,omitempty"`
+	TechnologyName *string                `protobuf:"bytes,2,opt,name=technology_name,json=technologyName,proto3,oneof" json:"technology_name,omitempty"`
+	Answer         *string                `protobuf:"bytes,3,opt,name=answer,proto3,oneof" json:"answer,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Answer) Reset() {
+	*x = Answer{}
+	mi := &file_scopingai_v1_message_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Answer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Answer) ProtoMessage() {}
+
+func (x *Answer) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_message_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Answer.ProtoReflect.Descriptor instead.
+func (*Answer) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_message_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Answer) GetQuestion() *Question {
+	if x != nil {
+		return x.Question
+	}
+	return nil
+}
+
+func (x *Answer) GetTechnologyName() string {
+	if x != nil && x.TechnologyName != nil {
+		return *x.TechnologyName
+	}
+	return ""
+}
+
+func (x *Answer) GetAnswer() string {
+	if x != nil && x.Answer != nil {
+		return *x.Answer
+	}
+	return ""
+}
+
+// Holds either a message text or an answer
+type Message struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId      string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MessageText *string                `protobuf:"bytes,3,opt,name=message_text,json=messageText,proto3,oneof" json:"message_text,omitempty"`
+	Answer      *Answer                `protobuf:"bytes,4,opt,name=answer,proto3" json:"answer,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DeletedAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	DeletedBy   *string                `protobuf:"bytes,8,opt,name=deleted_by,json=deletedBy,proto3,oneof" json:"deleted_by,omitempty"`
+	// Version of the stored record, the REST API returns it as the ETag
+	Version       string `protobuf:"bytes,9,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_scopingai_v1_message_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_message_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_message_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Message) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Message) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Message) GetMessageText() string {
+	if x != nil && x.MessageText != nil {
+		return *x.MessageText
+	}
+	return ""
+}
+
+func (x *Message) GetAnswer() *Answer {
+	if x != nil {
+		return x.Answer
+	}
+	return nil
+}
+
+func (x *Message) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Message) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Message) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *Message) GetDeletedBy() string {
+	if x != nil && x.DeletedBy != nil {
+		return *x.DeletedBy
+	}
+	return ""
+}
+
+func (x *Message) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type GetMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMessageRequest) Reset() {
+	*x = GetMessageRequest{}
+	mi := &file_scopingai_v1_message_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMessageRequest) ProtoMessage() {}
+
+func (x *GetMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_message_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMessageRequest.ProtoReflect.Descriptor instead.
+func (*GetMessageRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_message_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetMessageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetMessageRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListMessagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Page          *PageRequest           `protobuf:"bytes,2,opt,name=page,proto3" json:"page,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMessagesRequest) Reset() {
+	*x = ListMessagesRequest{}
+	mi := &file_scopingai_v1_message_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMessagesRequest) ProtoMessage() {}
+
+func (x *ListMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_message_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMessagesRequest.ProtoReflect.Descriptor instead.
+func (*ListMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_message_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListMessagesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListMessagesRequest) GetPage() *PageRequest {
+	if x != nil {
+		return x.Page
+	}
+	return nil
+}
+
+type ListMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*Message             `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMessagesResponse) Reset() {
+	*x = ListMessagesResponse{}
+	mi := &file_scopingai_v1_message_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMessagesResponse) ProtoMessage() {}
+
+func (x *ListMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_message_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMessagesResponse.ProtoReflect.Descriptor instead.
+func (*ListMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_message_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListMessagesResponse) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type CreateMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Message       *Message               `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMessageRequest) Reset() {
+	*x = CreateMessageRequest{}
+	mi := &file_scopingai_v1_message_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMessageRequest) ProtoMessage() {}
+
+func (x *CreateMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_message_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMessageRequest.ProtoReflect.Descriptor instead.
+func (*CreateMessageRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_message_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateMessageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateMessageRequest) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+// Replaces the message. A version, when set, must match the stored one.
+type UpdateMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Message       *Message               `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMessageRequest) Reset() {
+	*x = UpdateMessageRequest{}
+	mi := &file_scopingai_v1_message_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMessageRequest) ProtoMessage() {}
+
+func (x *UpdateMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_message_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMessageRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMessageRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_message_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateMessageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateMessageRequest) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type DeleteMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMessageRequest) Reset() {
+	*x = DeleteMessageRequest{}
+	mi := &file_scopingai_v1_message_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMessageRequest) ProtoMessage() {}
+
+func (x *DeleteMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_message_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMessageRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMessageRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_message_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteMessageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DeleteMessageRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RestoreMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreMessageRequest) Reset() {
+	*x = RestoreMessageRequest{}
+	mi := &file_scopingai_v1_message_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreMessageRequest) ProtoMessage() {}
+
+func (x *RestoreMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_message_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreMessageRequest.ProtoReflect.Descriptor instead.
+func (*RestoreMessageRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_message_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RestoreMessageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RestoreMessageRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type SubmitAnswersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Answers       []*Answer              `protobuf:"bytes,2,rep,name=answers,proto3" json:"answers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitAnswersRequest) Reset() {
+	*x = SubmitAnswersRequest{}
+	mi := &file_scopingai_v1_message_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitAnswersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitAnswersRequest) ProtoMessage() {}
+
+func (x *SubmitAnswersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_message_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitAnswersRequest.ProtoReflect.Descriptor instead.
+func (*SubmitAnswersRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_message_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SubmitAnswersRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SubmitAnswersRequest) GetAnswers() []*Answer {
+	if x != nil {
+		return x.Answers
+	}
+	return nil
+}
+
+// Sent while answers are turned into a recommendation. The first response
+// holds the pending message, the last one the completed recommendation.
+type SubmitAnswersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       *Message               `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Completed     bool                   `protobuf:"varint,2,opt,name=completed,proto3" json:"completed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitAnswersResponse) Reset() {
+	*x = SubmitAnswersResponse{}
+	mi := &file_scopingai_v1_message_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitAnswersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitAnswersResponse) ProtoMessage() {}
+
+func (x *SubmitAnswersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_message_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitAnswersResponse.ProtoReflect.Descriptor instead.
+func (*SubmitAnswersResponse) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_message_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SubmitAnswersResponse) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *SubmitAnswersResponse) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+var File_scopingai_v1_message_proto protoreflect.FileDescriptor
+
+const file_scopingai_v1_message_proto_rawDesc = "" +
+	"\n" +
+	"\x1ascopingai/v1/message.proto\x12\fscopingai.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x19scopingai/v1/common.proto\"\xa6\x01\n" +
+	"\x06Answer\x122\n" +
+	"\bquestion\x18\x01 \x01(\v2\x16.scopingai.v1.QuestionR\bquestion\x12,\n" +
+	"\x0ftechnology_name\x18\x02 \x01(\tH\x00R\x0etechnologyName\x88\x01\x01\x12\x1b\n" +
+	"\x06answer\x18\x03 \x01(\tH\x01R\x06answer\x88\x01\x01B\x12\n" +
+	"\x10_technology_nameB\t\n" +
+	"\a_answer\"\x97\x03\n" +
+	"\aMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12&\n" +
+	"\fmessage_text\x18\x03 \x01(\tH\x00R\vmessageText\x88\x01\x01\x12,\n" +
+	"\x06answer\x18\x04 \x01(\v2\x14.scopingai.v1.AnswerR\x06answer\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x129\n" +
+	"\n" +
+	"deleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12\"\n" +
+	"\n" +
+	"deleted_by\x18\b \x01(\tH\x01R\tdeletedBy\x88\x01\x01\x12\x18\n" +
+	"\aversion\x18\t \x01(\tR\aversionB\x0f\n" +
+	"\r_message_textB\r\n" +
+	"\v_deleted_by\"<\n" +
+	"\x11GetMessageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"]\n" +
+	"\x13ListMessagesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12-\n" +
+	"\x04page\x18\x02 \x01(\v2\x19.scopingai.v1.PageRequestR\x04page\"I\n" +
+	"\x14ListMessagesResponse\x121\n" +
+	"\bmessages\x18\x01 \x03(\v2\x15.scopingai.v1.MessageR\bmessages\"`\n" +
+	"\x14CreateMessageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12/\n" +
+	"\amessage\x18\x02 \x01(\v2\x15.scopingai.v1.MessageR\amessage\"`\n" +
+	"\x14UpdateMessageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12/\n" +
+	"\amessage\x18\x02 \x01(\v2\x15.scopingai.v1.MessageR\amessage\"?\n" +
+	"\x14DeleteMessageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"@\n" +
+	"\x15RestoreMessageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"_\n" +
+	"\x14SubmitAnswersRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12.\n" +
+	"\aanswers\x18\x02 \x03(\v2\x14.scopingai.v1.AnswerR\aanswers\"f\n" +
+	"\x15SubmitAnswersResponse\x12/\n" +
+	"\amessage\x18\x01 \x01(\v2\x15.scopingai.v1.MessageR\amessage\x12\x1c\n" +
+	"\tcompleted\x18\x02 \x01(\bR\tcompleted2\xad\a\n" +
+	"\x0eMessageService\x12s\n" +
+	"\n" +
+	"GetMessage\x12\x1f.scopingai.v1.GetMessageRequest\x1a\x15.scopingai.v1.Message\"-\x82\xd3\xe4\x93\x02'\x12%/api/v1/users/{user_id}/messages/{id}\x12\x7f\n" +
+	"\fListMessages\x12!.scopingai.v1.ListMessagesRequest\x1a\".scopingai.v1.ListMessagesResponse\"(\x82\xd3\xe4\x93\x02\"\x12 /api/v1/users/{user_id}/messages\x12}\n" +
+	"\rCreateMessage\x12\".scopingai.v1.CreateMessageRequest\x1a\x15.scopingai.v1.Message\"1\x82\xd3\xe4\x93\x02+:\amessage\" /api/v1/users/{user_id}/messages\x12\x8a\x01\n" +
+	"\rUpdateMessage\x12\".scopingai.v1.UpdateMessageRequest\x1a\x15.scopingai.v1.Message\">\x82\xd3\xe4\x93\x028:\amessage\x1a-/api/v1/users/{user_id}/messages/{message.id}\x12z\n" +
+	"\rDeleteMessage\x12\".scopingai.v1.DeleteMessageRequest\x1a\x16.google.protobuf.Empty\"-\x82\xd3\xe4\x93\x02'*%/api/v1/users/{user_id}/messages/{id}\x12\x84\x01\n" +
+	"\x0eRestoreMessage\x12#.scopingai.v1.RestoreMessageRequest\x1a\x16.google.protobuf.Empty\"5\x82\xd3\xe4\x93\x02/\"-/api/v1/users/{user_id}/messages/{id}/restore\x12\x95\x01\n" +
+	"\rSubmitAnswers\x12\".scopingai.v1.SubmitAnswersRequest\x1a#.scopingai.v1.SubmitAnswersResponse\"9\x82\xd3\xe4\x93\x023:\aanswers\"(/api/v1/users/{user_id}/messages/answers0\x01B?Z=github.com/zzenonn/scoping-ai/pkg/pb/scopingai/v1;scopingaiv1b\x06proto3"
+
+var (
+	file_scopingai_v1_message_proto_rawDescOnce sync.Once
+	file_scopingai_v1_message_proto_rawDescData []byte
+)
+
+func file_scopingai_v1_message_proto_rawDescGZIP() []byte {
+	file_scopingai_v1_message_proto_rawDescOnce.Do(func() {
+		file_scopingai_v1_message_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_scopingai_v1_message_proto_rawDesc), len(file_scopingai_v1_message_proto_rawDesc)))
+	})
+	return file_scopingai_v1_message_proto_rawDescData
+}
+
+var file_scopingai_v1_message_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_scopingai_v1_message_proto_goTypes = []any{
+	(*Answer)(nil),                // 0: scopingai.v1.Answer
+	(*Message)(nil),               // 1: scopingai.v1.Message
+	(*GetMessageRequest)(nil),     // 2: scopingai.v1.GetMessageRequest
+	(*ListMessagesRequest)(nil),   // 3: scopingai.v1.ListMessagesRequest
+	(*ListMessagesResponse)(nil),  // 4: scopingai.v1.ListMessagesResponse
+	(*CreateMessageRequest)(nil),  // 5: scopingai.v1.CreateMessageRequest
+	(*UpdateMessageRequest)(nil),  // 6: scopingai.v1.UpdateMessageRequest
+	(*DeleteMessageRequest)(nil),  // 7: scopingai.v1.DeleteMessageRequest
+	(*RestoreMessageRequest)(nil), // 8: scopingai.v1.RestoreMessageRequest
+	(*SubmitAnswersRequest)(nil),  // 9: scopingai.v1.SubmitAnswersRequest
+	(*SubmitAnswersResponse)(nil), // 10: scopingai.v1.SubmitAnswersResponse
+	(*Question)(nil),              // 11: scopingai.v1.Question
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+	(*PageRequest)(nil),           // 13: scopingai.v1.PageRequest
+	(*emptypb.Empty)(nil),         // 14: google.protobuf.Empty
+}
+var file_scopingai_v1_message_proto_depIdxs = []int32{
+	11, // 0: scopingai.v1.Answer.question:type_name -> scopingai.v1.Question
+	0,  // 1: scopingai.v1.Message.answer:type_name -> scopingai.v1.Answer
+	12, // 2: scopingai.v1.Message.created_at:type_name -> google.protobuf.Timestamp
+	12, // 3: scopingai.v1.Message.updated_at:type_name -> google.protobuf.Timestamp
+	12, // 4: scopingai.v1.Message.deleted_at:type_name -> google.protobuf.Timestamp
+	13, // 5: scopingai.v1.ListMessagesRequest.page:type_name -> scopingai.v1.PageRequest
+	1,  // 6: scopingai.v1.ListMessagesResponse.messages:type_name -> scopingai.v1.Message
+	1,  // 7: scopingai.v1.CreateMessageRequest.message:type_name -> scopingai.v1.Message
+	1,  // 8: scopingai.v1.UpdateMessageRequest.message:type_name -> scopingai.v1.Message
+	0,  // 9: scopingai.v1.SubmitAnswersRequest.answers:type_name -> scopingai.v1.Answer
+	1,  // 10: scopingai.v1.SubmitAnswersResponse.message:type_name -> scopingai.v1.Message
+	2,  // 11: scopingai.v1.MessageService.GetMessage:input_type -> scopingai.v1.GetMessageRequest
+	3,  // 12: scopingai.v1.MessageService.ListMessages:input_type -> scopingai.v1.ListMessagesRequest
+	5,  // 13: scopingai.v1.MessageService.CreateMessage:input_type -> scopingai.v1.CreateMessageRequest
+	6,  // 14: scopingai.v1.MessageService.UpdateMessage:input_type -> scopingai.v1.UpdateMessageRequest
+	7,  // 15: scopingai.v1.MessageService.DeleteMessage:input_type -> scopingai.v1.DeleteMessageRequest
+	8,  // 16: scopingai.v1.MessageService.RestoreMessage:input_type -> scopingai.v1.RestoreMessageRequest
+	9,  // 17: scopingai.v1.MessageService.SubmitAnswers:input_type -> scopingai.v1.SubmitAnswersRequest
+	1,  // 18: scopingai.v1.MessageService.GetMessage:output_type -> scopingai.v1.Message
+	4,  // 19: scopingai.v1.MessageService.ListMessages:output_type -> scopingai.v1.ListMessagesResponse
+	1,  // 20: scopingai.v1.MessageService.CreateMessage:output_type -> scopingai.v1.Message
+	1,  // 21: scopingai.v1.MessageService.UpdateMessage:output_type -> scopingai.v1.Message
+	14, // 22: scopingai.v1.MessageService.DeleteMessage:output_type -> google.protobuf.Empty
+	14, // 23: scopingai.v1.MessageService.RestoreMessage:output_type -> google.protobuf.Empty
+	10, // 24: scopingai.v1.MessageService.SubmitAnswers:output_type -> scopingai.v1.SubmitAnswersResponse
+	18, // [18:25] is the sub-list for method output_type
+	11, // [11:18] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_scopingai_v1_message_proto_init() }
+func file_scopingai_v1_message_proto_init() {
+	if File_scopingai_v1_message_proto != nil {
+		return
+	}
+	file_scopingai_v1_common_proto_init()
+	file_scopingai_v1_message_proto_msgTypes[0].OneofWrappers = []any{}
+	file_scopingai_v1_message_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_scopingai_v1_message_proto_rawDesc), len(file_scopingai_v1_message_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_scopingai_v1_message_proto_goTypes,
+		DependencyIndexes: file_scopingai_v1_message_proto_depIdxs,
+		MessageInfos:      file_scopingai_v1_message_proto_msgTypes,
+	}.Build()
+	File_scopingai_v1_message_proto = out.File
+	file_scopingai_v1_message_proto_goTypes = nil
+	file_scopingai_v1_message_proto_depIdxs = nil
+}
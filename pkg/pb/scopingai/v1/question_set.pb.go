@@ -0,0 +1,586 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: scopingai/v1/question_set.proto
+
+package scopingaiv1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// The scoping questions asked for a technology
+type QuestionSet struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TechnologyName string                 `protobuf:"bytes,2,opt,name=technology_name,json=technologyName,proto3" json:"technology_name,omitempty"`
+	Questions      []*Question            `protobuf:"bytes,3,rep,name=questions,proto3" json:"questions,omitempty"`
+	DeletedAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	DeletedBy      *string                `protobuf:"bytes,5,opt,name=deleted_by,json=deletedBy,proto3,oneof" json:"deleted_by,omitempty"`
+	// Version of the stored record, the REST API returns it as the ETag
+	Version       string `protobuf:"bytes,6,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuestionSet) Reset() {
+	*x = QuestionSet{}
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuestionSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuestionSet) ProtoMessage() {}
+
+func (x *QuestionSet) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuestionSet.ProtoReflect.Descriptor instead.
+func (*QuestionSet) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_question_set_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *QuestionSet) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *QuestionSet) GetTechnologyName() string {
+	if x != nil {
+		return x.TechnologyName
+	}
+	return ""
+}
+
+func (x *QuestionSet) GetQuestions() []*Question {
+	if x != nil {
+		return x.Questions
+	}
+	return nil
+}
+
+func (x *QuestionSet) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *QuestionSet) GetDeletedBy() string {
+	if x != nil && x.DeletedBy != nil {
+		return *x.DeletedBy
+	}
+	return ""
+}
+
+func (x *QuestionSet) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type GetQuestionSetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuestionSetRequest) Reset() {
+	*x = GetQuestionSetRequest{}
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuestionSetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuestionSetRequest) ProtoMessage() {}
+
+func (x *GetQuestionSetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuestionSetRequest.ProtoReflect.Descriptor instead.
+func (*GetQuestionSetRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_question_set_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetQuestionSetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetQuestionSetByTechnologyNameRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TechnologyName string                 `protobuf:"bytes,1,opt,name=technology_name,json=technologyName,proto3" json:"technology_name,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetQuestionSetByTechnologyNameRequest) Reset() {
+	*x = GetQuestionSetByTechnologyNameRequest{}
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuestionSetByTechnologyNameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuestionSetByTechnologyNameRequest) ProtoMessage() {}
+
+func (x *GetQuestionSetByTechnologyNameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuestionSetByTechnologyNameRequest.ProtoReflect.Descriptor instead.
+func (*GetQuestionSetByTechnologyNameRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_question_set_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetQuestionSetByTechnologyNameRequest) GetTechnologyName() string {
+	if x != nil {
+		return x.TechnologyName
+	}
+	return ""
+}
+
+type ListQuestionSetsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          *PageRequest           `protobuf:"bytes,1,opt,name=page,proto3" json:"page,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQuestionSetsRequest) Reset() {
+	*x = ListQuestionSetsRequest{}
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQuestionSetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQuestionSetsRequest) ProtoMessage() {}
+
+func (x *ListQuestionSetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQuestionSetsRequest.ProtoReflect.Descriptor instead.
+func (*ListQuestionSetsRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_question_set_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListQuestionSetsRequest) GetPage() *PageRequest {
+	if x != nil {
+		return x.Page
+	}
+	return nil
+}
+
+type ListQuestionSetsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QuestionSets  []*QuestionSet         `protobuf:"bytes,1,rep,name=question_sets,json=questionSets,proto3" json:"question_sets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQuestionSetsResponse) Reset() {
+	*x = ListQuestionSetsResponse{}
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQuestionSetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQuestionSetsResponse) ProtoMessage() {}
+
+func (x *ListQuestionSetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQuestionSetsResponse.ProtoReflect.Descriptor instead.
+func (*ListQuestionSetsResponse) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_question_set_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListQuestionSetsResponse) GetQuestionSets() []*QuestionSet {
+	if x != nil {
+		return x.QuestionSets
+	}
+	return nil
+}
+
+type CreateQuestionSetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QuestionSet   *QuestionSet           `protobuf:"bytes,1,opt,name=question_set,json=questionSet,proto3" json:"question_set,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateQuestionSetRequest) Reset() {
+	*x = CreateQuestionSetRequest{}
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateQuestionSetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateQuestionSetRequest) ProtoMessage() {}
+
+func (x *CreateQuestionSetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateQuestionSetRequest.ProtoReflect.Descriptor instead.
+func (*CreateQuestionSetRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_question_set_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateQuestionSetRequest) GetQuestionSet() *QuestionSet {
+	if x != nil {
+		return x.QuestionSet
+	}
+	return nil
+}
+
+// Replaces the question set. A version, when set, must match the stored one.
+type UpdateQuestionSetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QuestionSet   *QuestionSet           `protobuf:"bytes,1,opt,name=question_set,json=questionSet,proto3" json:"question_set,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateQuestionSetRequest) Reset() {
+	*x = UpdateQuestionSetRequest{}
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateQuestionSetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateQuestionSetRequest) ProtoMessage() {}
+
+func (x *UpdateQuestionSetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateQuestionSetRequest.ProtoReflect.Descriptor instead.
+func (*UpdateQuestionSetRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_question_set_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateQuestionSetRequest) GetQuestionSet() *QuestionSet {
+	if x != nil {
+		return x.QuestionSet
+	}
+	return nil
+}
+
+type DeleteQuestionSetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteQuestionSetRequest) Reset() {
+	*x = DeleteQuestionSetRequest{}
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteQuestionSetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteQuestionSetRequest) ProtoMessage() {}
+
+func (x *DeleteQuestionSetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteQuestionSetRequest.ProtoReflect.Descriptor instead.
+func (*DeleteQuestionSetRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_question_set_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteQuestionSetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RestoreQuestionSetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreQuestionSetRequest) Reset() {
+	*x = RestoreQuestionSetRequest{}
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreQuestionSetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreQuestionSetRequest) ProtoMessage() {}
+
+func (x *RestoreQuestionSetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scopingai_v1_question_set_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreQuestionSetRequest.ProtoReflect.Descriptor instead.
+func (*RestoreQuestionSetRequest) Descriptor() ([]byte, []int) {
+	return file_scopingai_v1_question_set_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RestoreQuestionSetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+var File_scopingai_v1_question_set_proto protoreflect.FileDescriptor
+
+const file_scopingai_v1_question_set_proto_rawDesc = "" +
+	"\n" +
+	"\x1fscopingai/v1/question_set.proto\x12\fscopingai.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x19scopingai/v1/common.proto\"\x84\x02\n" +
+	"\vQuestionSet\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x0ftechnology_name\x18\x02 \x01(\tR\x0etechnologyName\x124\n" +
+	"\tquestions\x18\x03 \x03(\v2\x16.scopingai.v1.QuestionR\tquestions\x129\n" +
+	"\n" +
+	"deleted_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12\"\n" +
+	"\n" +
+	"deleted_by\x18\x05 \x01(\tH\x00R\tdeletedBy\x88\x01\x01\x12\x18\n" +
+	"\aversion\x18\x06 \x01(\tR\aversionB\r\n" +
+	"\v_deleted_by\"'\n" +
+	"\x15GetQuestionSetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"P\n" +
+	"%GetQuestionSetByTechnologyNameRequest\x12'\n" +
+	"\x0ftechnology_name\x18\x01 \x01(\tR\x0etechnologyName\"H\n" +
+	"\x17ListQuestionSetsRequest\x12-\n" +
+	"\x04page\x18\x01 \x01(\v2\x19.scopingai.v1.PageRequestR\x04page\"Z\n" +
+	"\x18ListQuestionSetsResponse\x12>\n" +
+	"\rquestion_sets\x18\x01 \x03(\v2\x19.scopingai.v1.QuestionSetR\fquestionSets\"X\n" +
+	"\x18CreateQuestionSetRequest\x12<\n" +
+	"\fquestion_set\x18\x01 \x01(\v2\x19.scopingai.v1.QuestionSetR\vquestionSet\"X\n" +
+	"\x18UpdateQuestionSetRequest\x12<\n" +
+	"\fquestion_set\x18\x01 \x01(\v2\x19.scopingai.v1.QuestionSetR\vquestionSet\"*\n" +
+	"\x18DeleteQuestionSetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"+\n" +
+	"\x19RestoreQuestionSetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id2\xda\a\n" +
+	"\x12QuestionSetService\x12t\n" +
+	"\x0eGetQuestionSet\x12#.scopingai.v1.GetQuestionSetRequest\x1a\x19.scopingai.v1.QuestionSet\"\"\x82\xd3\xe4\x93\x02\x1c\x12\x1a/api/v1/question-sets/{id}\x12\xaf\x01\n" +
+	"\x1eGetQuestionSetByTechnologyName\x123.scopingai.v1.GetQuestionSetByTechnologyNameRequest\x1a\x19.scopingai.v1.QuestionSet\"=\x82\xd3\xe4\x93\x027\x125/api/v1/question-sets/by-technology/{technology_name}\x12\x80\x01\n" +
+	"\x10ListQuestionSets\x12%.scopingai.v1.ListQuestionSetsRequest\x1a&.scopingai.v1.ListQuestionSetsResponse\"\x1d\x82\xd3\xe4\x93\x02\x17\x12\x15/api/v1/question-sets\x12\x83\x01\n" +
+	"\x11CreateQuestionSet\x12&.scopingai.v1.CreateQuestionSetRequest\x1a\x19.scopingai.v1.QuestionSet\"+\x82\xd3\xe4\x93\x02%:\fquestion_set\"\x15/api/v1/question-sets\x12\x95\x01\n" +
+	"\x11UpdateQuestionSet\x12&.scopingai.v1.UpdateQuestionSetRequest\x1a\x19.scopingai.v1.QuestionSet\"=\x82\xd3\xe4\x93\x027:\fquestion_set\x1a'/api/v1/question-sets/{question_set.id}\x12w\n" +
+	"\x11DeleteQuestionSet\x12&.scopingai.v1.DeleteQuestionSetRequest\x1a\x16.google.protobuf.Empty\"\"\x82\xd3\xe4\x93\x02\x1c*\x1a/api/v1/question-sets/{id}\x12\x81\x01\n" +
+	"\x12RestoreQuestionSet\x12'.scopingai.v1.RestoreQuestionSetRequest\x1a\x16.google.protobuf.Empty\"*\x82\xd3\xe4\x93\x02$\"\"/api/v1/question-sets/{id}/restoreB?Z=github.com/zzenonn/scoping-ai/pkg/pb/scopingai/v1;scopingaiv1b\x06proto3"
+
+var (
+	file_scopingai_v1_question_set_proto_rawDescOnce sync.Once
+	file_scopingai_v1_question_set_proto_rawDescData []byte
+)
+
+func file_scopingai_v1_question_set_proto_rawDescGZIP() []byte {
+	file_scopingai_v1_question_set_proto_rawDescOnce.Do(func() {
+		file_scopingai_v1_question_set_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_scopingai_v1_question_set_proto_rawDesc), len(file_scopingai_v1_question_set_proto_rawDesc)))
+	})
+	return file_scopingai_v1_question_set_proto_rawDescData
+}
+
+var file_scopingai_v1_question_set_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_scopingai_v1_question_set_proto_goTypes = []any{
+	(*QuestionSet)(nil),                           // 0: scopingai.v1.QuestionSet
+	(*GetQuestionSetRequest)(nil),                 // 1: scopingai.v1.GetQuestionSetRequest
+	(*GetQuestionSetByTechnologyNameRequest)(nil), // 2: scopingai.v1.GetQuestionSetByTechnologyNameRequest
+	(*ListQuestionSetsRequest)(nil),               // 3: scopingai.v1.ListQuestionSetsRequest
+	(*ListQuestionSetsResponse)(nil),              // 4: scopingai.v1.ListQuestionSetsResponse
+	(*CreateQuestionSetRequest)(nil),              // 5: scopingai.v1.CreateQuestionSetRequest
+	(*UpdateQuestionSetRequest)(nil),              // 6: scopingai.v1.UpdateQuestionSetRequest
+	(*DeleteQuestionSetRequest)(nil),              // 7: scopingai.v1.DeleteQuestionSetRequest
+	(*RestoreQuestionSetRequest)(nil),             // 8: scopingai.v1.RestoreQuestionSetRequest
+	(*Question)(nil),                              // 9: scopingai.v1.Question
+	(*timestamppb.Timestamp)(nil),                 // 10: google.protobuf.Timestamp
+	(*PageRequest)(nil),                           // 11: scopingai.v1.PageRequest
+	(*emptypb.Empty)(nil),                         // 12: google.protobuf.Empty
+}
+var file_scopingai_v1_question_set_proto_depIdxs = []int32{
+	9,  // 0: scopingai.v1.QuestionSet.questions:type_name -> scopingai.v1.Question
+	10, // 1: scopingai.v1.QuestionSet.deleted_at:type_name -> google.protobuf.Timestamp
+	11, // 2: scopingai.v1.ListQuestionSetsRequest.page:type_name -> scopingai.v1.PageRequest
+	0,  // 3: scopingai.v1.ListQuestionSetsResponse.question_sets:type_name -> scopingai.v1.QuestionSet
+	0,  // 4: scopingai.v1.CreateQuestionSetRequest.question_set:type_name -> scopingai.v1.QuestionSet
+	0,  // 5: scopingai.v1.UpdateQuestionSetRequest.question_set:type_name -> scopingai.v1.QuestionSet
+	1,  // 6: scopingai.v1.QuestionSetService.GetQuestionSet:input_type -> scopingai.v1.GetQuestionSetRequest
+	2,  // 7: scopingai.v1.QuestionSetService.GetQuestionSetByTechnologyName:input_type -> scopingai.v1.GetQuestionSetByTechnologyNameRequest
+	3,  // 8: scopingai.v1.QuestionSetService.ListQuestionSets:input_type -> scopingai.v1.ListQuestionSetsRequest
+	5,  // 9: scopingai.v1.QuestionSetService.CreateQuestionSet:input_type -> scopingai.v1.CreateQuestionSetRequest
+	6,  // 10: scopingai.v1.QuestionSetService.UpdateQuestionSet:input_type -> scopingai.v1.UpdateQuestionSetRequest
+	7,  // 11: scopingai.v1.QuestionSetService.DeleteQuestionSet:input_type -> scopingai.v1.DeleteQuestionSetRequest
+	8,  // 12: scopingai.v1.QuestionSetService.RestoreQuestionSet:input_type -> scopingai.v1.RestoreQuestionSetRequest
+	0,  // 13: scopingai.v1.QuestionSetService.GetQuestionSet:output_type -> scopingai.v1.QuestionSet
+	0,  // 14: scopingai.v1.QuestionSetService.GetQuestionSetByTechnologyName:output_type -> scopingai.v1.QuestionSet
+	4,  // 15: scopingai.v1.QuestionSetService.ListQuestionSets:output_type -> scopingai.v1.ListQuestionSetsResponse
+	0,  // 16: scopingai.v1.QuestionSetService.CreateQuestionSet:output_type -> scopingai.v1.QuestionSet
+	0,  // 17: scopingai.v1.QuestionSetService.UpdateQuestionSet:output_type -> scopingai.v1.QuestionSet
+	12, // 18: scopingai.v1.QuestionSetService.DeleteQuestionSet:output_type -> google.protobuf.Empty
+	12, // 19: scopingai.v1.QuestionSetService.RestoreQuestionSet:output_type -> google.protobuf.Empty
+	13, // [13:20] is the sub-list for method output_type
+	6,  // [6:13] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_scopingai_v1_question_set_proto_init() }
+func file_scopingai_v1_question_set_proto_init() {
+	if File_scopingai_v1_question_set_proto != nil {
+		return
+	}
+	file_scopingai_v1_common_proto_init()
+	file_scopingai_v1_question_set_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_scopingai_v1_question_set_proto_rawDesc), len(file_scopingai_v1_question_set_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_scopingai_v1_question_set_proto_goTypes,
+		DependencyIndexes: file_scopingai_v1_question_set_proto_depIdxs,
+		MessageInfos:      file_scopingai_v1_question_set_proto_msgTypes,
+	}.Build()
+	File_scopingai_v1_question_set_proto = out.File
+	file_scopingai_v1_question_set_proto_goTypes = nil
+	file_scopingai_v1_question_set_proto_depIdxs = nil
+}
@@ -0,0 +1,350 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: scopingai/v1/question_set.proto
+
+package scopingaiv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	QuestionSetService_GetQuestionSet_FullMethodName                 = "/scopingai.v1.QuestionSetService/GetQuestionSet"
+	QuestionSetService_GetQuestionSetByTechnologyName_FullMethodName = "/scopingai.v1.QuestionSetService/GetQuestionSetByTechnologyName"
+	QuestionSetService_ListQuestionSets_FullMethodName               = "/scopingai.v1.QuestionSetService/ListQuestionSets"
+	QuestionSetService_CreateQuestionSet_FullMethodName              = "/scopingai.v1.QuestionSetService/CreateQuestionSet"
+	QuestionSetService_UpdateQuestionSet_FullMethodName              = "/scopingai.v1.QuestionSetService/UpdateQuestionSet"
+	QuestionSetService_DeleteQuestionSet_FullMethodName              = "/scopingai.v1.QuestionSetService/DeleteQuestionSet"
+	QuestionSetService_RestoreQuestionSet_FullMethodName             = "/scopingai.v1.QuestionSetService/RestoreQuestionSet"
+)
+
+// QuestionSetServiceClient is the client API for QuestionSetService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type QuestionSetServiceClient interface {
+	GetQuestionSet(ctx context.Context, in *GetQuestionSetRequest, opts ...grpc.CallOption) (*QuestionSet, error)
+	GetQuestionSetByTechnologyName(ctx context.Context, in *GetQuestionSetByTechnologyNameRequest, opts ...grpc.CallOption) (*QuestionSet, error)
+	ListQuestionSets(ctx context.Context, in *ListQuestionSetsRequest, opts ...grpc.CallOption) (*ListQuestionSetsResponse, error)
+	CreateQuestionSet(ctx context.Context, in *CreateQuestionSetRequest, opts ...grpc.CallOption) (*QuestionSet, error)
+	UpdateQuestionSet(ctx context.Context, in *UpdateQuestionSetRequest, opts ...grpc.CallOption) (*QuestionSet, error)
+	DeleteQuestionSet(ctx context.Context, in *DeleteQuestionSetRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RestoreQuestionSet(ctx context.Context, in *RestoreQuestionSetRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type questionSetServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQuestionSetServiceClient(cc grpc.ClientConnInterface) QuestionSetServiceClient {
+	return &questionSetServiceClient{cc}
+}
+
+func (c *questionSetServiceClient) GetQuestionSet(ctx context.Context, in *GetQuestionSetRequest, opts ...grpc.CallOption) (*QuestionSet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuestionSet)
+	err := c.cc.Invoke(ctx, QuestionSetService_GetQuestionSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionSetServiceClient) GetQuestionSetByTechnologyName(ctx context.Context, in *GetQuestionSetByTechnologyNameRequest, opts ...grpc.CallOption) (*QuestionSet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuestionSet)
+	err := c.cc.Invoke(ctx, QuestionSetService_GetQuestionSetByTechnologyName_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionSetServiceClient) ListQuestionSets(ctx context.Context, in *ListQuestionSetsRequest, opts ...grpc.CallOption) (*ListQuestionSetsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListQuestionSetsResponse)
+	err := c.cc.Invoke(ctx, QuestionSetService_ListQuestionSets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionSetServiceClient) CreateQuestionSet(ctx context.Context, in *CreateQuestionSetRequest, opts ...grpc.CallOption) (*QuestionSet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuestionSet)
+	err := c.cc.Invoke(ctx, QuestionSetService_CreateQuestionSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionSetServiceClient) UpdateQuestionSet(ctx context.Context, in *UpdateQuestionSetRequest, opts ...grpc.CallOption) (*QuestionSet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuestionSet)
+	err := c.cc.Invoke(ctx, QuestionSetService_UpdateQuestionSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionSetServiceClient) DeleteQuestionSet(ctx context.Context, in *DeleteQuestionSetRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, QuestionSetService_DeleteQuestionSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionSetServiceClient) RestoreQuestionSet(ctx context.Context, in *RestoreQuestionSetRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, QuestionSetService_RestoreQuestionSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QuestionSetServiceServer is the server API for QuestionSetService service.
+// All implementations must embed UnimplementedQuestionSetServiceServer
+// for forward compatibility.
+type QuestionSetServiceServer interface {
+	GetQuestionSet(context.Context, *GetQuestionSetRequest) (*QuestionSet, error)
+	GetQuestionSetByTechnologyName(context.Context, *GetQuestionSetByTechnologyNameRequest) (*QuestionSet, error)
+	ListQuestionSets(context.Context, *ListQuestionSetsRequest) (*ListQuestionSetsResponse, error)
+	CreateQuestionSet(context.Context, *CreateQuestionSetRequest) (*QuestionSet, error)
+	UpdateQuestionSet(context.Context, *UpdateQuestionSetRequest) (*QuestionSet, error)
+	DeleteQuestionSet(context.Context, *DeleteQuestionSetRequest) (*emptypb.Empty, error)
+	RestoreQuestionSet(context.Context, *RestoreQuestionSetRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedQuestionSetServiceServer()
+}
+
+// UnimplementedQuestionSetServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedQuestionSetServiceServer struct{}
+
+func (UnimplementedQuestionSetServiceServer) GetQuestionSet(context.Context, *GetQuestionSetRequest) (*QuestionSet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuestionSet not implemented")
+}
+func (UnimplementedQuestionSetServiceServer) GetQuestionSetByTechnologyName(context.Context, *GetQuestionSetByTechnologyNameRequest) (*QuestionSet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuestionSetByTechnologyName not implemented")
+}
+func (UnimplementedQuestionSetServiceServer) ListQuestionSets(context.Context, *ListQuestionSetsRequest) (*ListQuestionSetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListQuestionSets not implemented")
+}
+func (UnimplementedQuestionSetServiceServer) CreateQuestionSet(context.Context, *CreateQuestionSetRequest) (*QuestionSet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateQuestionSet not implemented")
+}
+func (UnimplementedQuestionSetServiceServer) UpdateQuestionSet(context.Context, *UpdateQuestionSetRequest) (*QuestionSet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateQuestionSet not implemented")
+}
+func (UnimplementedQuestionSetServiceServer) DeleteQuestionSet(context.Context, *DeleteQuestionSetRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteQuestionSet not implemented")
+}
+func (UnimplementedQuestionSetServiceServer) RestoreQuestionSet(context.Context, *RestoreQuestionSetRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreQuestionSet not implemented")
+}
+func (UnimplementedQuestionSetServiceServer) mustEmbedUnimplementedQuestionSetServiceServer() {}
+func (UnimplementedQuestionSetServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeQuestionSetServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QuestionSetServiceServer will
+// result in compilation errors.
+type UnsafeQuestionSetServiceServer interface {
+	mustEmbedUnimplementedQuestionSetServiceServer()
+}
+
+func RegisterQuestionSetServiceServer(s grpc.ServiceRegistrar, srv QuestionSetServiceServer) {
+	// If the following call pancis, it indicates UnimplementedQuestionSetServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&QuestionSetService_ServiceDesc, srv)
+}
+
+func _QuestionSetService_GetQuestionSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuestionSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionSetServiceServer).GetQuestionSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuestionSetService_GetQuestionSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionSetServiceServer).GetQuestionSet(ctx, req.(*GetQuestionSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuestionSetService_GetQuestionSetByTechnologyName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuestionSetByTechnologyNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionSetServiceServer).GetQuestionSetByTechnologyName(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuestionSetService_GetQuestionSetByTechnologyName_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionSetServiceServer).GetQuestionSetByTechnologyName(ctx, req.(*GetQuestionSetByTechnologyNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuestionSetService_ListQuestionSets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListQuestionSetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionSetServiceServer).ListQuestionSets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuestionSetService_ListQuestionSets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionSetServiceServer).ListQuestionSets(ctx, req.(*ListQuestionSetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuestionSetService_CreateQuestionSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateQuestionSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionSetServiceServer).CreateQuestionSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuestionSetService_CreateQuestionSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionSetServiceServer).CreateQuestionSet(ctx, req.(*CreateQuestionSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuestionSetService_UpdateQuestionSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateQuestionSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionSetServiceServer).UpdateQuestionSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuestionSetService_UpdateQuestionSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionSetServiceServer).UpdateQuestionSet(ctx, req.(*UpdateQuestionSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuestionSetService_DeleteQuestionSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteQuestionSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionSetServiceServer).DeleteQuestionSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuestionSetService_DeleteQuestionSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionSetServiceServer).DeleteQuestionSet(ctx, req.(*DeleteQuestionSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuestionSetService_RestoreQuestionSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreQuestionSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionSetServiceServer).RestoreQuestionSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuestionSetService_RestoreQuestionSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionSetServiceServer).RestoreQuestionSet(ctx, req.(*RestoreQuestionSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// QuestionSetService_ServiceDesc is the grpc.ServiceDesc for QuestionSetService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var QuestionSetService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scopingai.v1.QuestionSetService",
+	HandlerType: (*QuestionSetServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetQuestionSet",
+			Handler:    _QuestionSetService_GetQuestionSet_Handler,
+		},
+		{
+			MethodName: "GetQuestionSetByTechnologyName",
+			Handler:    _QuestionSetService_GetQuestionSetByTechnologyName_Handler,
+		},
+		{
+			MethodName: "ListQuestionSets",
+			Handler:    _QuestionSetService_ListQuestionSets_Handler,
+		},
+		{
+			MethodName: "CreateQuestionSet",
+			Handler:    _QuestionSetService_CreateQuestionSet_Handler,
+		},
+		{
+			MethodName: "UpdateQuestionSet",
+			Handler:    _QuestionSetService_UpdateQuestionSet_Handler,
+		},
+		{
+			MethodName: "DeleteQuestionSet",
+			Handler:    _QuestionSetService_DeleteQuestionSet_Handler,
+		},
+		{
+			MethodName: "RestoreQuestionSet",
+			Handler:    _QuestionSetService_RestoreQuestionSet_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "scopingai/v1/question_set.proto",
+}